@@ -1,19 +1,30 @@
 package main
 
 import (
+    "context"
+    "errors"
     "flag"
     "log"
+    "net/http"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
 
-    "github.com/sirupsen/logrus"
     "facebook-scraper/internal/api"
     "facebook-scraper/internal/config"
     "facebook-scraper/internal/database"
+    "facebook-scraper/internal/scraper"
+    "facebook-scraper/internal/utils"
 )
 
 func main() {
     var (
         configFile = flag.String("config", "configs/config.yaml", "Configuration file path")
         port       = flag.String("port", "8080", "API server port")
+        tlsCert    = flag.String("tls-cert", "", "TLS certificate file; serves HTTPS with -tls-key (overrides api.tls.cert_file)")
+        tlsKey     = flag.String("tls-key", "", "TLS private key file (overrides api.tls.key_file)")
+        redirect   = flag.String("http-redirect-port", "", "Also listen on this port and redirect HTTP to HTTPS (overrides api.tls.redirect_port)")
     )
     flag.Parse()
 
@@ -24,12 +35,7 @@ func main() {
     }
 
     // Setup logger
-    logger := logrus.New()
-    if cfg.Logging.Level == "debug" {
-        logger.SetLevel(logrus.DebugLevel)
-    } else {
-        logger.SetLevel(logrus.InfoLevel)
-    }
+    logger := utils.NewLogger(cfg.Logging.Level, cfg.Logging.Format)
 
     // Initialize database
     db, err := database.NewConnection(&cfg.Database, logger)
@@ -40,17 +46,108 @@ func main() {
 
     // Create API server
     server := api.NewServer(db, logger, *port)
+    server.SetMetricsFile(cfg.Monitoring.MetricsFile)
+    server.SetAPIKey(cfg.API.APIKey)
+    server.SetStatsOptions(api.StatsOptions{
+        MinLikes: cfg.Filter.MinLikes,
+        Days:     cfg.Filter.DaysBack,
+    })
+    tlsOpts := api.TLSOptions{
+        CertFile:     cfg.API.TLS.CertFile,
+        KeyFile:      cfg.API.TLS.KeyFile,
+        RedirectPort: cfg.API.TLS.RedirectPort,
+    }
+    if *tlsCert != "" || *tlsKey != "" {
+        tlsOpts.CertFile, tlsOpts.KeyFile = *tlsCert, *tlsKey
+    }
+    if *redirect != "" {
+        tlsOpts.RedirectPort = *redirect
+    }
+    if (tlsOpts.CertFile == "") != (tlsOpts.KeyFile == "") {
+        logger.Fatal("TLS needs both a certificate and a key")
+    }
+    server.SetTLSOptions(tlsOpts)
+    server.SetTimeoutOptions(api.TimeoutOptions{
+        Read:           time.Duration(cfg.API.ReadTimeout) * time.Second,
+        Write:          time.Duration(cfg.API.WriteTimeout) * time.Second,
+        Idle:           time.Duration(cfg.API.IdleTimeout) * time.Second,
+        MaxHeaderBytes: cfg.API.MaxHeaderBytes,
+    })
+    server.SetCORSOptions(api.CORSOptions{
+        Origins: cfg.API.CORSOrigins,
+        Methods: cfg.API.CORSMethods,
+        Headers: cfg.API.CORSHeaders,
+    })
+
+    groups, groupsErr := config.LoadGroups("configs/groups.yaml")
+
+    // On-demand scraping needs working cookies, so it's only set up when enabled
+    if cfg.API.APIKey != "" {
+        fbScraper, err := scraper.NewFromConfig(cfg, logger, db)
+        if err == nil {
+            err = fbScraper.ApplyGroupFilters(groups, cfg.Filter)
+        }
+        if err == nil {
+            err = fbScraper.Initialize()
+        }
+        if err != nil {
+            logger.Warnf("On-demand scraping disabled: %v", err)
+        } else {
+            defer fbScraper.Close()
+            server.SetScraper(fbScraper)
+        }
+    }
+
+    var groupNames []string
+    if groupsErr != nil {
+        logger.Warnf("Dashboard will not list groups: %v", groupsErr)
+    } else {
+        for _, group := range groups {
+            groupNames = append(groupNames, group.Name)
+        }
+    }
+    server.SetDashboardOptions(api.DashboardOptions{
+        MinLikes: cfg.Filter.MinLikes,
+        Groups:   groupNames,
+    })
 
     logger.Infof("Starting Facebook Scraper API server on port %s", *port)
     logger.Info("Available endpoints:")
     logger.Info("  GET  /api/posts - List posts with pagination")
+    logger.Info("  GET  /api/posts/{post_id} - Get a single post")
+    logger.Info("  GET  /api/posts/{post_id}/history - Engagement history of a post")
     logger.Info("  GET  /api/posts/group/{id} - Get posts by group")
+    logger.Info("  GET  /api/search - Search posts by content, author, hashtag or group")
     logger.Info("  GET  /api/stats - Get scraping statistics")
+    logger.Info("  GET  /api/groups - Groups with stored posts")
+    logger.Info("  GET  /api/authors/top - Top authors")
+    logger.Info("  GET  /api/trends - Engagement trends")
+    logger.Info("  GET  /api/history - Recent scraping runs")
     logger.Info("  GET  /api/export/csv - Export posts to CSV")
+    logger.Info("  GET  /api/export/json - Export posts to JSON")
     logger.Info("  GET  /api/health - Health check")
+    logger.Info("  POST /api/scrape - Start an on-demand scrape (API key)")
+    logger.Info("  GET  /api/scrape/{job_id} - Scrape job status (API key)")
+    logger.Info("  GET  /metrics - Prometheus metrics")
     logger.Info("  GET  /dashboard - Web dashboard")
 
-    if err := server.Start(); err != nil {
+    // Stop accepting requests and cancel on-demand scrapes on Ctrl-C or SIGTERM
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        <-ctx.Done()
+        logger.Info("Shutting down API server...")
+        shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        defer cancel()
+        if err := server.Shutdown(shutdownCtx); err != nil {
+            logger.Errorf("Shutdown failed: %v", err)
+        }
+    }()
+
+    if err := server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
         logger.Fatalf("Failed to start server: %v", err)
     }
+    <-done
 }
\ No newline at end of file
@@ -1,68 +1,120 @@
 package main
 
 import (
+    "bufio"
+    "context"
     "flag"
     "fmt"
     "log"
+    "os"
+    "strings"
     "time"
 
-    "github.com/sirupsen/logrus"
     "facebook-scraper/internal/config"
     "facebook-scraper/internal/database"
     "facebook-scraper/internal/monitoring"
+    "facebook-scraper/internal/utils"
 )
 
 func main() {
     var (
         configFile  = flag.String("config", "configs/config.yaml", "Configuration file path")
-        metricsFile = flag.String("metrics", "data/metrics.json", "Metrics file path")
+        metricsFile = flag.String("metrics", "", "Metrics file path (defaults to monitoring.metrics_file from config)")
         report      = flag.Bool("report", false, "Generate and display monitoring report")
         alerts      = flag.Bool("alerts", false, "Check and display alerts")
+        history     = flag.Int("history", 0, "Show the last N scraping runs")
+        reset       = flag.Bool("reset", false, "Clear all accumulated metrics")
+        force       = flag.Bool("force", false, "Don't ask for confirmation with -reset")
     )
     flag.Parse()
 
-    // Setup logger
-    logger := logrus.New()
-    logger.SetLevel(logrus.InfoLevel)
-
     // Load configuration
     cfg, err := config.Load(*configFile)
     if err != nil {
         log.Fatalf("Failed to load config: %v", err)
     }
 
-    // Initialize database
-    db, err := database.NewConnection(&cfg.Database, logger)
-    if err != nil {
-        logger.Fatalf("Failed to connect to database: %v", err)
-    }
-    defer db.Close()
+    // Setup logger; debug output would drown the report, so stay at info
+    logger := utils.NewLogger("info", cfg.Logging.Format)
 
     // Initialize monitor
+    if *metricsFile == "" {
+        *metricsFile = cfg.Monitoring.MetricsFile
+    }
     monitor := monitoring.NewMonitor(logger, *metricsFile)
 
     if *report {
         // Generate and display report
         fmt.Println(monitor.GenerateReport())
         
-        // Also show database stats
-        stats, err := db.GetScrapingStats()
+        // Also show database stats; only the report needs the database
+        db, err := database.NewConnection(&cfg.Database, logger)
+        if err != nil {
+            logger.Fatalf("Failed to connect to database: %v", err)
+        }
+        defer db.Close()
+
+        stats, err := db.GetScrapingStats(context.Background(), cfg.Filter.MinLikes, cfg.Filter.DaysBack)
         if err != nil {
             logger.Errorf("Failed to get database stats: %v", err)
         } else {
             fmt.Println("\nDatabase Statistics:")
             fmt.Printf("- Total Posts: %v\n", stats["total_posts"])
-            fmt.Printf("- High Engagement Posts: %v\n", stats["high_engagement_posts"])
+            fmt.Printf("- High Engagement Posts (%d+ likes, past %d days): %v\n",
+                cfg.Filter.MinLikes, cfg.Filter.DaysBack, stats["high_engagement_posts"])
             fmt.Printf("- Average Likes: %.2f\n", stats["average_likes"])
+            fmt.Printf("- Median Likes: %.2f (90th percentile: %.2f)\n", stats["median_likes"], stats["p90_likes"])
             fmt.Printf("- Groups Scraped: %v\n", stats["groups_scraped"])
             fmt.Printf("- Last Scraped: %v\n", stats["last_scraped_at"])
         }
         return
     }
 
+    if *reset {
+        if !*force && !confirm(fmt.Sprintf("Reset all metrics in %s?", *metricsFile)) {
+            fmt.Println("Reset cancelled")
+            return
+        }
+        monitor.Reset()
+        fmt.Println("Metrics reset")
+        return
+    }
+
+    if *history > 0 {
+        runs := monitor.GetHistory()
+        if len(runs) > *history {
+            runs = runs[len(runs)-*history:]
+        }
+        fmt.Printf("Last %d scraping runs:\n", len(runs))
+        for _, run := range runs {
+            status := "ok"
+            if run.Failed {
+                status = "failed"
+            }
+            fmt.Printf("  %s  %-20s posts=%-5d errors=%-3d duration=%-10s %s\n",
+                run.Timestamp.Format("2006-01-02 15:04:05"), run.Group, run.Posts, run.Errors,
+                run.Duration.Round(time.Millisecond), status)
+        }
+        return
+    }
+
     if *alerts {
         // Check and display alerts
         alertManager := monitoring.NewAlertManager(monitor, logger)
+        var webhooks []monitoring.Webhook
+        for _, webhook := range cfg.Alerts.Webhooks {
+            webhooks = append(webhooks, monitoring.Webhook{URL: webhook.URL, Headers: webhook.Headers})
+        }
+        alertManager.SetWebhooks(webhooks)
+        thresholds := make(map[string]monitoring.GroupThresholds)
+        for groupID, group := range cfg.Alerts.Groups {
+            thresholds[groupID] = monitoring.GroupThresholds{
+                MaxHoursSinceScrape: group.MaxHoursSinceScrape,
+                MaxErrorRate:        group.MaxErrorRate,
+                MaxEmptyRuns:        group.MaxEmptyRuns,
+            }
+        }
+        alertManager.SetGroupThresholds(thresholds)
         alerts := alertManager.CheckAlerts()
         
         if len(alerts) == 0 {
@@ -72,6 +124,7 @@ func main() {
             for _, alert := range alerts {
                 fmt.Printf("  - %s\n", alert)
             }
+            alertManager.SendAlerts(alerts)
         }
         return
     }
@@ -88,4 +141,12 @@ func main() {
     if warning, exists := health["warning"]; exists {
         fmt.Printf("- Warning: %s\n", warning)
     }
-}
\ No newline at end of file
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+    fmt.Printf("%s [y/N] ", question)
+    answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+    answer = strings.ToLower(strings.TrimSpace(answer))
+    return answer == "y" || answer == "yes"
+}
@@ -1,21 +1,43 @@
 package main
 
 import (
+    "context"
+    "errors"
     "flag"
+    "fmt"
     "log"
     "os"
+    "os/signal"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "syscall"
     "time"
 
     "github.com/sirupsen/logrus"
+    "gopkg.in/natefinch/lumberjack.v2"
     "facebook-scraper/internal/config"
     "facebook-scraper/internal/database"
+    "facebook-scraper/internal/monitoring"
     "facebook-scraper/internal/scraper"
+    "facebook-scraper/internal/utils"
+    "facebook-scraper/pkg/types"
 )
 
+// dryRunSamples is how many matching posts -dry-run shows per group
+const dryRunSamples = 3
+
 func main() {
     var (
         configFile = flag.String("config", "configs/config.yaml", "Configuration file path")
         extractCmd = flag.Bool("extract-cookies", false, "Show instructions for extracting cookies")
+        cleanupCmd = flag.Bool("cleanup", false, "Delete posts older than database.retention_days and exit")
+        interval   = flag.Duration("interval", 0, "Scrape repeatedly, waiting this long between cycles, e.g. 30m or 6h (overrides scraper.interval)")
+        dryRun     = flag.Bool("dry-run", false, "Scrape and filter once without touching the database, then print what would be saved")
+        groupID    = flag.String("group", "", "Scrape only this group ID instead of the groups file")
+        groupIDs   = flag.String("groups", "", "Scrape only these comma-separated group IDs instead of the groups file")
+        outputFmt  = flag.String("output", "", "Also write posts to data/posts.<ext>: json, csv, jsonl or none (overrides scraper.output_format)")
     )
     flag.Parse()
 
@@ -23,6 +45,9 @@ func main() {
         scraper.ExtractCookiesFromBrowser()
         return
     }
+    if *dryRun && *cleanupCmd {
+        log.Fatal("-dry-run and -cleanup can't be combined")
+    }
 
     // Load configuration
     cfg, err := config.Load(*configFile)
@@ -30,52 +55,75 @@ func main() {
         log.Fatalf("Failed to load config: %v", err)
     }
 
-    // Setup logger
-    logger := logrus.New()
-    if cfg.Logging.Level == "debug" {
-        logger.SetLevel(logrus.DebugLevel)
-    } else {
-        logger.SetLevel(logrus.InfoLevel)
+    if *outputFmt != "" {
+        cfg.Scraper.OutputFormat = *outputFmt
+    }
+    if *interval == 0 {
+        // Load has already validated the configured interval
+        *interval, _ = cfg.Scraper.ScrapeInterval()
     }
+
+    // Setup logger
+    logger := utils.NewLogger(cfg.Logging.Level, cfg.Logging.Format)
     
     // Create logs directory if it doesn't exist
     if err := os.MkdirAll("logs", 0755); err != nil {
         log.Fatalf("Failed to create logs directory: %v", err)
     }
 
-    // Create log file
+    // Log to a file that is rotated once it reaches max_size megabytes
     if cfg.Logging.File != "" {
-        file, err := os.OpenFile(cfg.Logging.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-        if err != nil {
-            log.Fatalf("Failed to open log file: %v", err)
+        logFile := &lumberjack.Logger{
+            Filename:   cfg.Logging.File,
+            MaxSize:    cfg.Logging.MaxSize,
+            MaxBackups: cfg.Logging.MaxBackups,
+            MaxAge:     cfg.Logging.MaxAge,
         }
-        defer file.Close()
-        logger.SetOutput(file)
+        defer logFile.Close()
+        logger.SetOutput(logFile)
     }
 
-    // Initialize database
-    db, err := database.NewConnection(&cfg.Database, logger)
-    if err != nil {
-        logger.Fatalf("Failed to connect to database: %v", err)
+    // Initialize monitor so runs show up in the monitor command. Dry runs
+    // keep their metrics in memory so they don't skew the real numbers.
+    metricsFile := cfg.Monitoring.MetricsFile
+    if *dryRun {
+        metricsFile = ""
+    } else if err := os.MkdirAll(filepath.Dir(metricsFile), 0755); err != nil {
+        log.Fatalf("Failed to create metrics directory: %v", err)
+    }
+    monitor := monitoring.NewMonitor(logger, metricsFile)
+
+    // Initialize database; dry runs never write to it so don't need one
+    var db *database.DB
+    if !*dryRun {
+        db, err = database.NewConnection(&cfg.Database, logger)
+        if err != nil {
+            logger.Fatalf("Failed to connect to database: %v", err)
+        }
+        defer db.Close()
+
+        // Run migrations
+        if err := db.RunMigrations(); err != nil {
+            logger.Fatalf("Failed to run migrations: %v", err)
+        }
     }
-    defer db.Close()
 
-    // Run migrations
-    if err := db.RunMigrations(); err != nil {
-        logger.Fatalf("Failed to run migrations: %v", err)
+    if *cleanupCmd {
+        if cfg.Database.RetentionDays <= 0 {
+            logger.Fatal("Cleanup needs database.retention_days to be set")
+        }
+        if _, err := cleanupOldPosts(db, cfg.Database.RetentionDays); err != nil {
+            logger.Fatalf("Cleanup failed: %v", err)
+        }
+        return
     }
 
     // Initialize scraper with database
-    fbScraper, err := scraper.NewFacebookScraper(
-        cfg.Facebook.Auth.CookiesFile,
-        cfg.Facebook.Auth.UserAgent,
-        time.Duration(cfg.Facebook.RateLimit.DelayBetweenRequests)*time.Second,
-        logger,
-        db,
-    )
+    fbScraper, err := scraper.NewFromConfig(cfg, logger, db)
     if err != nil {
-        logger.Fatalf("Failed to create Facebook scraper: %v", err)
+        logger.Fatalf("Failed to set up scraper: %v", err)
     }
+    fbScraper.SetDryRun(*dryRun)
 
     // Initialize the scraper (loads cookies and validates auth)
     if err := fbScraper.Initialize(); err != nil {
@@ -83,28 +131,278 @@ func main() {
     }
     defer fbScraper.Close()
 
-    logger.Info("Scraper started successfully - filtering for posts with 1000+ likes in past 5 days")
+    logger.Infof("Scraper started successfully - filtering for posts with %d+ likes in past %d days",
+        cfg.Filter.MinLikes, cfg.Filter.DaysBack)
 
-    // Load groups to scrape
-    groups, err := config.LoadGroups("configs/groups.yaml")
-    if err != nil {
-        logger.Fatalf("Failed to load groups: %v", err)
+    // Load groups to scrape, unless they were given on the command line
+    groups := adHocGroups(*groupID, *groupIDs)
+    if len(groups) > 0 {
+        logger.Infof("Scraping %d group(s) from the command line, ignoring the groups file", len(groups))
+    } else {
+        groups, err = config.LoadGroups("configs/groups.yaml")
+        if err != nil {
+            logger.Fatalf("Failed to load groups: %v", err)
+        }
+        if err := fbScraper.ApplyGroupFilters(groups, cfg.Filter); err != nil {
+            logger.Fatalf("Invalid group filter: %v", err)
+        }
+    }
+
+    // Stop cleanly on Ctrl-C / SIGTERM so deferred cleanup still saves cookies
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    if *dryRun {
+        delay := time.Duration(cfg.Facebook.RateLimit.DelayBetweenRequests) * time.Second
+        scrapeGroups(ctx, fbScraper, monitor, logger, groups, cfg.Scraper.ConcurrentWorkers, delay, cfg.Facebook.RateLimit.JitterPercent)
+        if len(cfg.Search.Queries) > 0 && ctx.Err() == nil {
+            scrapeSearches(ctx, fbScraper, monitor, logger, cfg.Search.Queries, delay, cfg.Facebook.RateLimit.JitterPercent)
+        }
+        printDryRunSummary(fbScraper.DryRunResults(), dryRunSamples)
+        return
+    }
+
+    if *interval <= 0 {
+        runCycle(ctx, fbScraper, monitor, db, logger, cfg, groups)
+        logger.Info("Data saved to PostgreSQL database. Use PgAdmin or connect directly to view results.")
+        return
+    }
+
+    // Daemon mode: scrape every interval until interrupted
+    logger.Infof("Scraping every %s, press Ctrl-C to stop", *interval)
+    for {
+        runCycle(ctx, fbScraper, monitor, db, logger, cfg, groups)
+
+        // Spread cycles by up to 10% so they don't run like clockwork
+        wait := scraper.Jitter(*interval, 10)
+        logger.Infof("Next scraping cycle at %s", time.Now().Add(wait).Format("2006-01-02 15:04:05"))
+        if scraper.SleepContext(ctx, wait) != nil {
+            logger.Info("Scheduler stopped")
+            return
+        }
+    }
+}
+
+// runCycle scrapes every group and search once, records the cycle in the
+// monitor and then writes the output file and prunes old posts
+func runCycle(ctx context.Context, fbScraper *scraper.FacebookScraper, monitor *monitoring.Monitor, db *database.DB,
+    logger *logrus.Logger, cfg *config.Config, groups []config.Group) {
+    start := time.Now()
+    delay := time.Duration(cfg.Facebook.RateLimit.DelayBetweenRequests) * time.Second
+    jitterPercent := cfg.Facebook.RateLimit.JitterPercent
+    totalPosts, failedGroups := scrapeGroups(ctx, fbScraper, monitor, logger, groups, cfg.Scraper.ConcurrentWorkers, delay, jitterPercent)
+    if len(cfg.Search.Queries) > 0 && ctx.Err() == nil {
+        searchPosts, failedSearches := scrapeSearches(ctx, fbScraper, monitor, logger, cfg.Search.Queries, delay, jitterPercent)
+        totalPosts += searchPosts
+        failedGroups = append(failedGroups, failedSearches...)
+    }
+    if ctx.Err() != nil {
+        logger.Warn("Scraping interrupted, shutting down")
+    } else {
+        monitor.RecordCycle(monitoring.CycleRecord{
+            Started:      start,
+            Duration:     time.Since(start),
+            Posts:        totalPosts,
+            FailedGroups: len(failedGroups),
+        })
+    }
+    if len(failedGroups) > 0 {
+        logger.Warnf("%d of %d groups failed: %s", len(failedGroups), len(groups), strings.Join(failedGroups, ", "))
+    }
+
+    logger.Infof("Scraping completed! Total posts meeting criteria (%d+ likes, past %d days): %d",
+        cfg.Filter.MinLikes, cfg.Filter.DaysBack, totalPosts)
+
+    if _, err := fbScraper.WriteOutput("data"); err != nil {
+        logger.Errorf("Failed to write output file: %v", err)
+    }
+
+    if cfg.Database.RetentionDays > 0 {
+        if _, err := cleanupOldPosts(db, cfg.Database.RetentionDays); err != nil {
+            logger.Errorf("Cleanup failed: %v", err)
+        }
+    }
+}
+
+// printDryRunSummary prints per-source counts, the best matching posts of each
+// source and the combined filter stats
+func printDryRunSummary(results []scraper.DryRunResult, samples int) {
+    fmt.Println("\nDry run - nothing was saved")
+    fmt.Println("===========================")
+
+    var total types.FilterStats
+    for _, result := range results {
+        total.Add(result.Filter)
+        fmt.Printf("\n%s %s: %d scraped, %d would be saved\n",
+            result.SourceType, result.SourceID, result.Scraped, len(result.Posts))
+
+        posts := append([]types.ScrapedPost(nil), result.Posts...)
+        sort.Slice(posts, func(i, j int) bool {
+            return posts[i].LikesCount > posts[j].LikesCount
+        })
+        if len(posts) > samples {
+            posts = posts[:samples]
+        }
+        for _, post := range posts {
+            fmt.Printf("  - %d likes, %d comments, %d shares | %s | %s: %s\n",
+                post.LikesCount, post.CommentsCount, post.SharesCount,
+                post.PostTime.Format("2006-01-02"), post.AuthorName, preview(post.Content, 80))
+        }
+        fmt.Printf("  Filter: %s\n", result.Filter.String())
+    }
+
+    fmt.Printf("\nTotal: %s\n", total.String())
+}
+
+// preview shortens text to one line of at most n characters
+func preview(text string, n int) string {
+    text = strings.Join(strings.Fields(text), " ")
+    runes := []rune(text)
+    if len(runes) <= n {
+        return text
+    }
+    return string(runes[:n-3]) + "..."
+}
+
+// cleanupOldPosts deletes posts scraped more than retentionDays ago
+func cleanupOldPosts(db *database.DB, retentionDays int) (int64, error) {
+    return db.DeleteOldPosts(context.Background(), time.Duration(retentionDays)*24*time.Hour)
+}
+
+// scrapeGroups processes groups with a pool of workers. Each worker waits its
+// own delay, randomized by jitterPercent, between groups so they don't hit
+// Facebook in lockstep.
+func scrapeGroups(ctx context.Context, fbScraper *scraper.FacebookScraper, monitor *monitoring.Monitor, logger *logrus.Logger,
+    groups []config.Group, workers int, delay time.Duration, jitterPercent int) (int, []string) {
+    if workers < 1 {
+        workers = 1
+    }
+    if workers > len(groups) {
+        workers = len(groups)
     }
 
-    totalPosts := 0
-    // Scrape each group
+    jobs := make(chan config.Group)
+    var (
+        mu           sync.Mutex
+        wg           sync.WaitGroup
+        totalPosts   int
+        failedGroups []string
+    )
+
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func(worker int) {
+            defer wg.Done()
+
+            // Stagger worker start-up across the delay window
+            if scraper.SleepContext(ctx, delay*time.Duration(worker)/time.Duration(workers)) != nil {
+                return
+            }
+
+            for group := range jobs {
+                logger.Infof("[worker %d] Scraping %s: %s (%s)", worker, groupType(group), group.Name, group.ID)
+                groupStart := time.Now()
+                scrape := fbScraper.ScrapeGroup
+                if group.IsPage() {
+                    scrape = fbScraper.ScrapePage
+                }
+                stats, err := scrape(ctx, group.ID)
+
+                mu.Lock()
+                if ctx.Err() != nil {
+                    mu.Unlock()
+                    return
+                }
+                if errors.Is(err, scraper.ErrAuthExpired) {
+                    logger.Errorf("Failed to scrape group %s: %v - re-export your cookies (see -extract-cookies)", group.ID, err)
+                    monitor.RecordScrapingFailure(group.ID, time.Since(groupStart))
+                    failedGroups = append(failedGroups, group.ID)
+                } else if err != nil {
+                    logger.Errorf("Failed to scrape group %s: %v", group.ID, err)
+                    monitor.RecordScrapingFailure(group.ID, time.Since(groupStart))
+                    failedGroups = append(failedGroups, group.ID)
+                } else {
+                    totalPosts += stats.SavedPosts
+                    monitor.RecordScrapingRun(group.ID, stats.TotalPosts, stats.ProcessingTime, stats.ErrorPosts)
+                    logger.Infof("Successfully scraped group: %s (%d saved, %d skipped, %d errors)",
+                        group.Name, stats.SavedPosts, stats.SkippedPosts, stats.ErrorPosts)
+                }
+                mu.Unlock()
+
+                // Add delay between groups to respect rate limits
+                if scraper.SleepContext(ctx, scraper.Jitter(delay, jitterPercent)) != nil {
+                    return
+                }
+            }
+        }(w)
+    }
+
+feed:
     for _, group := range groups {
-        logger.Infof("Scraping group: %s (%s)", group.Name, group.ID)
-        if err := fbScraper.ScrapeGroup(group.ID); err != nil {
-            logger.Errorf("Failed to scrape group %s: %v", group.ID, err)
+        select {
+        case jobs <- group:
+        case <-ctx.Done():
+            break feed
+        }
+    }
+    close(jobs)
+    wg.Wait()
+
+    return totalPosts, failedGroups
+}
+
+// scrapeSearches runs the keyword searches one after another, waiting the
+// usual jittered delay between them
+func scrapeSearches(ctx context.Context, fbScraper *scraper.FacebookScraper, monitor *monitoring.Monitor, logger *logrus.Logger,
+    queries []string, delay time.Duration, jitterPercent int) (int, []string) {
+    var (
+        totalPosts int
+        failed     []string
+    )
+
+    for i, query := range queries {
+        if i > 0 && scraper.SleepContext(ctx, scraper.Jitter(delay, jitterPercent)) != nil {
+            break
+        }
+
+        logger.Infof("Searching posts for %q", query)
+        id := scraper.SearchGroupID + ":" + query
+        start := time.Now()
+        stats, err := fbScraper.ScrapeSearch(ctx, query)
+        if ctx.Err() != nil {
+            break
+        }
+        if err != nil {
+            logger.Errorf("Failed to search %q: %v", query, err)
+            monitor.RecordScrapingFailure(id, time.Since(start))
+            failed = append(failed, id)
             continue
         }
-        logger.Infof("Successfully scraped group: %s", group.Name)
-        
-        // Add delay between groups to respect rate limits
-        time.Sleep(time.Duration(cfg.Facebook.RateLimit.DelayBetweenRequests) * time.Second)
+
+        totalPosts += stats.SavedPosts
+        monitor.RecordScrapingRun(id, stats.TotalPosts, stats.ProcessingTime, stats.ErrorPosts)
+        logger.Infof("Search %q done (%d saved, %d skipped, %d errors)",
+            query, stats.SavedPosts, stats.SkippedPosts, stats.ErrorPosts)
+    }
+
+    return totalPosts, failed
+}
+
+// adHocGroups builds the groups given with -group and -groups, named by
+// their IDs
+func adHocGroups(groupID, groupIDs string) []config.Group {
+    var groups []config.Group
+    for _, id := range append([]string{groupID}, strings.Split(groupIDs, ",")...) {
+        if id = strings.TrimSpace(id); id != "" {
+            groups = append(groups, config.Group{ID: id, Name: id})
+        }
     }
+    return groups
+}
 
-    logger.Infof("Scraping completed! Total posts meeting criteria (1000+ likes, past 5 days): %d", totalPosts)
-    logger.Info("Data saved to PostgreSQL database. Use PgAdmin or connect directly to view results.")
-}
\ No newline at end of file
+func groupType(group config.Group) string {
+    if group.IsPage() {
+        return scraper.SourcePage
+    }
+    return scraper.SourceGroup
+}
@@ -27,11 +27,28 @@ func main() {
         log.Fatalf("Failed to create auth manager: %v", err)
     }
     
+    if err := authManager.SetProxies(cfg.Facebook.Auth.Proxies()); err != nil {
+        log.Fatalf("Failed to configure proxies: %v", err)
+    }
+    
     fmt.Println("Testing cookie loading...")
     if err := authManager.LoadCookies(); err != nil {
         log.Fatalf("Failed to load cookies: %v", err)
     }
     
+    fmt.Println("Checking required cookies...")
+    results := authManager.CheckRequiredCookies()
+    for _, name := range scraper.RequiredCookies {
+        if err := results[name]; err != nil {
+            fmt.Printf("  ❌ %s: %v\n", name, err)
+        } else {
+            fmt.Printf("  ✅ %s\n", name)
+        }
+    }
+    if err := authManager.ValidateCookieFormat(); err != nil {
+        log.Fatalf("Cookie validation failed: %v", err)
+    }
+    
     fmt.Println("Testing authentication...")
     if err := authManager.ValidateAuth(); err != nil {
         log.Fatalf("Authentication failed: %v", err)
@@ -0,0 +1,137 @@
+package api
+
+import (
+    "compress/gzip"
+    "net/http"
+    "strings"
+)
+
+// gzipMinSize is the smallest body worth compressing; below it the gzip
+// header and CPU cost outweigh the savings
+const gzipMinSize = 1024
+
+// gzipHandler compresses responses for clients that send Accept-Encoding: gzip
+func gzipHandler(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Add("Vary", "Accept-Encoding")
+        if !acceptsGzip(r) || r.Method == http.MethodHead {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        gw := &gzipResponseWriter{ResponseWriter: w}
+        defer gw.Close()
+        next.ServeHTTP(gw, r)
+    })
+}
+
+func acceptsGzip(r *http.Request) bool {
+    for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+        if strings.TrimSpace(strings.SplitN(encoding, ";", 2)[0]) == "gzip" {
+            return true
+        }
+    }
+    return false
+}
+
+// gzipResponseWriter buffers the start of the body until it knows whether
+// compression is worthwhile, then either streams through gzip or writes as is
+type gzipResponseWriter struct {
+    http.ResponseWriter
+    gz      *gzip.Writer
+    buf     []byte
+    status  int
+    decided bool
+}
+
+func (gw *gzipResponseWriter) WriteHeader(status int) {
+    if gw.status == 0 {
+        gw.status = status
+    }
+}
+
+func (gw *gzipResponseWriter) Write(p []byte) (int, error) {
+    if gw.decided {
+        if gw.gz != nil {
+            return gw.gz.Write(p)
+        }
+        return gw.ResponseWriter.Write(p)
+    }
+
+    gw.buf = append(gw.buf, p...)
+    if len(gw.buf) >= gzipMinSize {
+        if err := gw.decide(true); err != nil {
+            return 0, err
+        }
+    }
+    return len(p), nil
+}
+
+// Flush sends buffered data so streamed exports keep flowing
+func (gw *gzipResponseWriter) Flush() {
+    if !gw.decided {
+        gw.decide(len(gw.buf) >= gzipMinSize)
+    }
+    if gw.gz != nil {
+        gw.gz.Flush()
+    }
+    if flusher, ok := gw.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// Close writes whatever is still buffered and terminates the gzip stream
+func (gw *gzipResponseWriter) Close() error {
+    if !gw.decided {
+        if err := gw.decide(false); err != nil {
+            return err
+        }
+    }
+    if gw.gz != nil {
+        return gw.gz.Close()
+    }
+    return nil
+}
+
+// decide commits the headers, compressing only when the body is large
+// enough and not already compressed, then drains the buffer
+func (gw *gzipResponseWriter) decide(large bool) error {
+    gw.decided = true
+    header := gw.Header()
+    if header.Get("Content-Type") == "" && len(gw.buf) > 0 {
+        // net/http would otherwise sniff the compressed bytes
+        header.Set("Content-Type", http.DetectContentType(gw.buf))
+    }
+    if large && header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+        header.Set("Content-Encoding", "gzip")
+        header.Del("Content-Length")
+        gw.gz = gzip.NewWriter(gw.ResponseWriter)
+    }
+
+    if gw.status != 0 {
+        gw.ResponseWriter.WriteHeader(gw.status)
+    }
+    if len(gw.buf) == 0 {
+        return nil
+    }
+
+    var err error
+    if gw.gz != nil {
+        _, err = gw.gz.Write(gw.buf)
+    } else {
+        _, err = gw.ResponseWriter.Write(gw.buf)
+    }
+    gw.buf = nil
+    return err
+}
+
+// compressible rejects content types that are already compressed
+func compressible(contentType string) bool {
+    contentType = strings.ToLower(contentType)
+    for _, prefix := range []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/x-gzip"} {
+        if strings.HasPrefix(contentType, prefix) {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+    "crypto/rand"
+    "crypto/subtle"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "facebook-scraper/internal/scraper"
+)
+
+// Scrape job states
+const (
+    JobRunning = "running"
+    JobDone    = "done"
+    JobError   = "error"
+)
+
+// ScrapeJob tracks an on-demand scrape started through POST /api/scrape
+type ScrapeJob struct {
+    ID         string     `json:"id"`
+    GroupID    string     `json:"group_id"`
+    Status     string     `json:"status"`
+    PostsSaved int        `json:"posts_saved"`
+    Error      string     `json:"error,omitempty"`
+    StartedAt  time.Time  `json:"started_at"`
+    FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished jobs are kept for polling for jobTTL, and at most maxJobs are
+// kept in total so a busy server doesn't grow without bound
+const (
+    jobTTL  = time.Hour
+    maxJobs = 1000
+)
+
+// jobStore keeps scrape jobs in memory for polling
+type jobStore struct {
+    mu   sync.Mutex
+    jobs map[string]*ScrapeJob
+}
+
+func newJobStore() *jobStore {
+    return &jobStore{jobs: make(map[string]*ScrapeJob)}
+}
+
+// add registers a job and returns a copy of it for the response, since the
+// stored job is updated by the scrape goroutine
+func (js *jobStore) add(job *ScrapeJob) ScrapeJob {
+    js.mu.Lock()
+    defer js.mu.Unlock()
+
+    js.evict(time.Now())
+    js.jobs[job.ID] = job
+    return *job
+}
+
+// evict drops finished jobs past jobTTL, then the oldest finished jobs while
+// the store is full. Running jobs are never dropped. Callers hold js.mu.
+func (js *jobStore) evict(now time.Time) {
+    var finished []*ScrapeJob
+    for id, job := range js.jobs {
+        if job.FinishedAt == nil {
+            continue
+        }
+        if now.Sub(*job.FinishedAt) > jobTTL {
+            delete(js.jobs, id)
+            continue
+        }
+        finished = append(finished, job)
+    }
+
+    if len(js.jobs) < maxJobs {
+        return
+    }
+    sort.Slice(finished, func(i, j int) bool {
+        return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+    })
+    for _, job := range finished {
+        if len(js.jobs) < maxJobs {
+            break
+        }
+        delete(js.jobs, job.ID)
+    }
+}
+
+// get returns a copy so callers can read it without holding the lock
+func (js *jobStore) get(id string) (ScrapeJob, bool) {
+    js.mu.Lock()
+    defer js.mu.Unlock()
+
+    job, ok := js.jobs[id]
+    if !ok {
+        return ScrapeJob{}, false
+    }
+    return *job, true
+}
+
+func (js *jobStore) finish(id string, stats *scraper.ScrapingStats, err error) {
+    js.mu.Lock()
+    defer js.mu.Unlock()
+
+    job, ok := js.jobs[id]
+    if !ok {
+        return
+    }
+    now := time.Now()
+    job.FinishedAt = &now
+    if err != nil {
+        job.Status = JobError
+        job.Error = err.Error()
+        return
+    }
+    job.Status = JobDone
+    job.PostsSaved = stats.SavedPosts
+}
+
+func newJobID() (string, error) {
+    buf := make([]byte, 8)
+    if _, err := rand.Read(buf); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(buf), nil
+}
+
+// SetScraper enables on-demand scraping through /api/scrape
+func (s *Server) SetScraper(fbScraper *scraper.FacebookScraper) {
+    s.scraper = fbScraper
+}
+
+// SetAPIKey sets the key required by control endpoints
+func (s *Server) SetAPIKey(apiKey string) {
+    s.apiKey = apiKey
+}
+
+// requireAPIKey rejects requests without the configured key in the X-API-Key
+// header or as a bearer token. Without a configured key the endpoint is off.
+func (s *Server) requireAPIKey(next http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if s.apiKey == "" {
+            s.writeError(w, "API key not configured, endpoint disabled", http.StatusForbidden)
+            return
+        }
+
+        key := r.Header.Get("X-API-Key")
+        if key == "" {
+            key = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+        }
+        if subtle.ConstantTimeCompare([]byte(key), []byte(s.apiKey)) != 1 {
+            s.writeError(w, "Invalid or missing API key", http.StatusUnauthorized)
+            return
+        }
+
+        next(w, r)
+    }
+}
+
+func (s *Server) handleStartScrape(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+        return
+    }
+    if s.scraper == nil {
+        s.writeError(w, "Scraping is not enabled on this server", http.StatusServiceUnavailable)
+        return
+    }
+
+    var request struct {
+        GroupID string `json:"group_id"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil || strings.TrimSpace(request.GroupID) == "" {
+        s.writeError(w, "Request body must be {\"group_id\": \"...\"}", http.StatusBadRequest)
+        return
+    }
+
+    id, err := newJobID()
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to create job: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    job := s.jobs.add(&ScrapeJob{
+        ID:        id,
+        GroupID:   strings.TrimSpace(request.GroupID),
+        Status:    JobRunning,
+        StartedAt: time.Now(),
+    })
+
+    // The scrape outlives the request, so it runs until the server shuts down
+    // rather than until the client disconnects
+    go func(groupID string) {
+        s.logger.Infof("Starting on-demand scrape %s for group %s", id, groupID)
+        stats, err := s.scraper.ScrapeGroup(s.ctx, groupID)
+        if err != nil {
+            s.logger.Errorf("On-demand scrape %s failed: %v", id, err)
+        }
+        s.jobs.finish(id, stats, err)
+    }(job.GroupID)
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusAccepted)
+    s.writeJSON(w, APIResponse{
+        Success: true,
+        Data:    job,
+    })
+}
+
+func (s *Server) handleScrapeStatus(w http.ResponseWriter, r *http.Request) {
+    id := strings.Trim(r.URL.Path[len("/api/scrape/"):], "/")
+    if id == "" {
+        s.writeError(w, "Job ID is required", http.StatusBadRequest)
+        return
+    }
+
+    job, ok := s.jobs.get(id)
+    if !ok {
+        s.writeError(w, fmt.Sprintf("Job %s not found", id), http.StatusNotFound)
+        return
+    }
+
+    s.writeJSON(w, APIResponse{
+        Success: true,
+        Data:    job,
+    })
+}
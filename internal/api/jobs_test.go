@@ -0,0 +1,91 @@
+package api
+
+import (
+    "errors"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "facebook-scraper/internal/scraper"
+)
+
+func TestJobStoreAddReturnsCopy(t *testing.T) {
+    js := newJobStore()
+    job := js.add(&ScrapeJob{ID: "a", Status: JobRunning, StartedAt: time.Now()})
+
+    js.finish("a", &scraper.ScrapingStats{SavedPosts: 3}, nil)
+
+    if job.Status != JobRunning || job.FinishedAt != nil {
+        t.Errorf("returned job changed after finish: %+v", job)
+    }
+    stored, _ := js.get("a")
+    if stored.Status != JobDone || stored.PostsSaved != 3 {
+        t.Errorf("stored job = %+v, want done with 3 posts", stored)
+    }
+}
+
+// Run with -race: finishing a job while it is being read must not race
+func TestJobStoreConcurrentFinishAndGet(t *testing.T) {
+    js := newJobStore()
+    for i := 0; i < 50; i++ {
+        js.add(&ScrapeJob{ID: fmt.Sprint(i), Status: JobRunning, StartedAt: time.Now()})
+    }
+
+    var wg sync.WaitGroup
+    for i := 0; i < 50; i++ {
+        wg.Add(2)
+        go func(id string) {
+            defer wg.Done()
+            js.finish(id, nil, errors.New("boom"))
+        }(fmt.Sprint(i))
+        go func(id string) {
+            defer wg.Done()
+            if job, ok := js.get(id); ok {
+                _ = job.Status
+            }
+        }(fmt.Sprint(i))
+    }
+    wg.Wait()
+
+    for i := 0; i < 50; i++ {
+        if job, _ := js.get(fmt.Sprint(i)); job.Status != JobError {
+            t.Fatalf("job %d status = %q, want %q", i, job.Status, JobError)
+        }
+    }
+}
+
+func TestJobStoreEvictsExpiredJobs(t *testing.T) {
+    js := newJobStore()
+    expired := time.Now().Add(-2 * jobTTL)
+    js.add(&ScrapeJob{ID: "old", Status: JobDone, FinishedAt: &expired})
+    js.add(&ScrapeJob{ID: "running", Status: JobRunning, StartedAt: expired})
+    js.add(&ScrapeJob{ID: "new", Status: JobRunning})
+
+    if _, ok := js.get("old"); ok {
+        t.Error("job finished past the TTL was kept")
+    }
+    if _, ok := js.get("running"); !ok {
+        t.Error("running job was evicted")
+    }
+}
+
+func TestJobStoreCapsFinishedJobs(t *testing.T) {
+    js := newJobStore()
+    start := time.Now().Add(-time.Minute)
+    for i := 0; i < maxJobs; i++ {
+        finished := start.Add(time.Duration(i) * time.Millisecond)
+        js.add(&ScrapeJob{ID: fmt.Sprint(i), Status: JobDone, FinishedAt: &finished})
+    }
+    js.add(&ScrapeJob{ID: "next", Status: JobRunning})
+
+    if len(js.jobs) != maxJobs {
+        t.Errorf("store holds %d jobs, want %d", len(js.jobs), maxJobs)
+    }
+    if _, ok := js.get("0"); ok {
+        t.Error("oldest finished job was kept")
+    }
+    if _, ok := js.get("next"); !ok {
+        t.Error("new job was not added")
+    }
+}
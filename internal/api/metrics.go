@@ -0,0 +1,167 @@
+package api
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "os"
+    "strconv"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+    "facebook-scraper/internal/database"
+    "facebook-scraper/internal/monitoring"
+)
+
+// metricsTimeout bounds the database queries made for one /metrics scrape
+const metricsTimeout = 5 * time.Second
+
+// handleHistory serves /api/history, the scraper's recent runs from its
+// metrics file, newest last; ?limit= keeps only the latest runs
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+    if s.metricsFile == "" {
+        s.writeError(w, "Metrics file not configured", http.StatusNotFound)
+        return
+    }
+
+    metrics, err := monitoring.ReadMetrics(s.metricsFile)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to read metrics: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    runs := metrics.RunsHistory
+    if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); limit > 0 && len(runs) > limit {
+        runs = runs[len(runs)-limit:]
+    }
+    if runs == nil {
+        runs = []monitoring.RunRecord{}
+    }
+
+    response := APIResponse{
+        Success: true,
+        Data:    runs,
+        Count:   len(runs),
+    }
+
+    s.writeJSON(w, response)
+}
+
+// metricsHandler serves /metrics in the Prometheus exposition format, with
+// the Go runtime and process collectors alongside the scraper's own metrics
+func (s *Server) metricsHandler() http.Handler {
+    registry := prometheus.NewRegistry()
+    registry.MustRegister(
+        collectors.NewGoCollector(),
+        collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+        newScraperCollector(s),
+    )
+    return promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: s.logger})
+}
+
+// scraperCollector reads the database and the scraper's metrics file on
+// every scrape, so the API and the scraper report the same numbers
+type scraperCollector struct {
+    server *Server
+
+    dbUp         *prometheus.Desc
+    dbPing       *prometheus.Desc
+    posts        *prometheus.Desc
+    runs         *prometheus.Desc
+    scrapedPosts *prometheus.Desc
+    savedPosts   *prometheus.Desc
+    scrapeErrors *prometheus.Desc
+    errorRate    *prometheus.Desc
+    postsPerRun  *prometheus.Desc
+    lastRunAge   *prometheus.Desc
+    groupScraped *prometheus.Desc
+    groupErrors  *prometheus.Desc
+}
+
+func newScraperCollector(s *Server) *scraperCollector {
+    group := []string{"group"}
+    return &scraperCollector{
+        server:       s,
+        dbUp:         prometheus.NewDesc("fbscraper_db_up", "Whether the database answered a ping.", nil, nil),
+        dbPing:       prometheus.NewDesc("fbscraper_db_ping_duration_seconds", "Database ping latency.", nil, nil),
+        posts:        prometheus.NewDesc("fbscraper_posts", "Posts stored in the database.", nil, nil),
+        runs:         prometheus.NewDesc("fbscraper_scrape_runs_total", "Scraping runs, successful or not.", nil, nil),
+        scrapedPosts: prometheus.NewDesc("fbscraper_scraped_posts_total", "Posts processed by the scraper.", nil, nil),
+        savedPosts:   prometheus.NewDesc("fbscraper_saved_posts_total", "Posts saved by the scraper.", nil, nil),
+        scrapeErrors: prometheus.NewDesc("fbscraper_scrape_errors_total", "Posts that failed to save.", nil, nil),
+        errorRate:    prometheus.NewDesc("fbscraper_error_rate_percent", "Share of processed posts that failed.", nil, nil),
+        postsPerRun:  prometheus.NewDesc("fbscraper_saved_posts_per_run", "Average posts saved per scraping run.", nil, nil),
+        lastRunAge:   prometheus.NewDesc("fbscraper_last_run_age_seconds", "Seconds since the last scraping run.", nil, nil),
+        groupScraped: prometheus.NewDesc("fbscraper_group_scraped_posts_total", "Posts processed per group.", group, nil),
+        groupErrors:  prometheus.NewDesc("fbscraper_group_errors_total", "Errors per group.", group, nil),
+    }
+}
+
+func (c *scraperCollector) Describe(ch chan<- *prometheus.Desc) {
+    for _, desc := range []*prometheus.Desc{
+        c.dbUp, c.dbPing, c.posts, c.runs, c.scrapedPosts, c.savedPosts, c.scrapeErrors,
+        c.errorRate, c.postsPerRun, c.lastRunAge, c.groupScraped, c.groupErrors,
+    } {
+        ch <- desc
+    }
+}
+
+func (c *scraperCollector) Collect(ch chan<- prometheus.Metric) {
+    s := c.server
+
+    // Database
+    if s.db != nil {
+        start := time.Now()
+        dbUp := 1.0
+        if err := s.db.Ping(); err != nil {
+            dbUp = 0
+        }
+        ch <- prometheus.MustNewConstMetric(c.dbUp, prometheus.GaugeValue, dbUp)
+        ch <- prometheus.MustNewConstMetric(c.dbPing, prometheus.GaugeValue, time.Since(start).Seconds())
+
+        if dbUp == 1 {
+            ctx, cancel := context.WithTimeout(s.ctx, metricsTimeout)
+            total, err := s.db.GetPostsCount(ctx, database.PostsQuery{})
+            cancel()
+            if err == nil {
+                ch <- prometheus.MustNewConstMetric(c.posts, prometheus.GaugeValue, float64(total))
+            }
+        }
+    }
+
+    // Scraper runs
+    if s.metricsFile == "" {
+        return
+    }
+    metrics, err := monitoring.ReadMetrics(s.metricsFile)
+    if err != nil {
+        if !errors.Is(err, os.ErrNotExist) {
+            s.logger.Warnf("Failed to read scraper metrics: %v", err)
+        }
+        return
+    }
+
+    ch <- prometheus.MustNewConstMetric(c.runs, prometheus.CounterValue, float64(metrics.ScrapingRuns))
+    ch <- prometheus.MustNewConstMetric(c.scrapedPosts, prometheus.CounterValue, float64(metrics.TotalPosts))
+    ch <- prometheus.MustNewConstMetric(c.savedPosts, prometheus.CounterValue, float64(metrics.SuccessfulPosts))
+    ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, float64(metrics.FailedPosts))
+    ch <- prometheus.MustNewConstMetric(c.errorRate, prometheus.GaugeValue, metrics.ErrorRate)
+
+    postsPerRun := 0.0
+    if metrics.ScrapingRuns > 0 {
+        postsPerRun = float64(metrics.SuccessfulPosts) / float64(metrics.ScrapingRuns)
+    }
+    ch <- prometheus.MustNewConstMetric(c.postsPerRun, prometheus.GaugeValue, postsPerRun)
+
+    if !metrics.LastRun.IsZero() {
+        ch <- prometheus.MustNewConstMetric(c.lastRunAge, prometheus.GaugeValue, time.Since(metrics.LastRun).Seconds())
+    }
+
+    for groupID, gm := range metrics.GroupMetrics {
+        ch <- prometheus.MustNewConstMetric(c.groupScraped, prometheus.CounterValue, float64(gm.PostsScraped), groupID)
+        ch <- prometheus.MustNewConstMetric(c.groupErrors, prometheus.CounterValue, float64(gm.ErrorCount), groupID)
+    }
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+    "io"
+    "net/http/httptest"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+    "facebook-scraper/internal/monitoring"
+)
+
+func TestMetricsHandlerExposition(t *testing.T) {
+    metricsFile := filepath.Join(t.TempDir(), "metrics.json")
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    monitor := monitoring.NewMonitor(logger, metricsFile)
+    monitor.RecordScrapingRun("group-a", 10, time.Second, 2)
+    monitor.RecordScrapingRun("group-b", 5, time.Second, 0)
+
+    s := newTestServer(t)
+    s.SetMetricsFile(metricsFile)
+
+    rec := httptest.NewRecorder()
+    s.metricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+    if rec.Code != 200 {
+        t.Fatalf("status = %d, want 200", rec.Code)
+    }
+    if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+        t.Errorf("Content-Type = %q, want the text exposition format", ct)
+    }
+
+    body := rec.Body.String()
+    for _, want := range []string{
+        "# TYPE fbscraper_scrape_runs_total counter",
+        "fbscraper_scrape_runs_total 2",
+        "fbscraper_scraped_posts_total 15",
+        "fbscraper_saved_posts_total 13",
+        "fbscraper_scrape_errors_total 2",
+        "fbscraper_saved_posts_per_run 6.5",
+        `fbscraper_group_scraped_posts_total{group="group-a"} 10`,
+        `fbscraper_group_errors_total{group="group-b"} 0`,
+        "# TYPE fbscraper_last_run_age_seconds gauge",
+        "# TYPE go_goroutines gauge",
+        "# TYPE process_cpu_seconds_total counter",
+    } {
+        if !strings.Contains(body, want) {
+            t.Errorf("/metrics is missing %q", want)
+        }
+    }
+    if strings.Contains(body, "fbscraper_db_up") {
+        t.Error("/metrics reported the database without one configured")
+    }
+}
+
+func TestMetricsHandlerWithoutMetricsFile(t *testing.T) {
+    s := newTestServer(t)
+    s.SetMetricsFile(filepath.Join(t.TempDir(), "missing.json"))
+
+    rec := httptest.NewRecorder()
+    s.metricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+    if rec.Code != 200 {
+        t.Fatalf("status = %d, want 200", rec.Code)
+    }
+    if body := rec.Body.String(); strings.Contains(body, "fbscraper_") || !strings.Contains(body, "go_goroutines") {
+        t.Errorf("/metrics without scraper metrics should only report the runtime:\n%s", body)
+    }
+}
@@ -1,23 +1,88 @@
 package api
 
 import (
+    "context"
+    "encoding/csv"
     "encoding/json"
+    "errors"
     "fmt"
+    "html/template"
+    "io"
     "net/http"
+    "sort"
     "strconv"
+    "strings"
+    "sync"
     "time"
 
     "github.com/sirupsen/logrus"
     "facebook-scraper/internal/database"
     "facebook-scraper/internal/database/models"
+    "facebook-scraper/internal/scraper"
+    "facebook-scraper/web"
 )
 
+// Version is reported by the root and health endpoints
+const Version = "1.0.0"
+
 type Server struct {
-    db     *database.DB
-    logger *logrus.Logger
-    port   string
+    db          *database.DB
+    posts       postStore // db, replaced by a fake in tests
+    logger      *logrus.Logger
+    port        string
+    metricsFile string
+    dashboard   DashboardOptions
+    cors        CORSOptions
+    stats       StatsOptions
+    tls         TLSOptions
+    timeouts    TimeoutOptions
+    apiKey      string
+    scraper     *scraper.FacebookScraper
+    jobs        *jobStore
+    started     time.Time
+    ctx         context.Context // cancelled on Shutdown, stops on-demand scrapes
+    cancel      context.CancelFunc
+    mu          sync.Mutex
+    servers     []*http.Server
+}
+
+// postStore is the part of the database /api/posts reads from
+type postStore interface {
+    GetPostsWithPagination(ctx context.Context, q database.PostsQuery) ([]*models.Post, error)
+    GetPostsCount(ctx context.Context, q database.PostsQuery) (int, error)
+}
+
+// DashboardOptions are rendered into the dashboard page
+type DashboardOptions struct {
+    APIBase  string   // prefix of the API routes used by the page
+    MinLikes int      // min_likes passed to the posts and export requests
+    Groups   []string // names of the tracked groups shown in the header
+}
+
+// StatsOptions are the defaults of /api/stats, overridable per request with
+// the min_likes and days query parameters
+type StatsOptions struct {
+    MinLikes int // likes needed to count as high engagement
+    Days     int // window of the windowed figures
+}
+
+// CORSOptions controls the cross-origin headers sent with every API response
+type CORSOptions struct {
+    Origins []string // allowed origins; empty allows any origin with "*"
+    Methods []string
+    Headers []string
+}
+
+// TimeoutOptions harden the HTTP server against slow and hung clients
+type TimeoutOptions struct {
+    Read           time.Duration // whole request, including the body
+    Write          time.Duration // from the end of the request headers to the end of the response
+    Idle           time.Duration // keep-alive connections waiting for the next request
+    MaxHeaderBytes int
 }
 
+var dashboardTemplate = template.Must(template.ParseFS(web.Templates, "templates/dashboard.html"))
+
 type APIResponse struct {
     Success bool        `json:"success"`
     Data    interface{} `json:"data,omitempty"`
@@ -32,37 +97,199 @@ type PostsResponse struct {
     PageSize   int            `json:"page_size"`
 }
 
+// exportPost is a post with its media decoded so JSON exports and post
+// details carry structured images, videos and link previews instead of JSON
+// strings
+type exportPost struct {
+    *models.Post
+    Images       json.RawMessage `json:"images"`
+    Videos       json.RawMessage `json:"videos"`
+    LinkPreview  json.RawMessage `json:"link_preview,omitempty"`
+    MentionLinks json.RawMessage `json:"mention_links,omitempty"`
+}
+
 type StatsResponse struct {
-    TotalPosts       int     `json:"total_posts"`
-    HighEngagement   int     `json:"high_engagement_posts"`
-    AverageLikes     float64 `json:"average_likes"`
-    TopGroup         string  `json:"top_group"`
-    LastScrapedAt    string  `json:"last_scraped_at"`
-    GroupsScraped    int     `json:"groups_scraped"`
+    TotalPosts     int     `json:"total_posts"`
+    HighEngagement int     `json:"high_engagement_posts"`
+    AverageLikes   float64 `json:"average_likes"`
+    MedianLikes    float64 `json:"median_likes"`
+    P90Likes       float64 `json:"p90_likes"`
+    TopGroup       string  `json:"top_group"`
+    LastScrapedAt  string  `json:"last_scraped_at"`
+    GroupsScraped  int     `json:"groups_scraped"`
 }
 
 func NewServer(db *database.DB, logger *logrus.Logger, port string) *Server {
+    ctx, cancel := context.WithCancel(context.Background())
     return &Server{
-        db:     db,
-        logger: logger,
-        port:   port,
+        db:      db,
+        posts:   db,
+        logger:  logger,
+        port:    port,
+        jobs:    newJobStore(),
+        started: time.Now(),
+        ctx:     ctx,
+        cancel:  cancel,
+        dashboard: DashboardOptions{
+            APIBase:  "/api",
+            MinLikes: 1000,
+        },
+        stats: StatsOptions{
+            MinLikes: 1000,
+            Days:     5,
+        },
+        cors: CORSOptions{
+            Methods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+            Headers: []string{"Content-Type", "Authorization", "X-API-Key"},
+        },
+        timeouts: TimeoutOptions{
+            Read:           15 * time.Second,
+            Write:          60 * time.Second, // exports can take a while to stream
+            Idle:           120 * time.Second,
+            MaxHeaderBytes: 1 << 20,
+        },
+    }
+}
+
+// SetTimeoutOptions configures the HTTP server limits; zero fields keep the
+// defaults
+func (s *Server) SetTimeoutOptions(opts TimeoutOptions) {
+    if opts.Read > 0 {
+        s.timeouts.Read = opts.Read
+    }
+    if opts.Write > 0 {
+        s.timeouts.Write = opts.Write
+    }
+    if opts.Idle > 0 {
+        s.timeouts.Idle = opts.Idle
+    }
+    if opts.MaxHeaderBytes > 0 {
+        s.timeouts.MaxHeaderBytes = opts.MaxHeaderBytes
+    }
+}
+
+// httpServer wraps a handler in an http.Server with the configured limits,
+// and tracks it so Shutdown can stop it
+func (s *Server) httpServer(port string, handler http.Handler) *http.Server {
+    server := &http.Server{
+        Addr:              ":" + port,
+        Handler:           handler,
+        ReadTimeout:       s.timeouts.Read,
+        ReadHeaderTimeout: s.timeouts.Read,
+        WriteTimeout:      s.timeouts.Write,
+        IdleTimeout:       s.timeouts.Idle,
+        MaxHeaderBytes:    s.timeouts.MaxHeaderBytes,
+    }
+
+    s.mu.Lock()
+    s.servers = append(s.servers, server)
+    s.mu.Unlock()
+    return server
+}
+
+// Shutdown cancels running on-demand scrapes and gracefully stops the
+// listeners started by Start, waiting for open requests until ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+    s.cancel()
+
+    s.mu.Lock()
+    servers := s.servers
+    s.mu.Unlock()
+
+    var firstErr error
+    for _, server := range servers {
+        if err := server.Shutdown(ctx); err != nil && firstErr == nil {
+            firstErr = err
+        }
+    }
+    return firstErr
+}
+
+// SetStatsOptions configures the /api/stats defaults; zero fields keep the
+// current values
+func (s *Server) SetStatsOptions(opts StatsOptions) {
+    if opts.MinLikes > 0 {
+        s.stats.MinLikes = opts.MinLikes
+    }
+    if opts.Days > 0 {
+        s.stats.Days = opts.Days
+    }
+}
+
+// SetCORSOptions restricts cross-origin access; empty method and header
+// lists keep the defaults
+func (s *Server) SetCORSOptions(opts CORSOptions) {
+    s.cors.Origins = opts.Origins
+    if len(opts.Methods) > 0 {
+        s.cors.Methods = opts.Methods
+    }
+    if len(opts.Headers) > 0 {
+        s.cors.Headers = opts.Headers
     }
 }
 
+// SetDashboardOptions configures the server-side values of the dashboard;
+// empty fields keep the defaults
+func (s *Server) SetDashboardOptions(opts DashboardOptions) {
+    if opts.APIBase != "" {
+        s.dashboard.APIBase = opts.APIBase
+    }
+    if opts.MinLikes > 0 {
+        s.dashboard.MinLikes = opts.MinLikes
+    }
+    s.dashboard.Groups = opts.Groups
+}
+
+// SetMetricsFile points /metrics at the scraper's metrics file
+func (s *Server) SetMetricsFile(metricsFile string) {
+    s.metricsFile = metricsFile
+}
+
 func (s *Server) Start() error {
     s.setupRoutes()
-    s.logger.Infof("Starting API server on port %s", s.port)
-    return http.ListenAndServe(":"+s.port, nil)
+    handler := gzipHandler(http.DefaultServeMux)
+
+    server := s.httpServer(s.port, handler)
+
+    if !s.tlsEnabled() {
+        s.logger.Infof("Starting API server on port %s", s.port)
+        return server.ListenAndServe()
+    }
+
+    if s.tls.RedirectPort != "" {
+        go func() {
+            s.logger.Infof("Redirecting HTTP on port %s to HTTPS", s.tls.RedirectPort)
+            redirect := s.httpServer(s.tls.RedirectPort, httpsRedirectHandler(s.port))
+            if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+                s.logger.Errorf("HTTP redirect listener stopped: %v", err)
+            }
+        }()
+    }
+
+    s.logger.Infof("Starting API server with TLS on port %s", s.port)
+    return server.ListenAndServeTLS(s.tls.CertFile, s.tls.KeyFile)
 }
 
 func (s *Server) setupRoutes() {
     // Enable CORS
     http.HandleFunc("/", s.corsMiddleware(s.handleRoot))
     http.HandleFunc("/api/posts", s.corsMiddleware(s.handlePosts))
+    http.HandleFunc("/api/posts/", s.corsMiddleware(s.handlePostDetail))
     http.HandleFunc("/api/posts/group/", s.corsMiddleware(s.handlePostsByGroup))
+    http.HandleFunc("/api/search", s.corsMiddleware(s.handleSearch))
     http.HandleFunc("/api/stats", s.corsMiddleware(s.handleStats))
+    http.HandleFunc("/api/stats/groups", s.corsMiddleware(s.handleGroupStats))
+    http.HandleFunc("/api/groups", s.corsMiddleware(s.handleGroups))
+    http.HandleFunc("/api/authors/top", s.corsMiddleware(s.handleTopAuthors))
+    http.HandleFunc("/api/trends", s.corsMiddleware(s.handleTrends))
+    http.HandleFunc("/api/history", s.corsMiddleware(s.handleHistory))
+    http.HandleFunc("/api/errors", s.corsMiddleware(s.handleErrors))
     http.HandleFunc("/api/export/csv", s.corsMiddleware(s.handleExportCSV))
+    http.HandleFunc("/api/export/json", s.corsMiddleware(s.handleExportJSON))
     http.HandleFunc("/api/health", s.corsMiddleware(s.handleHealth))
+    http.HandleFunc("/api/scrape", s.corsMiddleware(s.requireAPIKey(s.handleStartScrape)))
+    http.HandleFunc("/api/scrape/", s.corsMiddleware(s.requireAPIKey(s.handleScrapeStatus)))
+    http.Handle("/metrics", s.metricsHandler())
     
     // Serve static files for web dashboard
     http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static/"))))
@@ -71,9 +298,15 @@ func (s *Server) setupRoutes() {
 
 func (s *Server) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
-        w.Header().Set("Access-Control-Allow-Origin", "*")
-        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+        if len(s.cors.Origins) > 0 {
+            w.Header().Add("Vary", "Origin")
+        }
+        if origin := s.allowedOrigin(r.Header.Get("Origin")); origin != "" {
+            w.Header().Set("Access-Control-Allow-Origin", origin)
+        }
+        w.Header().Set("Access-Control-Allow-Methods", strings.Join(s.cors.Methods, ", "))
+        w.Header().Set("Access-Control-Allow-Headers", strings.Join(s.cors.Headers, ", "))
+        w.Header().Set("Access-Control-Expose-Headers", "Link")
         
         if r.Method == "OPTIONS" {
             w.WriteHeader(http.StatusOK)
@@ -84,18 +317,37 @@ func (s *Server) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
     }
 }
 
+// allowedOrigin returns the Access-Control-Allow-Origin value for a request:
+// "*" without an allowlist, the request's own origin when it is listed, and
+// nothing otherwise so browsers block the response
+func (s *Server) allowedOrigin(origin string) string {
+    if len(s.cors.Origins) == 0 {
+        return "*"
+    }
+    for _, allowed := range s.cors.Origins {
+        if allowed == origin && origin != "" {
+            return origin
+        }
+    }
+    return ""
+}
+
 func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
     response := APIResponse{
         Success: true,
         Data: map[string]string{
             "message": "Facebook Scraper API",
-            "version": "1.0.0",
-            "endpoints": "/api/posts, /api/stats, /api/export/csv, /dashboard",
+            "version": Version,
+            "endpoints": "/api/posts, /api/search, /api/stats, /api/stats/groups, /api/groups, /api/errors, /api/authors/top, /api/trends, /api/export/csv, /api/export/json, /dashboard",
         },
     }
     s.writeJSON(w, response)
 }
 
+// defaultWithinDays is the window of recent scrapes /api/posts returns when
+// neither within_days nor a date range is given
+const defaultWithinDays = 5
+
 func (s *Server) handlePosts(w http.ResponseWriter, r *http.Request) {
     // Parse query parameters
     page, _ := strconv.Atoi(r.URL.Query().Get("page"))
@@ -110,16 +362,73 @@ func (s *Server) handlePosts(w http.ResponseWriter, r *http.Request) {
     
     minLikes, _ := strconv.Atoi(r.URL.Query().Get("min_likes"))
     if minLikes < 1 {
-        minLikes = 1000
+        minLikes = s.stats.MinLikes
+    }
+
+    sort := r.URL.Query().Get("sort")
+    order := r.URL.Query().Get("order")
+    if !database.ValidSort(sort, order) {
+        s.writeError(w, "Invalid sort or order (sort: likes, comments, shares, timestamp, scraped_at; order: asc, desc)", http.StatusBadRequest)
+        return
+    }
+
+    from, err := parseDateParam(r.URL.Query().Get("from"), false)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Invalid from date: %v", err), http.StatusBadRequest)
+        return
+    }
+    to, err := parseDateParam(r.URL.Query().Get("to"), true)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Invalid to date: %v", err), http.StatusBadRequest)
+        return
+    }
+    if !from.IsZero() && !to.IsZero() && from.After(to) {
+        s.writeError(w, "from must be before to", http.StatusBadRequest)
+        return
+    }
+
+    postsQuery := database.PostsQuery{
+        Page:     page,
+        PageSize: pageSize,
+        MinLikes: minLikes,
+        Sort:     sort,
+        Order:    order,
+        From:     from,
+        To:       to,
+    }
+    if postTypes := r.URL.Query().Get("post_type"); postTypes != "" {
+        for _, postType := range strings.Split(postTypes, ",") {
+            if postType = strings.ToLower(strings.TrimSpace(postType)); postType != "" {
+                postsQuery.PostTypes = append(postsQuery.PostTypes, postType)
+            }
+        }
+    }
+    if dedupe := r.URL.Query().Get("dedupe"); dedupe != "" {
+        postsQuery.Dedupe, err = strconv.ParseBool(dedupe)
+        if err != nil {
+            s.writeError(w, "Invalid dedupe (want true or false)", http.StatusBadRequest)
+            return
+        }
+    }
+    // within_days limits results to recent scrapes, 0 for no limit. Without it
+    // or an explicit date range keep the default window.
+    if withinDays := r.URL.Query().Get("within_days"); withinDays != "" {
+        postsQuery.RecentDays, err = strconv.Atoi(withinDays)
+        if err != nil || postsQuery.RecentDays < 0 {
+            s.writeError(w, "Invalid within_days (want a number of days, 0 for no limit)", http.StatusBadRequest)
+            return
+        }
+    } else if from.IsZero() && to.IsZero() {
+        postsQuery.RecentDays = defaultWithinDays
     }
 
-    posts, err := s.db.GetPostsWithPagination(page, pageSize, minLikes)
+    posts, err := s.posts.GetPostsWithPagination(r.Context(), postsQuery)
     if err != nil {
         s.writeError(w, fmt.Sprintf("Failed to fetch posts: %v", err), http.StatusInternalServerError)
         return
     }
 
-    totalCount, err := s.db.GetPostsCount(minLikes)
+    totalCount, err := s.posts.GetPostsCount(r.Context(), postsQuery)
     if err != nil {
         s.writeError(w, fmt.Sprintf("Failed to get total count: %v", err), http.StatusInternalServerError)
         return
@@ -136,6 +445,109 @@ func (s *Server) handlePosts(w http.ResponseWriter, r *http.Request) {
         Count: len(posts),
     }
 
+    setPaginationLinks(w, r, page, pageSize, totalCount)
+    s.writeJSON(w, response)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+
+    search := database.PostSearch{
+        Query:   strings.TrimSpace(query.Get("q")),
+        Author:  strings.TrimSpace(query.Get("author")),
+        Hashtag: strings.TrimSpace(query.Get("hashtag")),
+        Group:   strings.TrimSpace(query.Get("group")),
+    }
+    if search.Query == "" && search.Author == "" && search.Hashtag == "" && search.Group == "" {
+        s.writeError(w, "At least one of q, author, hashtag or group is required", http.StatusBadRequest)
+        return
+    }
+
+    search.Page, _ = strconv.Atoi(query.Get("page"))
+    if search.Page < 1 {
+        search.Page = 1
+    }
+
+    search.PageSize, _ = strconv.Atoi(query.Get("page_size"))
+    if search.PageSize < 1 || search.PageSize > 100 {
+        search.PageSize = 20
+    }
+
+    posts, totalCount, err := s.db.SearchPosts(r.Context(), search)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to search posts: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    response := APIResponse{
+        Success: true,
+        Data: PostsResponse{
+            Posts:      posts,
+            TotalCount: totalCount,
+            Page:       search.Page,
+            PageSize:   search.PageSize,
+        },
+        Count: len(posts),
+    }
+
+    setPaginationLinks(w, r, search.Page, search.PageSize, totalCount)
+    s.writeJSON(w, response)
+}
+
+func (s *Server) handlePostDetail(w http.ResponseWriter, r *http.Request) {
+    postID := strings.Trim(r.URL.Path[len("/api/posts/"):], "/")
+    if postID == "" {
+        s.writeError(w, "Post ID is required", http.StatusBadRequest)
+        return
+    }
+    if strings.HasSuffix(postID, "/history") {
+        s.handlePostHistory(w, r, strings.TrimSuffix(postID, "/history"))
+        return
+    }
+
+    post, err := s.db.GetPostByPostID(r.Context(), postID)
+    if errors.Is(err, database.ErrPostNotFound) {
+        s.writeError(w, fmt.Sprintf("Post %s not found", postID), http.StatusNotFound)
+        return
+    }
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to fetch post: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    response := APIResponse{
+        Success: true,
+        Data: exportPost{
+            Post:   post,
+            Images:       rawJSONArray(post.Images),
+            Videos:       rawJSONArray(post.Videos),
+            LinkPreview:  rawJSONObject(post.LinkPreview),
+            MentionLinks: rawJSONObject(post.MentionLinks),
+        },
+    }
+
+    s.writeJSON(w, response)
+}
+
+// handlePostHistory serves /api/posts/{post_id}/history, the engagement of a
+// post at each scrape for charting its growth
+func (s *Server) handlePostHistory(w http.ResponseWriter, r *http.Request, postID string) {
+    history, err := s.db.GetEngagementHistory(r.Context(), postID)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to fetch engagement history: %v", err), http.StatusInternalServerError)
+        return
+    }
+    if len(history) == 0 {
+        s.writeError(w, fmt.Sprintf("No engagement history for post %s", postID), http.StatusNotFound)
+        return
+    }
+
+    response := APIResponse{
+        Success: true,
+        Data:    history,
+        Count:   len(history),
+    }
+
     s.writeJSON(w, response)
 }
 
@@ -146,28 +558,65 @@ func (s *Server) handlePostsByGroup(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-    if limit < 1 || limit > 100 {
-        limit = 50
+    page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+    if page < 1 {
+        page = 1
+    }
+
+    // limit is the older name of page_size
+    pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+    if pageSize == 0 {
+        pageSize, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+    }
+    if pageSize < 1 || pageSize > 100 {
+        pageSize = 50
     }
 
-    posts, err := s.db.GetPostsByGroup(groupID, limit)
+    posts, err := s.db.GetPostsByGroup(r.Context(), groupID, pageSize, (page-1)*pageSize)
     if err != nil {
         s.writeError(w, fmt.Sprintf("Failed to fetch posts for group: %v", err), http.StatusInternalServerError)
         return
     }
 
+    totalCount, err := s.db.GetPostsByGroupCount(r.Context(), groupID)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to get total count: %v", err), http.StatusInternalServerError)
+        return
+    }
+
     response := APIResponse{
         Success: true,
-        Data:    posts,
-        Count:   len(posts),
+        Data: PostsResponse{
+            Posts:      posts,
+            TotalCount: totalCount,
+            Page:       page,
+            PageSize:   pageSize,
+        },
+        Count: len(posts),
     }
 
+    setPaginationLinks(w, r, page, pageSize, totalCount)
     s.writeJSON(w, response)
 }
 
+// statsWindow reads the min_likes and days parameters of the stats, top
+// authors and export endpoints, falling back to the configured defaults
+func (s *Server) statsWindow(r *http.Request) (int, int) {
+    minLikes, _ := strconv.Atoi(r.URL.Query().Get("min_likes"))
+    if minLikes < 1 {
+        minLikes = s.stats.MinLikes
+    }
+    days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+    if days < 1 {
+        days = s.stats.Days
+    }
+    return minLikes, days
+}
+
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
-    stats, err := s.db.GetScrapingStats()
+    minLikes, days := s.statsWindow(r)
+
+    stats, err := s.db.GetScrapingStats(r.Context(), minLikes, days)
     if err != nil {
         s.writeError(w, fmt.Sprintf("Failed to fetch stats: %v", err), http.StatusInternalServerError)
         return
@@ -181,200 +630,442 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
     s.writeJSON(w, response)
 }
 
-func (s *Server) handleExportCSV(w http.ResponseWriter, r *http.Request) {
-    minLikes, _ := strconv.Atoi(r.URL.Query().Get("min_likes"))
-    if minLikes < 1 {
-        minLikes = 1000
+// handleGroupStats compares the groups' post counts and engagement
+func (s *Server) handleGroupStats(w http.ResponseWriter, r *http.Request) {
+    minLikes, days := s.statsWindow(r)
+
+    groups, err := s.db.GetStatsByGroup(r.Context(), minLikes, days)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to fetch group stats: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    response := APIResponse{
+        Success: true,
+        Data:    groups,
+        Count:   len(groups),
     }
 
-    posts, err := s.db.GetPostsForExport(minLikes)
+    s.writeJSON(w, response)
+}
+
+// handleGroups lists the groups with stored posts, e.g. for a group filter
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request) {
+    groups, err := s.db.GetGroups(r.Context())
     if err != nil {
-        s.writeError(w, fmt.Sprintf("Failed to fetch posts for export: %v", err), http.StatusInternalServerError)
+        s.writeError(w, fmt.Sprintf("Failed to fetch groups: %v", err), http.StatusInternalServerError)
         return
     }
 
-    w.Header().Set("Content-Type", "text/csv")
-    w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=facebook_posts_%s.csv", time.Now().Format("2006-01-02")))
+    response := APIResponse{
+        Success: true,
+        Data:    groups,
+        Count:   len(groups),
+    }
 
-    // Write CSV header
-    w.Write([]byte("Group Name,Author,Content,Likes,Comments,Shares,Post Type,Timestamp,URL\n"))
+    s.writeJSON(w, response)
+}
 
-    // Write CSV data
-    for _, post := range posts {
-        content := fmt.Sprintf("\"%s\"", strings.ReplaceAll(post.Content, "\"", "\"\""))
-        line := fmt.Sprintf("%s,%s,%s,%d,%d,%d,%s,%s,%s\n",
-            post.GroupName,
-            post.AuthorName,
-            content,
-            post.Likes,
-            post.Comments,
-            post.Shares,
-            post.PostType,
-            post.Timestamp.Format("2006-01-02 15:04:05"),
-            post.PostURL,
-        )
-        w.Write([]byte(line))
+func (s *Server) handleTopAuthors(w http.ResponseWriter, r *http.Request) {
+    limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+    if limit < 1 {
+        limit = 10
+    }
+    if limit > 100 {
+        limit = 100
     }
+
+    minLikes, days := s.statsWindow(r)
+    authors, err := s.db.GetTopAuthors(r.Context(), minLikes, days, limit)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to fetch top authors: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    response := APIResponse{
+        Success: true,
+        Data:    authors,
+        Count:   len(authors),
+    }
+
+    s.writeJSON(w, response)
 }
 
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-    // Check database connection
-    if err := s.db.Ping(); err != nil {
-        s.writeError(w, "Database connection failed", http.StatusServiceUnavailable)
+// handleErrors lists recent failed scrape attempts, optionally of one group
+func (s *Server) handleErrors(w http.ResponseWriter, r *http.Request) {
+    limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+    if limit < 1 {
+        limit = 50
+    }
+    if limit > 500 {
+        limit = 500
+    }
+
+    scrapeErrors, err := s.db.GetRecentErrors(r.Context(), r.URL.Query().Get("group"), limit)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to fetch scrape errors: %v", err), http.StatusInternalServerError)
         return
     }
 
     response := APIResponse{
         Success: true,
-        Data: map[string]interface{}{
-            "status":    "healthy",
-            "timestamp": time.Now().Format(time.RFC3339),
-            "database":  "connected",
-        },
+        Data:    scrapeErrors,
+        Count:   len(scrapeErrors),
     }
 
     s.writeJSON(w, response)
 }
 
-func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
-    html := `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Facebook Scraper Dashboard</title>
-    <style>
-        * { margin: 0; padding: 0; box-sizing: border-box; }
-        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background: #f5f5f5; }
-        .container { max-width: 1200px; margin: 0 auto; padding: 20px; }
-        .header { background: white; padding: 20px; border-radius: 8px; margin-bottom: 20px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
-        .stats-grid { display: grid; grid-template-columns: repeat(auto-fit, minmax(250px, 1fr)); gap: 20px; margin-bottom: 20px; }
-        .stat-card { background: white; padding: 20px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
-        .stat-number { font-size: 2em; font-weight: bold; color: #1877f2; }
-        .stat-label { color: #666; margin-top: 5px; }
-        .posts-section { background: white; padding: 20px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
-        .post-item { border-bottom: 1px solid #eee; padding: 15px 0; }
-        .post-author { font-weight: bold; color: #1877f2; }
-        .post-content { margin: 10px 0; color: #333; }
-        .post-stats { display: flex; gap: 20px; color: #666; font-size: 0.9em; }
-        .loading { text-align: center; padding: 40px; color: #666; }
-        .error { background: #fee; color: #c33; padding: 15px; border-radius: 4px; margin: 10px 0; }
-        .controls { margin-bottom: 20px; }
-        .btn { background: #1877f2; color: white; border: none; padding: 10px 20px; border-radius: 4px; cursor: pointer; }
-        .btn:hover { background: #166fe5; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>Facebook Scraper Dashboard</h1>
-            <p>Monitor your Facebook scraping results and analytics</p>
-        </div>
-
-        <div class="stats-grid" id="stats-grid">
-            <div class="loading">Loading statistics...</div>
-        </div>
-
-        <div class="controls">
-            <button class="btn" onclick="refreshData()">Refresh Data</button>
-            <button class="btn" onclick="exportCSV()">Export CSV</button>
-        </div>
-
-        <div class="posts-section">
-            <h2>Recent High-Engagement Posts</h2>
-            <div id="posts-container">
-                <div class="loading">Loading posts...</div>
-            </div>
-        </div>
-    </div>
-
-    <script>
-        async function loadStats() {
-            try {
-                const response = await fetch('/api/stats');
-                const data = await response.json();
-                
-                if (data.success) {
-                    const statsGrid = document.getElementById('stats-grid');
-                    statsGrid.innerHTML = ` + "`" + `
-                        <div class="stat-card">
-                            <div class="stat-number">${data.data.total_posts || 0}</div>
-                            <div class="stat-label">Total Posts</div>
-                        </div>
-                        <div class="stat-card">
-                            <div class="stat-number">${data.data.high_engagement_posts || 0}</div>
-                            <div class="stat-label">High Engagement Posts</div>
-                        </div>
-                        <div class="stat-card">
-                            <div class="stat-number">${Math.round(data.data.average_likes || 0)}</div>
-                            <div class="stat-label">Average Likes</div>
-                        </div>
-                        <div class="stat-card">
-                            <div class="stat-number">${data.data.groups_scraped || 0}</div>
-                            <div class="stat-label">Groups Scraped</div>
-                        </div>
-                    ` + "`" + `;
-                } else {
-                    throw new Error(data.error);
-                }
-            } catch (error) {
-                document.getElementById('stats-grid').innerHTML = ` + "`" + `<div class="error">Failed to load statistics: ${error.message}</div>` + "`" + `;
+func (s *Server) handleTrends(w http.ResponseWriter, r *http.Request) {
+    trends, err := s.db.GetEngagementTrends(r.Context())
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to fetch engagement trends: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    response := APIResponse{
+        Success: true,
+        Data:    trends,
+        Count:   len(trends),
+    }
+
+    s.writeJSON(w, response)
+}
+
+func (s *Server) handleExportCSV(w http.ResponseWriter, r *http.Request) {
+    minLikes, days := s.statsWindow(r)
+
+    fields := defaultCSVFields
+    if param := r.URL.Query().Get("fields"); param != "" {
+        var err error
+        fields, err = parseCSVFields(param)
+        if err != nil {
+            s.writeError(w, fmt.Sprintf("Invalid fields: %v", err), http.StatusBadRequest)
+            return
+        }
+    }
+
+    posts, err := s.db.ExportPosts(r.Context(), minLikes, days)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to fetch posts for export: %v", err), http.StatusInternalServerError)
+        return
+    }
+    defer posts.Close()
+
+    w.Header().Set("Content-Type", "text/csv")
+    w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=facebook_posts_%s.csv", time.Now().Format("2006-01-02")))
+
+    if err := writePostsCSV(w, posts, fields); err != nil {
+        s.logger.Errorf("CSV export aborted: %v", err)
+    }
+}
+
+// csvColumn is a column the CSV export can include
+type csvColumn struct {
+    header string
+    value  func(*models.Post) string
+}
+
+// csvColumns whitelists the fields selectable with ?fields=; list fields are
+// joined with "; "
+var csvColumns = map[string]csvColumn{
+    "group":        {"Group Name", func(p *models.Post) string { return p.GroupName }},
+    "group_id":     {"Group ID", func(p *models.Post) string { return p.GroupID }},
+    "post_id":      {"Post ID", func(p *models.Post) string { return p.PostID }},
+    "author":       {"Author", func(p *models.Post) string { return p.AuthorName }},
+    "author_id":    {"Author ID", func(p *models.Post) string { return p.AuthorID }},
+    "content":      {"Content", func(p *models.Post) string { return p.Content }},
+    "likes":        {"Likes", func(p *models.Post) string { return strconv.Itoa(p.Likes) }},
+    "comments":     {"Comments", func(p *models.Post) string { return strconv.Itoa(p.Comments) }},
+    "shares":       {"Shares", func(p *models.Post) string { return strconv.Itoa(p.Shares) }},
+    "post_type":    {"Post Type", func(p *models.Post) string { return p.PostType }},
+    "media_count":  {"Media Count", func(p *models.Post) string { return strconv.Itoa(p.MediaCount) }},
+    "language":     {"Language", func(p *models.Post) string { return p.Language }},
+    "is_sponsored": {"Sponsored", func(p *models.Post) string { return strconv.FormatBool(p.IsSponsored) }},
+    "hashtags":     {"Hashtags", func(p *models.Post) string { return strings.Join(p.Hashtags, "; ") }},
+    "mentions":     {"Mentions", func(p *models.Post) string { return strings.Join(p.Mentions, "; ") }},
+    "links":        {"Links", func(p *models.Post) string { return strings.Join(p.Links, "; ") }},
+    "timestamp":    {"Timestamp", func(p *models.Post) string { return p.Timestamp.Format("2006-01-02 15:04:05") }},
+    "scraped_at":   {"Scraped At", func(p *models.Post) string { return p.ScrapedAt.Format("2006-01-02 15:04:05") }},
+    "url":          {"URL", func(p *models.Post) string { return p.PostURL }},
+}
+
+// defaultCSVFields is the column layout used when ?fields= is absent
+var defaultCSVFields = []string{"group", "author", "content", "likes", "comments", "shares", "post_type", "timestamp", "url"}
+
+// parseCSVFields reads a comma-separated field list, keeping its order and
+// rejecting fields that aren't in csvColumns
+func parseCSVFields(param string) ([]string, error) {
+    var fields []string
+    for _, field := range strings.Split(param, ",") {
+        field = strings.ToLower(strings.TrimSpace(field))
+        if field == "" {
+            continue
+        }
+        if _, ok := csvColumns[field]; !ok {
+            names := make([]string, 0, len(csvColumns))
+            for name := range csvColumns {
+                names = append(names, name)
             }
+            sort.Strings(names)
+            return nil, fmt.Errorf("unknown field %q (available: %s)", field, strings.Join(names, ", "))
         }
+        fields = append(fields, field)
+    }
+    if len(fields) == 0 {
+        return nil, fmt.Errorf("fields must name at least one column")
+    }
+    return fields, nil
+}
+
+// postIterator is what exports read posts from, one at a time; the
+// database's PostRows in production
+type postIterator interface {
+    Next() bool
+    Post() (*models.Post, error)
+    Err() error
+}
+
+// exportFlushEvery is how many posts an export writes between flushes to the
+// client
+const exportFlushEvery = 100
 
-        async function loadPosts() {
-            try {
-                const response = await fetch('/api/posts?page_size=10');
-                const data = await response.json();
-                
-                if (data.success) {
-                    const container = document.getElementById('posts-container');
-                    if (data.data.posts.length === 0) {
-                        container.innerHTML = '<p>No posts found. Try running the scraper first.</p>';
-                        return;
-                    }
-                    
-                    container.innerHTML = data.data.posts.map(post => ` + "`" + `
-                        <div class="post-item">
-                            <div class="post-author">${post.author_name} • ${post.group_name}</div>
-                            <div class="post-content">${post.content.substring(0, 200)}${post.content.length > 200 ? '...' : ''}</div>
-                            <div class="post-stats">
-                                <span>👍 ${post.likes}</span>
-                                <span>💬 ${post.comments}</span>
-                                <span>🔄 ${post.shares}</span>
-                                <span>📅 ${new Date(post.timestamp).toLocaleDateString()}</span>
-                            </div>
-                        </div>
-                    ` + "`" + `).join('');
-                } else {
-                    throw new Error(data.error);
-                }
-            } catch (error) {
-                document.getElementById('posts-container').innerHTML = ` + "`" + `<div class="error">Failed to load posts: ${error.message}</div>` + "`" + `;
+// flushResponse sends buffered output to the client when out supports it
+func flushResponse(out io.Writer) {
+    if flusher, ok := out.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+// writePostsCSV writes the given fields of the posts with encoding/csv so
+// commas, quotes and newlines in any field are escaped correctly
+func writePostsCSV(out io.Writer, posts postIterator, fields []string) error {
+    writer := csv.NewWriter(out)
+
+    header := make([]string, len(fields))
+    for i, field := range fields {
+        header[i] = csvColumns[field].header
+    }
+    if err := writer.Write(header); err != nil {
+        return err
+    }
+
+    for n := 1; posts.Next(); n++ {
+        post, err := posts.Post()
+        if err != nil {
+            return err
+        }
+        record := make([]string, len(fields))
+        for i, field := range fields {
+            record[i] = csvColumns[field].value(post)
+        }
+        if err := writer.Write(record); err != nil {
+            return err
+        }
+        if n%exportFlushEvery == 0 {
+            writer.Flush()
+            if err := writer.Error(); err != nil {
+                return err
             }
+            flushResponse(out)
         }
+    }
+    if err := posts.Err(); err != nil {
+        return err
+    }
+
+    writer.Flush()
+    return writer.Error()
+}
+
+func (s *Server) handleExportJSON(w http.ResponseWriter, r *http.Request) {
+    minLikes, days := s.statsWindow(r)
+    ndjson := r.URL.Query().Get("format") == "ndjson"
+
+    posts, err := s.db.ExportPosts(r.Context(), minLikes, days)
+    if err != nil {
+        s.writeError(w, fmt.Sprintf("Failed to fetch posts for export: %v", err), http.StatusInternalServerError)
+        return
+    }
+    defer posts.Close()
 
-        function refreshData() {
-            loadStats();
-            loadPosts();
+    extension := "json"
+    w.Header().Set("Content-Type", "application/json")
+    if ndjson {
+        extension = "ndjson"
+        w.Header().Set("Content-Type", "application/x-ndjson")
+    }
+    w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=facebook_posts_%s.%s", time.Now().Format("2006-01-02"), extension))
+
+    if err := writePostsJSON(w, posts, ndjson); err != nil {
+        s.logger.Errorf("JSON export aborted: %v", err)
+    }
+}
+
+// writePostsJSON encodes the posts one by one as a JSON array, or one object
+// per line for ndjson, so the export is streamed rather than built in memory
+func writePostsJSON(out io.Writer, posts postIterator, ndjson bool) error {
+    encoder := json.NewEncoder(out)
+    if !ndjson {
+        if _, err := io.WriteString(out, "["); err != nil {
+            return err
         }
+    }
+    for n := 1; posts.Next(); n++ {
+        post, err := posts.Post()
+        if err != nil {
+            return err
+        }
+        if n > 1 && !ndjson {
+            if _, err := io.WriteString(out, ","); err != nil {
+                return err
+            }
+        }
+        if err := encoder.Encode(exportPost{
+            Post:         post,
+            Images:       rawJSONArray(post.Images),
+            Videos:       rawJSONArray(post.Videos),
+            LinkPreview:  rawJSONObject(post.LinkPreview),
+            MentionLinks: rawJSONObject(post.MentionLinks),
+        }); err != nil {
+            return err
+        }
+        if n%exportFlushEvery == 0 {
+            flushResponse(out)
+        }
+    }
+    if err := posts.Err(); err != nil {
+        return err
+    }
+    if !ndjson {
+        _, err := io.WriteString(out, "]\n")
+        return err
+    }
+    return nil
+}
 
-        function exportCSV() {
-            window.open('/api/export/csv', '_blank');
+// rawJSONArray passes stored JSON through unchanged, substituting an empty
+// array for missing or malformed values
+func rawJSONArray(value string) json.RawMessage {
+    if value == "" || !json.Valid([]byte(value)) {
+        return json.RawMessage("[]")
+    }
+    return json.RawMessage(value)
+}
+
+// rawJSONObject passes a stored JSON value through, or nil to omit it
+func rawJSONObject(value string) json.RawMessage {
+    if value == "" || !json.Valid([]byte(value)) {
+        return nil
+    }
+    return json.RawMessage(value)
+}
+
+// handleHealth is a probe for monitoring systems. It answers 503 only when
+// the database is unreachable; a failing post count reports "degraded".
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+    health := map[string]interface{}{
+        "status":         "healthy",
+        "timestamp":      time.Now().Format(time.RFC3339),
+        "version":        Version,
+        "uptime_seconds": int64(time.Since(s.started).Seconds()),
+    }
+
+    start := time.Now()
+    err := s.db.Ping()
+    health["db_ping_ms"] = float64(time.Since(start).Microseconds()) / 1000
+    if err != nil {
+        health["status"] = "unhealthy"
+        health["database"] = "unreachable"
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusServiceUnavailable)
+        json.NewEncoder(w).Encode(APIResponse{
+            Success: false,
+            Data:    health,
+            Error:   "Database connection failed",
+        })
+        return
+    }
+    health["database"] = "connected"
+
+    total, lastScraped, err := s.db.GetPostTotals(r.Context())
+    if err != nil {
+        s.logger.Warnf("Health check could not count posts: %v", err)
+        health["status"] = "degraded"
+    } else {
+        health["total_posts"] = total
+        health["last_scraped_at"] = nil
+        if !lastScraped.IsZero() {
+            health["last_scraped_at"] = lastScraped.Format(time.RFC3339)
         }
+    }
+
+    response := APIResponse{
+        Success: true,
+        Data:    health,
+    }
+
+    s.writeJSON(w, response)
+}
 
-        // Load data on page load
-        document.addEventListener('DOMContentLoaded', function() {
-            loadStats();
-            loadPosts();
-        });
-    </script>
-</body>
-</html>
-    `
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
     w.Header().Set("Content-Type", "text/html")
-    w.Write([]byte(html))
+    if err := dashboardTemplate.Execute(w, s.dashboard); err != nil {
+        s.logger.Errorf("Failed to render dashboard: %v", err)
+    }
+}
+
+// parseDateParam accepts RFC3339 or YYYY-MM-DD. Plain dates used as an upper
+// bound cover the whole day.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+    if value == "" {
+        return time.Time{}, nil
+    }
+    if t, err := time.Parse(time.RFC3339, value); err == nil {
+        return t, nil
+    }
+
+    t, err := time.Parse("2006-01-02", value)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("%q is not RFC3339 or YYYY-MM-DD", value)
+    }
+    if endOfDay {
+        t = t.Add(24*time.Hour - time.Nanosecond)
+    }
+    return t, nil
+}
+
+// setPaginationLinks adds an RFC 5988 Link header with the first, prev, next
+// and last pages of a paginated response. The links keep the request's other
+// query parameters and are relative to the server.
+func setPaginationLinks(w http.ResponseWriter, r *http.Request, page, pageSize, totalCount int) {
+    lastPage := (totalCount + pageSize - 1) / pageSize
+    if lastPage < 1 {
+        lastPage = 1
+    }
+
+    pageURL := func(p int) string {
+        query := r.URL.Query()
+        query.Del("limit") // older name of page_size
+        query.Set("page", strconv.Itoa(p))
+        query.Set("page_size", strconv.Itoa(pageSize))
+        return r.URL.Path + "?" + query.Encode()
+    }
+
+    links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(1))}
+    if page > 1 {
+        prev := page - 1
+        if prev > lastPage {
+            prev = lastPage
+        }
+        links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(prev)))
+    }
+    if page < lastPage {
+        links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(page+1)))
+    }
+    links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(lastPage)))
+
+    w.Header().Set("Link", strings.Join(links, ", "))
 }
 
 func (s *Server) writeJSON(w http.ResponseWriter, data interface{}) {
@@ -0,0 +1,237 @@
+package api
+
+import (
+    "context"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+    "facebook-scraper/internal/database"
+    "facebook-scraper/internal/database/models"
+)
+
+func newTestServer(t *testing.T) *Server {
+    t.Helper()
+
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return NewServer(nil, logger, "0")
+}
+
+func TestStatsWindow(t *testing.T) {
+    s := newTestServer(t)
+    s.SetStatsOptions(StatsOptions{MinLikes: 500, Days: 30})
+
+    tests := []struct {
+        query        string
+        wantMinLikes int
+        wantDays     int
+    }{
+        {"", 500, 30},
+        {"?min_likes=2000", 2000, 30},
+        {"?days=7", 500, 7},
+        {"?min_likes=0&days=-1", 500, 30},
+        {"?min_likes=abc&days=xyz", 500, 30},
+    }
+
+    for _, tt := range tests {
+        r := httptest.NewRequest("GET", "/api/authors/top"+tt.query, nil)
+        minLikes, days := s.statsWindow(r)
+        if minLikes != tt.wantMinLikes || days != tt.wantDays {
+            t.Errorf("statsWindow(%q) = %d, %d, want %d, %d", tt.query, minLikes, days, tt.wantMinLikes, tt.wantDays)
+        }
+    }
+}
+
+func TestStatsWindowDefaults(t *testing.T) {
+    s := newTestServer(t)
+
+    minLikes, days := s.statsWindow(httptest.NewRequest("GET", "/api/stats", nil))
+    if minLikes != 1000 || days != 5 {
+        t.Errorf("statsWindow() = %d, %d, want 1000, 5 without configured options", minLikes, days)
+    }
+}
+
+// slicePosts is a postIterator over posts held in memory
+type slicePosts struct {
+    posts []*models.Post
+    next  int
+}
+
+func (sp *slicePosts) Next() bool {
+    sp.next++
+    return sp.next <= len(sp.posts)
+}
+
+func (sp *slicePosts) Post() (*models.Post, error) {
+    return sp.posts[sp.next-1], nil
+}
+
+func (sp *slicePosts) Err() error {
+    return nil
+}
+
+func TestWritePostsCSVQuoting(t *testing.T) {
+    posts := []*models.Post{
+        {
+            GroupName:  "Deals, Nairobi",
+            AuthorName: `Jane "JD" Doe`,
+            Content:    "Line one\nLine two, with a comma\n\"Quoted\" ending",
+            Likes:      1200,
+            Hashtags:   []string{"#deals", "#nairobi"},
+            Timestamp:  time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+        },
+        {GroupName: "Plain", AuthorName: "Sam", Content: "no special characters"},
+    }
+    fields := []string{"group", "author", "content", "likes", "hashtags", "timestamp"}
+
+    rec := httptest.NewRecorder()
+    if err := writePostsCSV(rec, &slicePosts{posts: posts}, fields); err != nil {
+        t.Fatalf("writePostsCSV: %v", err)
+    }
+
+    body := rec.Body.String()
+    for _, want := range []string{`"Deals, Nairobi"`, `"Jane ""JD"" Doe"`, "\"Line one\nLine two, with a comma\n\"\"Quoted\"\" ending\""} {
+        if !strings.Contains(body, want) {
+            t.Errorf("CSV is missing the escaped field %s:\n%s", want, body)
+        }
+    }
+
+    records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+    if err != nil {
+        t.Fatalf("reading the CSV back: %v", err)
+    }
+    want := [][]string{
+        {"Group Name", "Author", "Content", "Likes", "Hashtags", "Timestamp"},
+        {"Deals, Nairobi", `Jane "JD" Doe`, "Line one\nLine two, with a comma\n\"Quoted\" ending", "1200", "#deals; #nairobi", "2024-03-01 09:30:00"},
+        {"Plain", "Sam", "no special characters", "0", "", "0001-01-01 00:00:00"},
+    }
+    if !reflect.DeepEqual(records, want) {
+        t.Errorf("records = %q, want %q", records, want)
+    }
+}
+
+func TestWritePostsJSONStreams(t *testing.T) {
+    var posts []*models.Post
+    for i := 0; i < exportFlushEvery+1; i++ {
+        posts = append(posts, &models.Post{PostID: fmt.Sprint(i), Images: `[{"url": "a.jpg"}]`})
+    }
+
+    for _, ndjson := range []bool{false, true} {
+        rec := httptest.NewRecorder()
+        if err := writePostsJSON(rec, &slicePosts{posts: posts}, ndjson); err != nil {
+            t.Fatalf("writePostsJSON(ndjson=%v): %v", ndjson, err)
+        }
+        if !rec.Flushed {
+            t.Errorf("ndjson=%v: response was never flushed while writing %d posts", ndjson, len(posts))
+        }
+
+        var got []map[string]interface{}
+        if ndjson {
+            decoder := json.NewDecoder(rec.Body)
+            for decoder.More() {
+                var post map[string]interface{}
+                if err := decoder.Decode(&post); err != nil {
+                    t.Fatalf("decoding ndjson: %v", err)
+                }
+                got = append(got, post)
+            }
+        } else if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+            t.Fatalf("decoding the JSON array: %v\n%s", err, rec.Body.String())
+        }
+
+        if len(got) != len(posts) {
+            t.Fatalf("ndjson=%v: decoded %d posts, want %d", ndjson, len(got), len(posts))
+        }
+        if images, ok := got[0]["images"].([]interface{}); !ok || len(images) != 1 {
+            t.Errorf("ndjson=%v: images = %v, want the decoded array", ndjson, got[0]["images"])
+        }
+    }
+
+    rec := httptest.NewRecorder()
+    if err := writePostsJSON(rec, &slicePosts{}, false); err != nil || strings.TrimSpace(rec.Body.String()) != "[]" {
+        t.Errorf("writePostsJSON() with no posts = %q, %v, want an empty array", rec.Body.String(), err)
+    }
+}
+
+func TestParseCSVFields(t *testing.T) {
+    fields, err := parseCSVFields(" Likes, content ,,url")
+    if err != nil || !reflect.DeepEqual(fields, []string{"likes", "content", "url"}) {
+        t.Errorf("parseCSVFields() = %q, %v, want likes, content and url", fields, err)
+    }
+    if _, err := parseCSVFields("content,password"); err == nil || !strings.Contains(err.Error(), `"password"`) {
+        t.Errorf("parseCSVFields() error = %v, want one naming the unknown field", err)
+    }
+    if _, err := parseCSVFields(" , "); err == nil {
+        t.Error("parseCSVFields() accepted an empty field list")
+    }
+}
+
+// fakePostStore records the queries /api/posts makes
+type fakePostStore struct {
+    pageQueries  []database.PostsQuery
+    countQueries []database.PostsQuery
+}
+
+func (f *fakePostStore) GetPostsWithPagination(ctx context.Context, q database.PostsQuery) ([]*models.Post, error) {
+    f.pageQueries = append(f.pageQueries, q)
+    return []*models.Post{}, nil
+}
+
+func (f *fakePostStore) GetPostsCount(ctx context.Context, q database.PostsQuery) (int, error) {
+    f.countQueries = append(f.countQueries, q)
+    return 0, nil
+}
+
+func TestHandlePostsWithinDays(t *testing.T) {
+    tests := []struct {
+        name       string
+        query      string
+        wantStatus int
+        wantDays   int
+    }{
+        {"zero means no limit", "?within_days=0", 200, 0},
+        {"explicit window", "?within_days=30", 200, 30},
+        {"missing uses the default", "", 200, defaultWithinDays},
+        {"date range replaces the default", "?from=2024-01-01", 200, 0},
+        {"not a number", "?within_days=week", 400, 0},
+        {"negative", "?within_days=-1", 400, 0},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            store := &fakePostStore{}
+            s := newTestServer(t)
+            s.posts = store
+
+            rec := httptest.NewRecorder()
+            s.handlePosts(rec, httptest.NewRequest("GET", "/api/posts"+tt.query, nil))
+
+            if rec.Code != tt.wantStatus {
+                t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+            }
+            if tt.wantStatus != 200 {
+                if len(store.pageQueries)+len(store.countQueries) != 0 {
+                    t.Error("the database was queried for an invalid request")
+                }
+                return
+            }
+            if len(store.pageQueries) != 1 || len(store.countQueries) != 1 {
+                t.Fatalf("made %d page and %d count queries, want 1 each", len(store.pageQueries), len(store.countQueries))
+            }
+            if got := store.pageQueries[0].RecentDays; got != tt.wantDays {
+                t.Errorf("GetPostsWithPagination RecentDays = %d, want %d", got, tt.wantDays)
+            }
+            if got := store.countQueries[0].RecentDays; got != tt.wantDays {
+                t.Errorf("GetPostsCount RecentDays = %d, want %d", got, tt.wantDays)
+            }
+        })
+    }
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+    "net"
+    "net/http"
+)
+
+// TLSOptions make the server speak HTTPS
+type TLSOptions struct {
+    CertFile     string // PEM certificate, including any intermediates
+    KeyFile      string // PEM private key
+    RedirectPort string // optional plain HTTP port that redirects to HTTPS
+}
+
+// SetTLSOptions serves the API over HTTPS when both the certificate and key
+// are set
+func (s *Server) SetTLSOptions(opts TLSOptions) {
+    s.tls = opts
+}
+
+func (s *Server) tlsEnabled() bool {
+    return s.tls.CertFile != "" && s.tls.KeyFile != ""
+}
+
+// httpsRedirectHandler sends every request to the same host, path and query
+// on the HTTPS port
+func httpsRedirectHandler(httpsPort string) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        host := r.Host
+        if h, _, err := net.SplitHostPort(host); err == nil {
+            host = h
+        }
+        if httpsPort != "443" {
+            host = net.JoinHostPort(host, httpsPort)
+        }
+
+        target := "https://" + host + r.URL.RequestURI()
+        // 308 keeps the method and body, so POST /api/scrape still works
+        http.Redirect(w, r, target, http.StatusPermanentRedirect)
+    })
+}
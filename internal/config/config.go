@@ -4,16 +4,82 @@ import (
     "fmt"
     "io/ioutil"
     "os"
+    "regexp"
+    "time"
 
     "gopkg.in/yaml.v2"
     "github.com/joho/godotenv"
+    "facebook-scraper/pkg/types"
+)
+
+const (
+    DefaultMinLikes    = 1000
+    DefaultDaysBack    = 5
+    DefaultMetricsFile = "data/metrics.json"
+    DefaultRawHTMLDir  = "logs/raw"
+    DefaultLogFormat   = "text"
+
+    DefaultMaxOpenConns    = 25
+    DefaultMaxIdleConns    = 5
+    DefaultConnMaxLifetime = 5 // minutes
 )
 
 type Config struct {
-    Facebook FacebookConfig `yaml:"facebook"`
-    Scraper  ScraperConfig  `yaml:"scraper"`
-    Database DatabaseConfig `yaml:"database"`
-    Logging  LoggingConfig  `yaml:"logging"`
+    Facebook      FacebookConfig      `yaml:"facebook"`
+    Scraper       ScraperConfig       `yaml:"scraper"`
+    Filter        FilterConfig        `yaml:"filter"`
+    Search        SearchConfig        `yaml:"search"`
+    Selectors     SelectorsConfig     `yaml:"selectors"`
+    Database      DatabaseConfig      `yaml:"database"`
+    Logging       LoggingConfig       `yaml:"logging"`
+    Monitoring    MonitoringConfig    `yaml:"monitoring"`
+    Debug         DebugConfig         `yaml:"debug"`
+    API           APIConfig           `yaml:"api"`
+    Alerts        AlertsConfig        `yaml:"alerts"`
+    Notifications NotificationsConfig `yaml:"notifications"`
+}
+
+// NotificationsConfig sets up the webhook told about new high-engagement posts
+type NotificationsConfig struct {
+    WebhookURL string            `yaml:"webhook_url"` // empty disables notifications
+    Headers    map[string]string `yaml:"headers"`
+    MinLikes   int               `yaml:"min_likes"` // 0 notifies about every new post that passed the filter
+}
+
+type AlertsConfig struct {
+    Webhooks []WebhookConfig             `yaml:"webhooks"` // every alert batch is POSTed as JSON to each
+    Groups   map[string]GroupAlertConfig `yaml:"groups"`   // per-group thresholds keyed by group ID
+}
+
+type GroupAlertConfig struct {
+    MaxHoursSinceScrape int     `yaml:"max_hours_since_scrape"`
+    MaxErrorRate        float64 `yaml:"max_error_rate"` // percent
+    MaxEmptyRuns        int     `yaml:"max_empty_runs"`
+}
+
+type WebhookConfig struct {
+    URL     string            `yaml:"url"`
+    Headers map[string]string `yaml:"headers"`
+}
+
+type APIConfig struct {
+    APIKey      string    `yaml:"api_key"`      // required by control endpoints such as POST /api/scrape
+    CORSOrigins []string  `yaml:"cors_origins"` // empty allows any origin
+    CORSMethods []string  `yaml:"cors_methods"`
+    CORSHeaders []string  `yaml:"cors_headers"`
+    TLS         TLSConfig `yaml:"tls"`
+
+    // HTTP server limits in seconds; 0 keeps the server defaults
+    ReadTimeout    int `yaml:"read_timeout"`
+    WriteTimeout   int `yaml:"write_timeout"`
+    IdleTimeout    int `yaml:"idle_timeout"`
+    MaxHeaderBytes int `yaml:"max_header_bytes"`
+}
+
+type TLSConfig struct {
+    CertFile     string `yaml:"cert_file"`
+    KeyFile      string `yaml:"key_file"`
+    RedirectPort string `yaml:"redirect_port"` // plain HTTP port redirected to HTTPS; empty disables
 }
 
 type FacebookConfig struct {
@@ -25,21 +91,126 @@ type FacebookConfig struct {
 }
 
 type AuthConfig struct {
-    Method      string `yaml:"method"`
-    CookiesFile string `yaml:"cookies_file"`
-    UserAgent   string `yaml:"user_agent"`
+    Method          string   `yaml:"method"`
+    CookiesFile     string   `yaml:"cookies_file"`
+    CookiesFiles    []string `yaml:"cookies_files"`    // extra accounts to rotate through
+    AccountCooldown int      `yaml:"account_cooldown"` // minutes
+    UserAgent       string   `yaml:"user_agent"`
+    UserAgents      []string `yaml:"user_agents"`
+    ProxyURL        string   `yaml:"proxy_url"`
+    ProxyURLs       []string `yaml:"proxy_urls"`
+}
+
+// Proxies returns every configured proxy, single proxy_url first
+func (ac AuthConfig) Proxies() []string {
+    var proxies []string
+    if ac.ProxyURL != "" {
+        proxies = append(proxies, ac.ProxyURL)
+    }
+    return append(proxies, ac.ProxyURLs...)
 }
 
 type RateLimitConfig struct {
     RequestsPerMinute    int `yaml:"requests_per_minute"`
     DelayBetweenRequests int `yaml:"delay_between_requests"`
+    JitterPercent        int `yaml:"jitter_percent"` // randomizes delays by up to this much either way
 }
 
 type ScraperConfig struct {
-    ConcurrentWorkers int    `yaml:"concurrent_workers"`
-    RetryAttempts     int    `yaml:"retry_attempts"`
-    RetryDelay        int    `yaml:"retry_delay"`
-    OutputFormat      string `yaml:"output_format"`
+    ConcurrentWorkers int           `yaml:"concurrent_workers"`
+    RetryAttempts     int           `yaml:"retry_attempts"`
+    RetryDelay        int           `yaml:"retry_delay"`
+    OutputFormat      string        `yaml:"output_format"`
+    Backend           string        `yaml:"backend"`          // http, selenium or chromedp
+    FallbackBackend   string        `yaml:"fallback_backend"` // tried when backend finds nothing
+    Browser           BrowserConfig `yaml:"browser"`
+    ScrapeComments    bool          `yaml:"scrape_comments"` // also parse and store top-level comments
+    MaxPages          int           `yaml:"max_pages"`       // older-post pages followed per group (http)
+    Interval          string        `yaml:"interval"`        // e.g. "30m" or "6h" to scrape repeatedly; empty runs once
+    DetectLanguage    bool          `yaml:"detect_language"` // guess each post's language (extra CPU per post)
+}
+
+type BrowserConfig struct {
+    Headless    bool   `yaml:"headless"`
+    Browser     string `yaml:"browser"`     // selenium: firefox or chrome
+    DriverPath  string `yaml:"driver_path"` // selenium: geckodriver/chromedriver binary
+    DriverPort  int    `yaml:"driver_port"`
+    MaxScrolls  int    `yaml:"max_scrolls"`
+    WaitTimeout int    `yaml:"wait_timeout"` // seconds
+}
+
+// SelectorsConfig overrides the scraper's CSS selector lists; empty lists
+// keep the built-in defaults
+type SelectorsConfig struct {
+    Post    []string `yaml:"post"`
+    Author  []string `yaml:"author"`
+    Content []string `yaml:"content"`
+}
+
+type DebugConfig struct {
+    SaveHTML bool   `yaml:"save_html"` // dump every fetched page for selector debugging
+    RawDir   string `yaml:"raw_dir"`
+}
+
+type SearchConfig struct {
+    Queries []string `yaml:"queries"` // keywords searched across public posts
+}
+
+type FilterConfig struct {
+    MinLikes           int            `yaml:"min_likes"`
+    MaxLikes           int            `yaml:"max_likes"`
+    MinComments        int            `yaml:"min_comments"`
+    MinShares          int            `yaml:"min_shares"`
+    MinTotalEngagement int            `yaml:"min_total_engagement"` // likes + comments + shares
+    MinReactions       map[string]int `yaml:"min_reactions"`        // per reaction type, e.g. {angry: 500}
+    DaysBack           int            `yaml:"days_back"`
+    Keywords           []string       `yaml:"keywords"`
+    ExcludeKeywords    []string       `yaml:"exclude_keywords"`
+    KeywordMatch       string         `yaml:"keyword_match"` // any (default) or all
+    KeywordRegex       []string       `yaml:"keyword_regex"`
+    ExcludeRegex       []string       `yaml:"exclude_regex"`
+    AuthorNames        []string       `yaml:"author_names"`
+    Hashtags           []string       `yaml:"hashtags"`
+    Mentions           []string       `yaml:"mentions"`
+    GroupIDs           []string       `yaml:"group_ids"` // keep only posts from these groups
+    PageIDs            []string       `yaml:"page_ids"`  // keep only posts from these Pages
+    PostTypes          []string       `yaml:"post_types"`
+    MinMediaCount      int            `yaml:"min_media_count"`
+    RequireMedia       bool           `yaml:"require_media"`
+    RequireLink        bool           `yaml:"require_link"`
+    ExcludeSponsored   *bool          `yaml:"exclude_sponsored"` // true when unset
+    Languages          []string       `yaml:"languages"`         // needs scraper.detect_language
+    DedupeByContent    bool           `yaml:"dedupe_by_content"` // drop reshares of the same text
+}
+
+// PostFilter converts the filter configuration into a types.PostFilter
+func (fc FilterConfig) PostFilter() *types.PostFilter {
+    return &types.PostFilter{
+        MinLikes:           fc.MinLikes,
+        MaxLikes:           fc.MaxLikes,
+        MinComments:        fc.MinComments,
+        MinShares:          fc.MinShares,
+        MinTotalEngagement: fc.MinTotalEngagement,
+        MinReactions:       fc.MinReactions,
+        DaysBack:           fc.DaysBack,
+        Keywords:           fc.Keywords,
+        ExcludeKeywords:    fc.ExcludeKeywords,
+        KeywordMatchMode:   fc.KeywordMatch,
+        KeywordRegex:       fc.KeywordRegex,
+        ExcludeRegex:       fc.ExcludeRegex,
+        AuthorNames:        fc.AuthorNames,
+        Hashtags:           fc.Hashtags,
+        Mentions:           fc.Mentions,
+        GroupIDs:           fc.GroupIDs,
+        PageIDs:            fc.PageIDs,
+        PostTypes:          fc.PostTypes,
+        MinMediaCount:      fc.MinMediaCount,
+        RequireMedia:       fc.RequireMedia,
+        RequireLink:        fc.RequireLink,
+        ExcludeSponsored:   fc.ExcludeSponsored == nil || *fc.ExcludeSponsored,
+        Languages:          fc.Languages,
+        DedupeByContent:    fc.DedupeByContent,
+    }
 }
 
 type DatabaseConfig struct {
@@ -49,19 +220,67 @@ type DatabaseConfig struct {
     User     string `yaml:"user"`
     Password string `yaml:"password"`
     SSLMode  string `yaml:"ssl_mode"`
+
+    MaxOpenConns    int `yaml:"max_open_conns"`
+    MaxIdleConns    int `yaml:"max_idle_conns"`
+    ConnMaxLifetime int `yaml:"conn_max_lifetime"` // minutes
+    RetentionDays   int `yaml:"retention_days"`    // delete posts scraped longer ago; 0 keeps everything
 }
 
 type LoggingConfig struct {
     Level      string `yaml:"level"`
+    Format     string `yaml:"format"` // text (default) or json
     File       string `yaml:"file"`
     MaxSize    int    `yaml:"max_size"`
     MaxBackups int    `yaml:"max_backups"`
     MaxAge     int    `yaml:"max_age"`
 }
 
+type MonitoringConfig struct {
+    MetricsFile string `yaml:"metrics_file"`
+}
+
 type Group struct {
     ID   string `yaml:"id"`
     Name string `yaml:"name"`
+    Type string `yaml:"type"` // "group" (default) or "page"
+
+    // Optional overrides of the global filter for this group
+    MinLikes int      `yaml:"min_likes"`
+    DaysBack int      `yaml:"days_back"`
+    Keywords []string `yaml:"keywords"`
+}
+
+// HasFilterOverrides reports whether the group sets any of its own filters
+func (g Group) HasFilterOverrides() bool {
+    return g.MinLikes > 0 || g.DaysBack > 0 || len(g.Keywords) > 0
+}
+
+// PostFilter returns the global filter with the group's overrides applied
+func (g Group) PostFilter(global FilterConfig) *types.PostFilter {
+    if g.MinLikes > 0 {
+        global.MinLikes = g.MinLikes
+    }
+    if g.DaysBack > 0 {
+        global.DaysBack = g.DaysBack
+    }
+    if len(g.Keywords) > 0 {
+        global.Keywords = g.Keywords
+    }
+    return global.PostFilter()
+}
+
+// IsPage reports whether the entry is a Facebook Page rather than a group
+func (g Group) IsPage() bool {
+    return g.Type == "page"
+}
+
+// ScrapeInterval parses Interval, returning 0 when the scraper should run once
+func (sc ScraperConfig) ScrapeInterval() (time.Duration, error) {
+    if sc.Interval == "" {
+        return 0, nil
+    }
+    return time.ParseDuration(sc.Interval)
 }
 
 func Load(configFile string) (*Config, error) {
@@ -81,8 +300,15 @@ func Load(configFile string) (*Config, error) {
         return nil, fmt.Errorf("failed to read config file: %w", err)
     }
 
-    var config Config
-    if err := yaml.Unmarshal(data, &config); err != nil {
+    // Keys left out of the file keep these defaults, while an explicit 0
+    // turns the threshold off
+    config := Config{
+        Filter: FilterConfig{
+            MinLikes: DefaultMinLikes,
+            DaysBack: DefaultDaysBack,
+        },
+    }
+    if err := yaml.Unmarshal([]byte(expandEnv(string(data))), &config); err != nil {
         return nil, fmt.Errorf("failed to parse config file: %w", err)
     }
 
@@ -99,10 +325,48 @@ func Load(configFile string) (*Config, error) {
     if dbName := os.Getenv("DB_NAME"); dbName != "" {
         config.Database.Name = dbName
     }
+    if apiKey := os.Getenv("API_KEY"); apiKey != "" {
+        config.API.APIKey = apiKey
+    }
+
+    if config.Monitoring.MetricsFile == "" {
+        config.Monitoring.MetricsFile = DefaultMetricsFile
+    }
+    if config.Database.MaxOpenConns == 0 {
+        config.Database.MaxOpenConns = DefaultMaxOpenConns
+    }
+    if config.Database.MaxIdleConns == 0 {
+        config.Database.MaxIdleConns = DefaultMaxIdleConns
+    }
+    if config.Database.ConnMaxLifetime == 0 {
+        config.Database.ConnMaxLifetime = DefaultConnMaxLifetime
+    }
+    if config.Debug.RawDir == "" {
+        config.Debug.RawDir = DefaultRawHTMLDir
+    }
+    if config.Logging.Format == "" {
+        config.Logging.Format = DefaultLogFormat
+    }
+
+    if err := config.Validate(); err != nil {
+        return nil, err
+    }
 
     return &config, nil
 }
 
+// envReference matches ${VAR} references to environment variables
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} with environment variables so secrets can stay
+// out of the file. Unset variables become empty. Bare $VAR and other dollar
+// signs are left alone, so regex patterns such as "(\d+)$" or "$1" survive.
+func expandEnv(data string) string {
+    return envReference.ReplaceAllStringFunc(data, func(ref string) string {
+        return os.Getenv(envReference.FindStringSubmatch(ref)[1])
+    })
+}
+
 func LoadGroups(groupsFile string) ([]Group, error) {
     if _, err := os.Stat(groupsFile); os.IsNotExist(err) {
         return nil, fmt.Errorf("groups file not found: %s", groupsFile)
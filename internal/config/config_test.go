@@ -0,0 +1,176 @@
+package config
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+// baseYAML holds the settings Validate requires
+const baseYAML = `
+facebook:
+  timeout: 30
+  rate_limit:
+    delay_between_requests: 6
+  auth:
+    method: "cookies"
+    cookies_file: "configs/cookies.json"
+database:
+  host: "localhost"
+  port: 5432
+  name: "facebook_scraper"
+  user: "postgres"
+`
+
+// loadYAML writes baseYAML plus extra to a temporary config file and loads it
+func loadYAML(t *testing.T, extra string) *Config {
+    t.Helper()
+
+    path := filepath.Join(t.TempDir(), "config.yaml")
+    if err := os.WriteFile(path, []byte(baseYAML+extra), 0644); err != nil {
+        t.Fatal(err)
+    }
+    cfg, err := Load(path)
+    if err != nil {
+        t.Fatalf("Load: %v", err)
+    }
+    return cfg
+}
+
+func TestLoadFilterDefaults(t *testing.T) {
+    tests := []struct {
+        name         string
+        yaml         string
+        wantMinLikes int
+        wantDaysBack int
+    }{
+        {"unset", "", DefaultMinLikes, DefaultDaysBack},
+        {"empty filter section", "filter:\n  keywords: []\n", DefaultMinLikes, DefaultDaysBack},
+        {"explicit zeros", "filter:\n  min_likes: 0\n  days_back: 0\n", 0, 0},
+        {"explicit zero min_likes only", "filter:\n  min_likes: 0\n", 0, DefaultDaysBack},
+        {"custom values", "filter:\n  min_likes: 250\n  days_back: 30\n", 250, 30},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            cfg := loadYAML(t, tt.yaml)
+            if cfg.Filter.MinLikes != tt.wantMinLikes || cfg.Filter.DaysBack != tt.wantDaysBack {
+                t.Errorf("min_likes = %d, days_back = %d, want %d and %d",
+                    cfg.Filter.MinLikes, cfg.Filter.DaysBack, tt.wantMinLikes, tt.wantDaysBack)
+            }
+        })
+    }
+}
+
+func TestExpandEnv(t *testing.T) {
+    t.Setenv("FB_TEST_PASSWORD", "s3cret")
+    t.Setenv("FB_TEST_EMPTY", "")
+
+    tests := []struct {
+        in   string
+        want string
+    }{
+        {"password: ${FB_TEST_PASSWORD}", "password: s3cret"},
+        {"password: ${FB_TEST_UNSET_VARIABLE}", "password: "},
+        {"password: ${FB_TEST_EMPTY}", "password: "},
+        {`keyword_regex: ["(\\d+)$", "^\\$[0-9]+"]`, `keyword_regex: ["(\\d+)$", "^\\$[0-9]+"]`},
+        {"replace: $1 and $FB_TEST_PASSWORD", "replace: $1 and $FB_TEST_PASSWORD"},
+        {"price: $$5", "price: $$5"},
+        {"broken: ${not closed", "broken: ${not closed"},
+    }
+
+    for _, tt := range tests {
+        if got := expandEnv(tt.in); got != tt.want {
+            t.Errorf("expandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+        }
+    }
+}
+
+func TestLoadKeepsRegexDollarSigns(t *testing.T) {
+    t.Setenv("FB_TEST_DB_PASSWORD", "s3cret")
+
+    cfg := loadYAML(t, `
+filter:
+  keyword_regex: ["(\\d+)$", "KES\\s?\\$?\\d+"]
+`)
+    want := []string{`(\d+)$`, `KES\s?\$?\d+`}
+    if len(cfg.Filter.KeywordRegex) != 2 || cfg.Filter.KeywordRegex[0] != want[0] || cfg.Filter.KeywordRegex[1] != want[1] {
+        t.Errorf("keyword_regex = %q, want %q", cfg.Filter.KeywordRegex, want)
+    }
+
+    cfg = loadYAML(t, "  password: ${FB_TEST_DB_PASSWORD}\n")
+    if cfg.Database.Password != "s3cret" {
+        t.Errorf("database.password = %q, want the environment value", cfg.Database.Password)
+    }
+}
+
+func TestLoadGroupsMixedOverrides(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "groups.yaml")
+    err := os.WriteFile(path, []byte(`
+groups:
+  - id: "111"
+    name: "Plain group"
+  - id: "222"
+    name: "Niche group"
+    min_likes: 50
+    days_back: 14
+    keywords: ["documentary", "series"]
+  - id: "333"
+    name: "Keywords only"
+    keywords: ["giveaway"]
+  - id: "brand"
+    name: "Brand page"
+    type: "page"
+    days_back: 3
+`), 0644)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    groups, err := LoadGroups(path)
+    if err != nil {
+        t.Fatalf("LoadGroups: %v", err)
+    }
+    if len(groups) != 4 {
+        t.Fatalf("loaded %d groups, want 4", len(groups))
+    }
+
+    global := FilterConfig{MinLikes: 1000, DaysBack: 5, Keywords: []string{"netflix"}}
+    tests := []struct {
+        overrides    bool
+        page         bool
+        wantMinLikes int
+        wantDaysBack int
+        wantKeywords []string
+    }{
+        {false, false, 1000, 5, []string{"netflix"}},
+        {true, false, 50, 14, []string{"documentary", "series"}},
+        {true, false, 1000, 5, []string{"giveaway"}},
+        {true, true, 1000, 3, []string{"netflix"}},
+    }
+
+    for i, tt := range tests {
+        g := groups[i]
+        if g.HasFilterOverrides() != tt.overrides || g.IsPage() != tt.page {
+            t.Errorf("group %s: HasFilterOverrides() = %v, IsPage() = %v, want %v and %v",
+                g.ID, g.HasFilterOverrides(), g.IsPage(), tt.overrides, tt.page)
+        }
+        filter := g.PostFilter(global)
+        if filter.MinLikes != tt.wantMinLikes || filter.DaysBack != tt.wantDaysBack || !reflect.DeepEqual(filter.Keywords, tt.wantKeywords) {
+            t.Errorf("group %s: filter min_likes = %d, days_back = %d, keywords = %q, want %d, %d, %q",
+                g.ID, filter.MinLikes, filter.DaysBack, filter.Keywords, tt.wantMinLikes, tt.wantDaysBack, tt.wantKeywords)
+        }
+    }
+
+    // Applying overrides leaves the global filter untouched for later groups
+    if global.MinLikes != 1000 || global.DaysBack != 5 || !reflect.DeepEqual(global.Keywords, []string{"netflix"}) {
+        t.Errorf("global filter changed to %+v", global)
+    }
+}
+
+func TestLoadGroupsMissingFile(t *testing.T) {
+    if _, err := LoadGroups(filepath.Join(t.TempDir(), "groups.yaml")); err == nil {
+        t.Error("LoadGroups() = nil error for a missing file")
+    }
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+    "fmt"
+    "net/url"
+    "strings"
+)
+
+// Validate checks required fields and value ranges, returning one error that
+// lists every problem found
+func (c *Config) Validate() error {
+    var problems []string
+    add := func(format string, args ...interface{}) {
+        problems = append(problems, fmt.Sprintf(format, args...))
+    }
+
+    // Facebook
+    if c.Facebook.Timeout <= 0 {
+        add("facebook.timeout must be a positive number of seconds, got %d", c.Facebook.Timeout)
+    }
+    if c.Facebook.RateLimit.DelayBetweenRequests <= 0 {
+        add("facebook.rate_limit.delay_between_requests must be at least 1 second, got %d",
+            c.Facebook.RateLimit.DelayBetweenRequests)
+    }
+    if c.Facebook.RateLimit.RequestsPerMinute < 0 {
+        add("facebook.rate_limit.requests_per_minute must not be negative")
+    }
+    if c.Facebook.RateLimit.JitterPercent < 0 || c.Facebook.RateLimit.JitterPercent > 100 {
+        add("facebook.rate_limit.jitter_percent must be between 0 and 100, got %d", c.Facebook.RateLimit.JitterPercent)
+    }
+    switch c.Facebook.Auth.Method {
+    case "":
+        add("facebook.auth.method is required (e.g. \"cookies\")")
+    case "cookies":
+        if c.Facebook.Auth.CookiesFile == "" {
+            add("facebook.auth.cookies_file is required when auth.method is \"cookies\"")
+        }
+    }
+    if c.Facebook.Auth.AccountCooldown < 0 {
+        add("facebook.auth.account_cooldown must not be negative")
+    }
+
+    // Scraper
+    if c.Scraper.ConcurrentWorkers < 0 {
+        add("scraper.concurrent_workers must not be negative")
+    }
+    if c.Scraper.RetryAttempts < 0 || c.Scraper.RetryDelay < 0 {
+        add("scraper.retry_attempts and scraper.retry_delay must not be negative")
+    }
+    switch c.Scraper.OutputFormat {
+    case "", "none", "json", "csv", "jsonl":
+    default:
+        add("scraper.output_format must be json, csv, jsonl or none, got %q", c.Scraper.OutputFormat)
+    }
+    if interval, err := c.Scraper.ScrapeInterval(); err != nil {
+        add("scraper.interval must be a duration such as \"30m\" or \"6h\", got %q", c.Scraper.Interval)
+    } else if interval < 0 {
+        add("scraper.interval must not be negative")
+    }
+    if c.Scraper.MaxPages < 0 {
+        add("scraper.max_pages must not be negative")
+    }
+    if c.Scraper.Browser.MaxScrolls < 0 || c.Scraper.Browser.WaitTimeout < 0 {
+        add("scraper.browser.max_scrolls and scraper.browser.wait_timeout must not be negative")
+    }
+
+    // Filter
+    if c.Filter.MinLikes < 0 || c.Filter.MinComments < 0 || c.Filter.MinShares < 0 {
+        add("filter thresholds must not be negative")
+    }
+    if c.Filter.MaxLikes > 0 && c.Filter.MaxLikes < c.Filter.MinLikes {
+        add("filter.max_likes (%d) is below filter.min_likes (%d)", c.Filter.MaxLikes, c.Filter.MinLikes)
+    }
+    if c.Filter.DaysBack < 0 {
+        add("filter.days_back must not be negative")
+    }
+    for kind, min := range c.Filter.MinReactions {
+        if min < 0 {
+            add("filter.min_reactions.%s must not be negative", kind)
+        }
+    }
+    switch c.Filter.KeywordMatch {
+    case "", "any", "all":
+    default:
+        add("filter.keyword_match must be \"any\" or \"all\", got %q", c.Filter.KeywordMatch)
+    }
+
+    if len(c.Filter.Languages) > 0 && !c.Scraper.DetectLanguage {
+        add("filter.languages needs scraper.detect_language to be enabled")
+    }
+
+    // Database
+    if c.Database.Host == "" {
+        add("database.host is required (or set DB_HOST)")
+    }
+    if c.Database.Name == "" {
+        add("database.name is required (or set DB_NAME)")
+    }
+    if c.Database.User == "" {
+        add("database.user is required (or set DB_USER)")
+    }
+    if c.Database.Port <= 0 || c.Database.Port > 65535 {
+        add("database.port must be between 1 and 65535, got %d", c.Database.Port)
+    }
+    if c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+        add("database.max_idle_conns (%d) exceeds database.max_open_conns (%d)",
+            c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+    }
+    if c.Database.RetentionDays < 0 {
+        add("database.retention_days must not be negative")
+    }
+
+    // API
+    if c.API.ReadTimeout < 0 || c.API.WriteTimeout < 0 || c.API.IdleTimeout < 0 || c.API.MaxHeaderBytes < 0 {
+        add("api timeouts and api.max_header_bytes must not be negative")
+    }
+    if (c.API.TLS.CertFile == "") != (c.API.TLS.KeyFile == "") {
+        add("api.tls.cert_file and api.tls.key_file must be set together")
+    }
+    if c.API.TLS.RedirectPort != "" && c.API.TLS.CertFile == "" {
+        add("api.tls.redirect_port needs api.tls.cert_file and api.tls.key_file")
+    }
+
+    // Notifications
+    if c.Notifications.MinLikes < 0 {
+        add("notifications.min_likes must not be negative")
+    }
+    if c.Notifications.WebhookURL != "" {
+        if u, err := url.Parse(c.Notifications.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            add("notifications.webhook_url must be an http or https URL, got %q", c.Notifications.WebhookURL)
+        }
+    }
+
+    // Logging
+    switch c.Logging.Format {
+    case "text", "json":
+    default:
+        add("logging.format must be \"text\" or \"json\", got %q", c.Logging.Format)
+    }
+
+    if len(problems) > 0 {
+        return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+    }
+    return nil
+}
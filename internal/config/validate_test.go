@@ -0,0 +1,85 @@
+package config
+
+import (
+    "strings"
+    "testing"
+)
+
+// validConfig returns the smallest configuration that passes Validate
+func validConfig() *Config {
+    var c Config
+    c.Facebook.Timeout = 30
+    c.Facebook.RateLimit.DelayBetweenRequests = 6
+    c.Facebook.Auth.Method = "cookies"
+    c.Facebook.Auth.CookiesFile = "configs/cookies.json"
+    c.Filter.MinLikes = DefaultMinLikes
+    c.Filter.DaysBack = DefaultDaysBack
+    c.Database.Host = "localhost"
+    c.Database.Port = 5432
+    c.Database.Name = "facebook_scraper"
+    c.Database.User = "postgres"
+    c.Database.MaxOpenConns = DefaultMaxOpenConns
+    c.Database.MaxIdleConns = DefaultMaxIdleConns
+    c.Logging.Format = DefaultLogFormat
+    return &c
+}
+
+func TestValidateValidConfig(t *testing.T) {
+    if err := validConfig().Validate(); err != nil {
+        t.Fatalf("Validate() = %v, want nil", err)
+    }
+}
+
+func TestValidateListsEveryProblem(t *testing.T) {
+    c := validConfig()
+    c.Database.Port = 70000
+    c.API.TLS.CertFile = "certs/server.crt"
+    c.Logging.Format = "xml"
+
+    err := c.Validate()
+    if err == nil {
+        t.Fatal("Validate() = nil, want an error")
+    }
+
+    msg := err.Error()
+    for _, want := range []string{
+        "database.port must be between 1 and 65535, got 70000",
+        "api.tls.cert_file and api.tls.key_file must be set together",
+        `logging.format must be "text" or "json", got "xml"`,
+    } {
+        if !strings.Contains(msg, want) {
+            t.Errorf("error does not mention %q:\n%s", want, msg)
+        }
+    }
+    if n := strings.Count(msg, "\n  - "); n != 3 {
+        t.Errorf("error lists %d problems, want 3:\n%s", n, msg)
+    }
+}
+
+func TestValidateRanges(t *testing.T) {
+    tests := []struct {
+        name   string
+        modify func(c *Config)
+        want   string
+    }{
+        {"timeout", func(c *Config) { c.Facebook.Timeout = 0 }, "facebook.timeout"},
+        {"delay", func(c *Config) { c.Facebook.RateLimit.DelayBetweenRequests = 0 }, "delay_between_requests"},
+        {"jitter", func(c *Config) { c.Facebook.RateLimit.JitterPercent = 150 }, "jitter_percent"},
+        {"auth method", func(c *Config) { c.Facebook.Auth.Method = "" }, "facebook.auth.method"},
+        {"cookies file", func(c *Config) { c.Facebook.Auth.CookiesFile = "" }, "cookies_file"},
+        {"database name", func(c *Config) { c.Database.Name = "" }, "database.name"},
+        {"max likes", func(c *Config) { c.Filter.MaxLikes = 10 }, "filter.max_likes"},
+        {"languages", func(c *Config) { c.Filter.Languages = []string{"en"} }, "detect_language"},
+        {"webhook url", func(c *Config) { c.Notifications.WebhookURL = "ftp://example.com" }, "webhook_url"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            c := validConfig()
+            tt.modify(c)
+            if err := c.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+                t.Errorf("Validate() = %v, want an error mentioning %q", err, tt.want)
+            }
+        })
+    }
+}
@@ -1,12 +1,14 @@
 package database
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "io/ioutil"
     "os"
     "path/filepath"
     "sort"
+    "time"
     "github.com/lib/pq"    
 
 
@@ -41,6 +43,13 @@ func NewConnection(cfg *config.DatabaseConfig, logger *logrus.Logger) (*DB, erro
         return nil, fmt.Errorf("failed to connect to database: %w", err)
     }
 
+    // Bound the pool so concurrent scrapers and the API can't exhaust Postgres
+    conn.SetMaxOpenConns(cfg.MaxOpenConns)
+    conn.SetMaxIdleConns(cfg.MaxIdleConns)
+    conn.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Minute)
+    logger.Infof("Database pool: max_open_conns=%d max_idle_conns=%d conn_max_lifetime=%dm",
+        cfg.MaxOpenConns, cfg.MaxIdleConns, cfg.ConnMaxLifetime)
+
     if err := conn.Ping(); err != nil {
         return nil, fmt.Errorf("failed to ping database: %w", err)
     }
@@ -61,17 +70,45 @@ func getEnvOrDefault(key, defaultValue string) string {
     return defaultValue
 }
 
+// migrationsDir holds the migration files, relative to the working directory
+const migrationsDir = "internal/database/migrations"
+
+// RunMigrations applies the migration files that haven't been applied yet,
+// each in its own transaction, recording them in schema_migrations
 func (db *DB) RunMigrations() error {
+    return db.runMigrations(migrationsDir)
+}
+
+func (db *DB) runMigrations(dir string) error {
     db.logger.Info("Running database migrations...")
 
-    migrationFiles, err := filepath.Glob("internal/database/migrations/*.sql")
+    migrationFiles, err := filepath.Glob(filepath.Join(dir, "*.sql"))
     if err != nil {
         return fmt.Errorf("failed to find migration files: %w", err)
     }
 
     sort.Strings(migrationFiles)
 
+    if _, err := db.conn.Exec(`
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            filename VARCHAR(255) PRIMARY KEY,
+            applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+        )`); err != nil {
+        return fmt.Errorf("failed to create schema_migrations table: %w", err)
+    }
+
+    applied, err := db.appliedMigrations()
+    if err != nil {
+        return err
+    }
+
     for _, file := range migrationFiles {
+        name := filepath.Base(file)
+        if applied[name] {
+            db.logger.Debugf("Skipping applied migration: %s", name)
+            continue
+        }
+
         db.logger.Infof("Running migration: %s", file)
         
         content, err := ioutil.ReadFile(file)
@@ -79,7 +116,7 @@ func (db *DB) RunMigrations() error {
             return fmt.Errorf("failed to read migration file %s: %w", file, err)
         }
 
-        if _, err := db.conn.Exec(string(content)); err != nil {
+        if err := db.applyMigration(name, string(content)); err != nil {
             return fmt.Errorf("failed to execute migration %s: %w", file, err)
         }
     }
@@ -88,17 +125,56 @@ func (db *DB) RunMigrations() error {
     return nil
 }
 
-// Update the SavePost method
+func (db *DB) appliedMigrations() (map[string]bool, error) {
+    rows, err := db.conn.Query(`SELECT filename FROM schema_migrations`)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+    }
+    defer rows.Close()
 
-func (db *DB) SavePost(post *models.Post) error {
-    query := `
+    applied := make(map[string]bool)
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+        }
+        applied[name] = true
+    }
+    return applied, rows.Err()
+}
+
+// applyMigration runs a migration and records it atomically, so a failing
+// file leaves neither partial schema changes nor a schema_migrations row
+func (db *DB) applyMigration(name, content string) error {
+    tx, err := db.conn.Begin()
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.Exec(content); err != nil {
+        return err
+    }
+    if _, err := tx.Exec(`INSERT INTO schema_migrations (filename) VALUES ($1)`, name); err != nil {
+        return fmt.Errorf("failed to record migration: %w", err)
+    }
+
+    return tx.Commit()
+}
+
+// savePostQuery upserts a post, refreshing engagement and media on conflict
+const savePostQuery = `
         INSERT INTO posts (
             group_id, group_name, post_id, author_name, author_id, content, 
             post_url, timestamp, likes, comments, shares, post_type, scraped_at,
-            images, videos, mentions, hashtags, links, media_count
+            images, videos, mentions, hashtags, links, media_count, reaction_breakdown,
+            source_type, search_query, raw_json, is_sponsored, language, link_preview, mention_links,
+            content_hash
         ) VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
-        ) ON CONFLICT (post_id) DO UPDATE SET
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+            $21, NULLIF($22, ''), NULLIF($23, '')::jsonb, $24, $25, NULLIF($26, '')::jsonb, NULLIF($27, '')::jsonb, $28
+        ) ON CONFLICT (group_id, post_id) DO UPDATE SET
+            group_name = EXCLUDED.group_name,
             likes = EXCLUDED.likes,
             comments = EXCLUDED.comments,
             shares = EXCLUDED.shares,
@@ -108,53 +184,181 @@ func (db *DB) SavePost(post *models.Post) error {
             mentions = EXCLUDED.mentions,
             hashtags = EXCLUDED.hashtags,
             links = EXCLUDED.links,
-            media_count = EXCLUDED.media_count
+            link_preview = EXCLUDED.link_preview,
+            mention_links = EXCLUDED.mention_links,
+            content_hash = EXCLUDED.content_hash,
+            media_count = EXCLUDED.media_count,
+            reaction_breakdown = EXCLUDED.reaction_breakdown,
+            is_sponsored = EXCLUDED.is_sponsored,
+            language = EXCLUDED.language,
+            raw_json = COALESCE(EXCLUDED.raw_json, posts.raw_json)
+        RETURNING (xmax = 0) AS inserted
     `
 
-    _, err := db.conn.Exec(query,
+func savePostArgs(post *models.Post) []interface{} {
+    return []interface{}{
         post.GroupID, post.GroupName, post.PostID, post.AuthorName, post.AuthorID,
         post.Content, post.PostURL, post.Timestamp, post.Likes, post.Comments,
         post.Shares, post.PostType, post.ScrapedAt, post.Images, post.Videos,
         pq.Array(post.Mentions), pq.Array(post.Hashtags), pq.Array(post.Links),
-        post.MediaCount,
-    )
+        post.MediaCount, post.ReactionBreakdown, post.SourceType, post.SearchQuery,
+        post.RawJSON, post.IsSponsored, post.Language, post.LinkPreview,
+        post.MentionLinks, post.ContentHash,
+    }
+}
+
+// saveSnapshotQuery records the engagement seen by the current scrape
+const saveSnapshotQuery = `
+        INSERT INTO engagement_snapshots (group_id, post_id, likes, comments, shares)
+        VALUES ($1, $2, $3, $4, $5)`
 
+func (db *DB) SavePost(ctx context.Context, post *models.Post) error {
+    _, err := db.SavePosts(ctx, []*models.Post{post})
     return err
 }
 
-func (db *DB) GetPostsByGroup(groupID string, limit int) ([]*models.Post, error) {
+// SavePosts upserts a batch of posts and their engagement snapshots in one
+// transaction with prepared statements; either every post is saved or none is.
+// It returns the posts that weren't stored before, as opposed to those whose
+// counts were only updated.
+func (db *DB) SavePosts(ctx context.Context, posts []*models.Post) ([]*models.Post, error) {
+    if len(posts) == 0 {
+        return nil, nil
+    }
+
+    tx, err := db.conn.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    stmt, err := tx.PrepareContext(ctx, savePostQuery)
+    if err != nil {
+        return nil, fmt.Errorf("failed to prepare post insert: %w", err)
+    }
+    defer stmt.Close()
+
+    snapshotStmt, err := tx.PrepareContext(ctx, saveSnapshotQuery)
+    if err != nil {
+        return nil, fmt.Errorf("failed to prepare snapshot insert: %w", err)
+    }
+    defer snapshotStmt.Close()
+
+    var inserted []*models.Post
+    for _, post := range posts {
+        // xmax is 0 only for rows the upsert inserted rather than updated
+        var isNew bool
+        if err := stmt.QueryRowContext(ctx, savePostArgs(post)...).Scan(&isNew); err != nil {
+            return nil, fmt.Errorf("failed to save post %s: %w", post.PostID, err)
+        }
+        if isNew {
+            inserted = append(inserted, post)
+        }
+        if _, err := snapshotStmt.ExecContext(ctx, post.GroupID, post.PostID, post.Likes, post.Comments, post.Shares); err != nil {
+            return nil, fmt.Errorf("failed to save engagement snapshot for post %s: %w", post.PostID, err)
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, err
+    }
+    return inserted, nil
+}
+
+// SaveScrapeError records a failed scrape attempt; the time is set by the
+// database
+func (db *DB) SaveScrapeError(ctx context.Context, scrapeErr *models.ScrapeError) error {
+    _, err := db.conn.ExecContext(ctx, `
+        INSERT INTO scrape_errors (group_id, strategy, url, status_code, error)
+        VALUES ($1, $2, $3, NULLIF($4, 0), $5)`,
+        scrapeErr.GroupID, scrapeErr.Strategy, scrapeErr.URL, scrapeErr.StatusCode, scrapeErr.Error)
+    if err != nil {
+        return fmt.Errorf("failed to save scrape error: %w", err)
+    }
+    return nil
+}
+
+// SaveComments replaces the stored comments of a post with the given ones so
+// re-scraping a post doesn't duplicate them
+func (db *DB) SaveComments(ctx context.Context, groupID, postID string, comments []*models.Comment) error {
+    tx, err := db.conn.BeginTx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.ExecContext(ctx, `DELETE FROM comments WHERE group_id = $1 AND post_id = $2`, groupID, postID); err != nil {
+        return fmt.Errorf("failed to clear comments: %w", err)
+    }
+
     query := `
-        SELECT id, group_id, group_name, post_id, author_id, author_name, content,
-               post_url, timestamp, likes, comments, shares, images, videos,
-               links, hashtags, mentions, post_type, scraped_at, created_at, updated_at
+        INSERT INTO comments (group_id, post_id, author_name, author_id, content, timestamp, likes, scraped_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+    for _, comment := range comments {
+        if _, err := tx.ExecContext(ctx, query,
+            groupID, postID, comment.AuthorName, comment.AuthorID, comment.Content,
+            comment.Timestamp, comment.Likes, comment.ScrapedAt,
+        ); err != nil {
+            return fmt.Errorf("failed to insert comment: %w", err)
+        }
+    }
+
+    return tx.Commit()
+}
+
+// DeleteOldPosts removes posts scraped more than olderThan ago, along with
+// their comments, and returns how many posts were deleted
+func (db *DB) DeleteOldPosts(ctx context.Context, olderThan time.Duration) (int64, error) {
+    tx, err := db.conn.BeginTx(ctx, nil)
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    result, err := tx.ExecContext(ctx, `DELETE FROM posts WHERE scraped_at < $1`, time.Now().Add(-olderThan))
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete old posts: %w", err)
+    }
+    deleted, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("failed to count deleted posts: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit cleanup: %w", err)
+    }
+
+    db.logger.Infof("Deleted %d posts scraped more than %v ago", deleted, olderThan)
+    return deleted, nil
+}
+
+// GetPostsByGroup returns one page of a group's posts, newest first
+func (db *DB) GetPostsByGroup(ctx context.Context, groupID string, limit, offset int) ([]*models.Post, error) {
+    query := fmt.Sprintf(`
+        SELECT %s
         FROM posts 
         WHERE group_id = $1 
         ORDER BY timestamp DESC 
-        LIMIT $2`
+        LIMIT $2 OFFSET $3`, postColumns)
 
-    rows, err := db.conn.Query(query, groupID, limit)
+    rows, err := db.conn.QueryContext(ctx, query, groupID, limit, offset)
     if err != nil {
         return nil, fmt.Errorf("failed to query posts: %w", err)
     }
     defer rows.Close()
 
-    var posts []*models.Post
-    for rows.Next() {
-        post := &models.Post{}
-        err := rows.Scan(
-            &post.ID, &post.GroupID, &post.GroupName, &post.PostID, &post.AuthorID,
-            &post.AuthorName, &post.Content, &post.PostURL, &post.Timestamp,
-            &post.Likes, &post.Comments, &post.Shares, &post.Images, &post.Videos,
-            &post.Links, &post.Hashtags, &post.Mentions, &post.PostType,
-            &post.ScrapedAt, &post.CreatedAt, &post.UpdatedAt,
-        )
-        if err != nil {
-            return nil, fmt.Errorf("failed to scan post: %w", err)
-        }
-        posts = append(posts, post)
-    }
+    return scanPosts(rows)
+}
 
-    return posts, nil
+// GetPostsByGroupCount returns how many posts are stored for a group
+func (db *DB) GetPostsByGroupCount(ctx context.Context, groupID string) (int, error) {
+    var count int
+    err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM posts WHERE group_id = $1`, groupID).Scan(&count)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get group posts count: %w", err)
+    }
+    return count, nil
 }
 
 func (db *DB) Close() error {
@@ -0,0 +1,328 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+    "facebook-scraper/internal/database/models"
+)
+
+// testDSNEnv names the variable holding a key=value Postgres DSN for the
+// database tests, e.g. "host=localhost user=postgres dbname=postgres
+// sslmode=disable". The tests are skipped when it isn't set.
+const testDSNEnv = "TEST_DATABASE_DSN"
+
+// newTestDB connects to the test database with an empty schema of its own as
+// the search path; the schema is dropped when the test ends
+func newTestDB(t *testing.T) *DB {
+    t.Helper()
+
+    dsn := os.Getenv(testDSNEnv)
+    if dsn == "" {
+        t.Skipf("%s not set", testDSNEnv)
+    }
+
+    admin, err := sql.Open("postgres", dsn)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { admin.Close() })
+    if err := admin.Ping(); err != nil {
+        t.Fatalf("failed to reach the test database: %v", err)
+    }
+
+    schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+    if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+    // public stays on the path for extensions such as pg_trgm
+    conn, err := sql.Open("postgres", dsn+" search_path="+schema+",public")
+    if err != nil {
+        t.Fatal(err)
+    }
+    // Close before the schema is dropped, as cleanups run last in first out
+    t.Cleanup(func() { conn.Close() })
+
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return &DB{conn: conn, logger: logger}
+}
+
+// newPostsDB is newTestDB with the posts schema: the base table from
+// testdata followed by the real migrations
+func newPostsDB(t *testing.T) *DB {
+    t.Helper()
+
+    db := newTestDB(t)
+    base, err := os.ReadFile(filepath.Join("testdata", "001_create_posts.sql"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := db.conn.Exec(string(base)); err != nil {
+        t.Fatalf("creating the posts table: %v", err)
+    }
+    if err := db.runMigrations("migrations"); err != nil {
+        t.Fatalf("runMigrations: %v", err)
+    }
+    return db
+}
+
+// testPost returns a post ready to save, scraped now
+func testPost(groupID, postID string, likes int) *models.Post {
+    now := time.Now()
+    return &models.Post{
+        GroupID:    groupID,
+        GroupName:  "Group " + groupID,
+        PostID:     postID,
+        AuthorName: "Jane Doe",
+        Content:    "Post " + postID,
+        PostURL:    "https://www.facebook.com/groups/" + groupID + "/posts/" + postID,
+        Timestamp:  now.Add(-time.Hour),
+        Likes:      likes,
+        PostType:   "text",
+        SourceType: "group",
+        ScrapedAt:  now,
+        Images:     "[]",
+        Videos:     "[]",
+    }
+}
+
+// writeMigrations writes the given files to a temporary migrations directory
+func writeMigrations(t *testing.T, files map[string]string) string {
+    t.Helper()
+
+    dir := t.TempDir()
+    for name, content := range files {
+        if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    return dir
+}
+
+func countRows(t *testing.T, db *DB, table string) int {
+    t.Helper()
+
+    var count int
+    if err := db.conn.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+        t.Fatalf("counting %s: %v", table, err)
+    }
+    return count
+}
+
+func TestRunMigrationsSecondRunIsNoOp(t *testing.T) {
+    db := newTestDB(t)
+
+    // Neither migration is idempotent, so running either twice would fail or
+    // duplicate the row
+    dir := writeMigrations(t, map[string]string{
+        "001_create_widgets.sql": `CREATE TABLE widgets (id SERIAL PRIMARY KEY, name TEXT NOT NULL);`,
+        "002_seed_widgets.sql":   `INSERT INTO widgets (name) VALUES ('first');`,
+    })
+
+    for run := 1; run <= 2; run++ {
+        if err := db.runMigrations(dir); err != nil {
+            t.Fatalf("run %d: runMigrations: %v", run, err)
+        }
+    }
+
+    if got := countRows(t, db, "widgets"); got != 1 {
+        t.Errorf("widgets has %d rows, want 1", got)
+    }
+    if got := countRows(t, db, "schema_migrations"); got != 2 {
+        t.Errorf("schema_migrations has %d rows, want 2", got)
+    }
+}
+
+func TestRunMigrationsRollsBackFailedMigration(t *testing.T) {
+    db := newTestDB(t)
+
+    dir := writeMigrations(t, map[string]string{
+        "001_create_widgets.sql": `CREATE TABLE widgets (id SERIAL PRIMARY KEY, name TEXT NOT NULL);`,
+        "002_broken.sql":         `INSERT INTO widgets (name) VALUES ('partial'); SELECT * FROM missing_table;`,
+    })
+
+    if err := db.runMigrations(dir); err == nil {
+        t.Fatal("runMigrations() = nil, want the broken migration's error")
+    }
+
+    if got := countRows(t, db, "widgets"); got != 0 {
+        t.Errorf("widgets has %d rows, want the broken migration's insert rolled back", got)
+    }
+    var applied []string
+    rows, err := db.conn.Query(`SELECT filename FROM schema_migrations ORDER BY filename`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var name string
+        rows.Scan(&name)
+        applied = append(applied, name)
+    }
+    if len(applied) != 1 || applied[0] != "001_create_widgets.sql" {
+        t.Errorf("schema_migrations = %v, want only 001_create_widgets.sql", applied)
+    }
+}
+
+func TestSavePostsSamePostInTwoGroups(t *testing.T) {
+    db := newPostsDB(t)
+    ctx := context.Background()
+
+    first, shared := testPost("group-a", "100", 10), testPost("group-b", "100", 20)
+    if err := db.SavePost(ctx, first); err != nil {
+        t.Fatalf("SavePost(group-a): %v", err)
+    }
+    if err := db.SavePost(ctx, shared); err != nil {
+        t.Fatalf("SavePost(group-b): %v", err)
+    }
+
+    for _, post := range []*models.Post{first, shared} {
+        posts, err := db.GetPostsByGroup(ctx, post.GroupID, 10, 0)
+        if err != nil {
+            t.Fatalf("GetPostsByGroup(%s): %v", post.GroupID, err)
+        }
+        if len(posts) != 1 || posts[0].PostID != "100" || posts[0].Likes != post.Likes {
+            t.Errorf("%s has %+v, want post 100 with %d likes", post.GroupID, posts, post.Likes)
+        }
+    }
+    if got := countRows(t, db, "posts"); got != 2 {
+        t.Errorf("posts has %d rows, want one per group", got)
+    }
+
+    // Saving again updates the group's row rather than adding one
+    first.Likes = 15
+    if err := db.SavePost(ctx, first); err != nil {
+        t.Fatalf("SavePost(group-a) again: %v", err)
+    }
+    if got := countRows(t, db, "posts"); got != 2 {
+        t.Errorf("posts has %d rows after an update, want 2", got)
+    }
+}
+
+func TestGetPostsByGroupMediaCount(t *testing.T) {
+    db := newPostsDB(t)
+    ctx := context.Background()
+
+    album := testPost("group-a", "1", 10)
+    album.Images = `[{"url": "https://scontent.xx.fbcdn.net/v/photo.jpg", "type": "image"}]`
+    album.MediaCount = 7
+    text := testPost("group-a", "2", 10)
+    text.Timestamp = album.Timestamp.Add(-time.Hour)
+    if _, err := db.SavePosts(ctx, []*models.Post{album, text}); err != nil {
+        t.Fatalf("SavePosts: %v", err)
+    }
+
+    posts, err := db.GetPostsByGroup(ctx, "group-a", 10, 0)
+    if err != nil {
+        t.Fatalf("GetPostsByGroup: %v", err)
+    }
+    if len(posts) != 2 {
+        t.Fatalf("GetPostsByGroup returned %d posts, want 2", len(posts))
+    }
+    if posts[0].MediaCount != 7 || posts[1].MediaCount != 0 {
+        t.Errorf("MediaCount = %d and %d, want 7 and 0", posts[0].MediaCount, posts[1].MediaCount)
+    }
+}
+
+func TestSavePostsReturnsOnlyInsertedPosts(t *testing.T) {
+    db := newPostsDB(t)
+    ctx := context.Background()
+
+    existing := testPost("group-a", "1", 500)
+    if _, err := db.SavePosts(ctx, []*models.Post{existing}); err != nil {
+        t.Fatalf("SavePosts: %v", err)
+    }
+
+    // The webhook is told about what SavePosts returns, so a re-scraped post
+    // must not come back even when its counts changed
+    existing.Likes = 800
+    fresh := testPost("group-a", "2", 300)
+    inserted, err := db.SavePosts(ctx, []*models.Post{existing, fresh})
+    if err != nil {
+        t.Fatalf("SavePosts: %v", err)
+    }
+    if len(inserted) != 1 || inserted[0] != fresh {
+        t.Errorf("SavePosts returned %+v, want only the new post", inserted)
+    }
+}
+
+func TestGetScrapingStatsWindow(t *testing.T) {
+    db := newPostsDB(t)
+    ctx := context.Background()
+
+    seed := []struct {
+        group   string
+        likes   int
+        daysAgo int
+        kind    string
+    }{
+        {"a", 2000, 1, "text"},
+        {"a", 700, 2, "text"},
+        {"b", 800, 10, "text"},
+        {"c", 100, 20, "photo"},
+        {"d", 5000, 60, "text"},
+    }
+    var posts []*models.Post
+    for i, s := range seed {
+        post := testPost(s.group, fmt.Sprint(i+1), s.likes)
+        post.ScrapedAt = time.Now().AddDate(0, 0, -s.daysAgo)
+        post.PostType = s.kind
+        posts = append(posts, post)
+    }
+    if _, err := db.SavePosts(ctx, posts); err != nil {
+        t.Fatalf("SavePosts: %v", err)
+    }
+
+    narrow, err := db.GetScrapingStats(ctx, 1000, 5)
+    if err != nil {
+        t.Fatalf("GetScrapingStats(1000, 5): %v", err)
+    }
+    wide, err := db.GetScrapingStats(ctx, 500, 30)
+    if err != nil {
+        t.Fatalf("GetScrapingStats(500, 30): %v", err)
+    }
+
+    if !reflect.DeepEqual(statsKeys(narrow), statsKeys(wide)) {
+        t.Errorf("keys differ between windows: %v and %v", statsKeys(narrow), statsKeys(wide))
+    }
+
+    tests := []struct {
+        key          string
+        narrow, wide interface{}
+    }{
+        {"total_posts", 5, 5},
+        {"high_engagement_posts", 1, 3},
+        {"groups_scraped", 1, 3},
+        {"posts_by_type", map[string]int{"text": 2}, map[string]int{"text": 3, "photo": 1}},
+        {"average_likes", 1350.0, 900.0},
+    }
+    for _, tt := range tests {
+        if !reflect.DeepEqual(narrow[tt.key], tt.narrow) {
+            t.Errorf("5 days, 1000 likes: %s = %v, want %v", tt.key, narrow[tt.key], tt.narrow)
+        }
+        if !reflect.DeepEqual(wide[tt.key], tt.wide) {
+            t.Errorf("30 days, 500 likes: %s = %v, want %v", tt.key, wide[tt.key], tt.wide)
+        }
+    }
+}
+
+func statsKeys(stats map[string]interface{}) []string {
+    keys := make([]string, 0, len(stats))
+    for key := range stats {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
@@ -0,0 +1,13 @@
+-- Top-level comments scraped alongside posts (scraper.scrape_comments)
+CREATE TABLE IF NOT EXISTS comments (
+    id SERIAL PRIMARY KEY,
+    post_id VARCHAR(255) NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    author_name VARCHAR(255),
+    author_id VARCHAR(255),
+    content TEXT,
+    timestamp TIMESTAMP,
+    likes INTEGER DEFAULT 0,
+    scraped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments(post_id);
@@ -0,0 +1,2 @@
+-- Per-type reaction counts (like, love, haha, ...); posts.likes keeps the total
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS reaction_breakdown JSONB DEFAULT '{}'::jsonb;
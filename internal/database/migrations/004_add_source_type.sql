@@ -0,0 +1,2 @@
+-- Posts can come from Pages as well as groups; group_id then holds the Page ID
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS source_type VARCHAR(16) DEFAULT 'group';
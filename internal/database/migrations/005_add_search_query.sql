@@ -0,0 +1,4 @@
+-- Keyword search results are stored with group_id 'search' and their query
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_query VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_posts_search_query ON posts(search_query);
@@ -0,0 +1,12 @@
+-- One row per scrape of a post so engagement growth can be charted;
+-- posts keeps the latest counts
+CREATE TABLE IF NOT EXISTS engagement_snapshots (
+    id SERIAL PRIMARY KEY,
+    post_id VARCHAR(255) NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    likes INTEGER DEFAULT 0,
+    comments INTEGER DEFAULT 0,
+    shares INTEGER DEFAULT 0,
+    captured_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_engagement_snapshots_post_id ON engagement_snapshots(post_id, captured_at);
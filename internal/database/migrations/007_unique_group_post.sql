@@ -0,0 +1,31 @@
+-- A shared post can appear in several groups, so posts are unique per
+-- (group_id, post_id) rather than by post_id alone. Comments and engagement
+-- snapshots follow the composite key.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_group_post ON posts(group_id, post_id);
+CREATE INDEX IF NOT EXISTS idx_posts_post_id ON posts(post_id);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS group_id VARCHAR(255);
+UPDATE comments c SET group_id = p.group_id
+FROM posts p WHERE c.group_id IS NULL AND p.post_id = c.post_id;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_post_id_fkey;
+
+ALTER TABLE engagement_snapshots ADD COLUMN IF NOT EXISTS group_id VARCHAR(255);
+UPDATE engagement_snapshots s SET group_id = p.group_id
+FROM posts p WHERE s.group_id IS NULL AND p.post_id = s.post_id;
+ALTER TABLE engagement_snapshots DROP CONSTRAINT IF EXISTS engagement_snapshots_post_id_fkey;
+
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_post_id_key;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'comments_group_post_fkey') THEN
+        ALTER TABLE comments ADD CONSTRAINT comments_group_post_fkey
+            FOREIGN KEY (group_id, post_id) REFERENCES posts(group_id, post_id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'engagement_snapshots_group_post_fkey') THEN
+        ALTER TABLE engagement_snapshots ADD CONSTRAINT engagement_snapshots_group_post_fkey
+            FOREIGN KEY (group_id, post_id) REFERENCES posts(group_id, post_id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_comments_group_post ON comments(group_id, post_id);
@@ -0,0 +1,2 @@
+-- The ScrapedPost each row was built from, kept for debugging and reprocessing
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS raw_json JSONB;
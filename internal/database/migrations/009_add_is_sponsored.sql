@@ -0,0 +1,2 @@
+-- Ads and "Suggested for you" posts, normally dropped by filter.exclude_sponsored
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS is_sponsored BOOLEAN NOT NULL DEFAULT FALSE;
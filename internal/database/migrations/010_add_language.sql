@@ -0,0 +1,2 @@
+-- ISO 639-1 code guessed when scraper.detect_language is enabled, '' otherwise
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS language VARCHAR(8) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Title, description and domain of a shared link's preview card
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS link_preview JSONB;
@@ -0,0 +1,3 @@
+-- Mentioned people and Pages with their profile IDs and links; posts.mentions
+-- keeps the flat list of names
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS mention_links JSONB;
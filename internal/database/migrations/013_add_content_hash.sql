@@ -0,0 +1,9 @@
+-- SHA-256 of the lowercased, whitespace-collapsed content, '' for posts without
+-- text; used to find reshares of the same content across runs
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64) NOT NULL DEFAULT '';
+
+UPDATE posts
+SET content_hash = encode(sha256(convert_to(lower(btrim(regexp_replace(content, '\s+', ' ', 'g'))), 'UTF8')), 'hex')
+WHERE content_hash = '' AND btrim(regexp_replace(content, '\s+', ' ', 'g')) <> '';
+
+CREATE INDEX IF NOT EXISTS idx_posts_content_hash ON posts (content_hash) WHERE content_hash <> '';
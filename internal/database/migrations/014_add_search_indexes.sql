@@ -0,0 +1,13 @@
+-- Trigram indexes let the ILIKE '%term%' searches of SearchPosts use an index
+-- instead of scanning every post (for terms of three or more characters)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_posts_content_trgm ON posts USING GIN (content gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_posts_author_name_trgm ON posts USING GIN (author_name gin_trgm_ops);
+
+-- Hashtag search uses hashtags @> ARRAY[tag], which this index supports
+CREATE INDEX IF NOT EXISTS idx_posts_hashtags ON posts USING GIN (hashtags);
+
+-- Group listings ordered by time, and the default likes ordering
+CREATE INDEX IF NOT EXISTS idx_posts_group_timestamp ON posts (group_id, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_likes ON posts (likes DESC);
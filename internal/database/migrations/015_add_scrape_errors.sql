@@ -0,0 +1,14 @@
+-- Failed URL strategy attempts, kept so operators can see why a group stopped
+-- returning posts
+CREATE TABLE IF NOT EXISTS scrape_errors (
+    id SERIAL PRIMARY KEY,
+    group_id VARCHAR(255) NOT NULL,
+    strategy VARCHAR(50) NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    status_code INTEGER,
+    error TEXT NOT NULL,
+    occurred_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_errors_occurred_at ON scrape_errors(occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scrape_errors_group_id ON scrape_errors(group_id, occurred_at DESC);
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Comment struct {
+    ID         int64     `json:"id" db:"id"`
+    GroupID    string    `json:"group_id" db:"group_id"`
+    PostID     string    `json:"post_id" db:"post_id"`
+    AuthorName string    `json:"author_name" db:"author_name"`
+    AuthorID   string    `json:"author_id" db:"author_id"`
+    Content    string    `json:"content" db:"content"`
+    Timestamp  time.Time `json:"timestamp" db:"timestamp"`
+    Likes      int       `json:"likes" db:"likes"`
+    ScrapedAt  time.Time `json:"scraped_at" db:"scraped_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// GroupSummary aggregates the stored posts of one group or Page
+type GroupSummary struct {
+    GroupID     string    `json:"group_id" db:"group_id"`
+    GroupName   string    `json:"group_name" db:"group_name"`
+    PostCount   int       `json:"post_count" db:"post_count"`
+    LastScraped time.Time `json:"last_scraped" db:"last_scraped"`
+}
+
+// GroupStats compares the engagement of one group or Page with the others
+type GroupStats struct {
+    GroupID             string    `json:"group_id" db:"group_id"`
+    GroupName           string    `json:"group_name" db:"group_name"`
+    PostCount           int       `json:"post_count" db:"post_count"`
+    AverageLikes        float64   `json:"average_likes" db:"average_likes"`
+    HighEngagementPosts int       `json:"high_engagement_posts" db:"high_engagement_posts"`
+    LastScraped         time.Time `json:"last_scraped" db:"last_scraped"`
+}
@@ -15,23 +15,34 @@ type Post struct {
     AuthorID    string    `json:"author_id" db:"author_id"`
     AuthorName  string    `json:"author_name" db:"author_name"`
     Content     string    `json:"content" db:"content"`
+    ContentHash string    `json:"content_hash,omitempty" db:"content_hash"` // SHA-256 of the normalized content
     PostURL     string    `json:"post_url" db:"post_url"`
     Timestamp   time.Time `json:"timestamp" db:"timestamp"`
     Likes       int       `json:"likes" db:"likes"`
     Comments    int       `json:"comments" db:"comments"`
     Shares      int       `json:"shares" db:"shares"`
     PostType    string    `json:"post_type" db:"post_type"`
+    IsSponsored bool      `json:"is_sponsored" db:"is_sponsored"`
+    Language    string    `json:"language,omitempty" db:"language"`
+    SourceType  string    `json:"source_type" db:"source_type"`
+    SearchQuery string    `json:"search_query,omitempty" db:"search_query"`
     ScrapedAt   time.Time `json:"scraped_at" db:"scraped_at"`
     CreatedAt   time.Time `json:"created_at" db:"created_at"`
     UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 
-        // Add these new fields
-    Images      string   `db:"images" json:"images"`       // JSON string
-    Videos      string   `db:"videos" json:"videos"`       // JSON string
-    Mentions    []string `db:"mentions" json:"mentions"`   // PostgreSQL array
-    Hashtags    []string `db:"hashtags" json:"hashtags"`   // PostgreSQL array
-    Links       []string `db:"links" json:"links"`         // PostgreSQL array
-    MediaCount  int      `db:"media_count" json:"media_count"`
+    // Add these new fields
+    Images       string   `db:"images" json:"images"`     // JSON string
+    Videos       string   `db:"videos" json:"videos"`     // JSON string
+    Mentions     []string `db:"mentions" json:"mentions"` // PostgreSQL array
+    Hashtags     []string `db:"hashtags" json:"hashtags"` // PostgreSQL array
+    Links        []string `db:"links" json:"links"`       // PostgreSQL array
+    LinkPreview  string   `db:"link_preview" json:"-"`    // JSON object, "" when the post has none
+    MentionLinks string   `db:"mention_links" json:"-"`   // JSON array of mentions with profile links
+    MediaCount   int      `db:"media_count" json:"media_count"`
+
+    ReactionBreakdown ReactionMap `db:"reaction_breakdown" json:"reaction_breakdown"` // JSON object
+
+    RawJSON string `db:"raw_json" json:"-"` // scraped post as JSON; read with GetRawPost
 }
 
 // StringArray for handling JSON arrays in PostgreSQL
@@ -56,4 +67,28 @@ func (sa *StringArray) Scan(value interface{}) error {
     }
     
     return json.Unmarshal(bytes, sa)
-}
\ No newline at end of file
+}
+
+// ReactionMap stores per-type reaction counts as a JSON object in PostgreSQL
+type ReactionMap map[string]int
+
+func (rm ReactionMap) Value() (driver.Value, error) {
+    if len(rm) == 0 {
+        return "{}", nil
+    }
+    return json.Marshal(rm)
+}
+
+func (rm *ReactionMap) Scan(value interface{}) error {
+    if value == nil {
+        *rm = ReactionMap{}
+        return nil
+    }
+
+    bytes, ok := value.([]byte)
+    if !ok {
+        return errors.New("type assertion to []byte failed")
+    }
+
+    return json.Unmarshal(bytes, rm)
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ScrapeError is a failed attempt to scrape a group or Page with one URL
+// strategy
+type ScrapeError struct {
+    ID         int       `json:"id" db:"id"`
+    GroupID    string    `json:"group_id" db:"group_id"`
+    Strategy   string    `json:"strategy" db:"strategy"` // e.g. "url 2"
+    URL        string    `json:"url" db:"url"`
+    StatusCode int       `json:"status_code,omitempty" db:"status_code"` // 0 when no HTTP response was involved
+    Error      string    `json:"error" db:"error"`
+    OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
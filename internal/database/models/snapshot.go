@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// EngagementSnapshot is a post's engagement as seen by one scrape
+type EngagementSnapshot struct {
+    GroupID    string    `json:"group_id" db:"group_id"`
+    PostID     string    `json:"post_id" db:"post_id"`
+    Likes      int       `json:"likes" db:"likes"`
+    Comments   int       `json:"comments" db:"comments"`
+    Shares     int       `json:"shares" db:"shares"`
+    CapturedAt time.Time `json:"captured_at" db:"captured_at"`
+}
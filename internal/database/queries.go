@@ -1,61 +1,161 @@
 package database
 
 import (
+    "context"
     "database/sql"
+    "encoding/json"
+    "errors"
     "fmt"
+    "strings"
+    "time"
+
+    "github.com/lib/pq"
     "facebook-scraper/internal/database/models"
 )
 
-// GetPostsWithPagination retrieves posts with pagination support
-func (db *DB) GetPostsWithPagination(page, pageSize, minLikes int) ([]*models.Post, error) {
-    offset := (page - 1) * pageSize
-    
-    query := `
-        SELECT id, group_id, group_name, post_id, author_id, author_name, content,
+// postColumns lists the columns scanned by scanPosts, in order
+const postColumns = `id, group_id, group_name, post_id, author_id, author_name, content,
                post_url, timestamp, likes, comments, shares, images, videos,
                links, hashtags, mentions, post_type, scraped_at, created_at, updated_at,
-               media_count
+               media_count, reaction_breakdown, source_type, is_sponsored, language,
+               COALESCE(link_preview::text, ''), COALESCE(mention_links::text, ''), content_hash`
+
+// dedupeCondition drops posts when another post with the same content hash
+// has more engagement, or the same engagement and a lower id
+const dedupeCondition = `(posts.content_hash = '' OR NOT EXISTS (
+            SELECT 1 FROM posts AS copy
+            WHERE copy.content_hash = posts.content_hash
+              AND (copy.likes + copy.comments + copy.shares, -copy.id) >
+                  (posts.likes + posts.comments + posts.shares, -posts.id)))`
+
+// ErrPostNotFound is returned when no stored post has the requested ID
+var ErrPostNotFound = errors.New("post not found")
+
+// PostSearch holds the optional criteria for SearchPosts; empty fields are
+// ignored
+type PostSearch struct {
+    Query    string // matched against content
+    Author   string // matched against author_name
+    Hashtag  string // exact hashtag, with or without the leading #
+    Group    string // group ID or part of the group name
+    Page     int
+    PageSize int
+}
+
+// sortColumns whitelists the columns posts can be ordered by
+var sortColumns = map[string]string{
+    "likes":      "likes",
+    "comments":   "comments",
+    "shares":     "shares",
+    "timestamp":  "timestamp",
+    "scraped_at": "scraped_at",
+}
+
+// PostsQuery holds the paging, filtering and ordering options for
+// GetPostsWithPagination
+type PostsQuery struct {
+    Page     int
+    PageSize int
+    MinLikes int
+    Sort     string // one of the sortColumns keys, likes by default
+    Order    string // asc or desc, desc by default
+
+    From       time.Time // post timestamp lower bound, ignored when zero
+    To         time.Time // post timestamp upper bound (inclusive), ignored when zero
+    RecentDays int       // only posts scraped in the last N days, 0 for all
+    PostTypes  []string  // only these post types, all when empty
+    Dedupe     bool      // only the most engaged post of each content hash
+}
+
+// where builds the WHERE clause and its arguments
+func (q PostsQuery) where() (string, []interface{}) {
+    conditions := []string{"likes >= $1"}
+    args := []interface{}{q.MinLikes}
+
+    if !q.From.IsZero() {
+        args = append(args, q.From)
+        conditions = append(conditions, fmt.Sprintf("timestamp >= $%d", len(args)))
+    }
+    if !q.To.IsZero() {
+        args = append(args, q.To)
+        conditions = append(conditions, fmt.Sprintf("timestamp <= $%d", len(args)))
+    }
+    if q.RecentDays > 0 {
+        args = append(args, q.RecentDays)
+        conditions = append(conditions, fmt.Sprintf("scraped_at >= NOW() - make_interval(days => $%d)", len(args)))
+    }
+    if len(q.PostTypes) > 0 {
+        args = append(args, pq.Array(q.PostTypes))
+        conditions = append(conditions, fmt.Sprintf("post_type = ANY($%d)", len(args)))
+    }
+    if q.Dedupe {
+        conditions = append(conditions, dedupeCondition)
+    }
+
+    return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ValidSort reports whether sort and order are accepted by
+// GetPostsWithPagination; empty values select the defaults
+func ValidSort(sort, order string) bool {
+    if _, ok := sortColumns[sort]; sort != "" && !ok {
+        return false
+    }
+    switch strings.ToLower(order) {
+    case "", "asc", "desc":
+        return true
+    }
+    return false
+}
+
+// orderBy builds the ORDER BY clause from whitelisted values only
+func (q PostsQuery) orderBy() string {
+    column, ok := sortColumns[q.Sort]
+    if !ok {
+        column = "likes"
+    }
+    direction := "DESC"
+    if strings.EqualFold(q.Order, "asc") {
+        direction = "ASC"
+    }
+
+    if column == "scraped_at" {
+        return fmt.Sprintf("scraped_at %s, id %s", direction, direction)
+    }
+    return fmt.Sprintf("%s %s, scraped_at DESC", column, direction)
+}
+
+// GetPostsWithPagination retrieves posts with pagination support
+func (db *DB) GetPostsWithPagination(ctx context.Context, q PostsQuery) ([]*models.Post, error) {
+    offset := (q.Page - 1) * q.PageSize
+    where, args := q.where()
+    
+    query := fmt.Sprintf(`
+        SELECT %s
         FROM posts 
-        WHERE likes >= $1 
-            AND scraped_at >= NOW() - INTERVAL '5 days'
-        ORDER BY likes DESC, scraped_at DESC 
-        LIMIT $2 OFFSET $3`
+        %s
+        ORDER BY %s 
+        LIMIT $%d OFFSET $%d`, postColumns, where, q.orderBy(), len(args)+1, len(args)+2)
 
-    rows, err := db.conn.Query(query, minLikes, pageSize, offset)
+    rows, err := db.conn.QueryContext(ctx, query, append(args, q.PageSize, offset)...)
     if err != nil {
         return nil, fmt.Errorf("failed to query posts: %w", err)
     }
     defer rows.Close()
 
-    var posts []*models.Post
-    for rows.Next() {
-        post := &models.Post{}
-        err := rows.Scan(
-            &post.ID, &post.GroupID, &post.GroupName, &post.PostID, &post.AuthorID,
-            &post.AuthorName, &post.Content, &post.PostURL, &post.Timestamp,
-            &post.Likes, &post.Comments, &post.Shares, &post.Images, &post.Videos,
-            &post.Links, &post.Hashtags, &post.Mentions, &post.PostType,
-            &post.ScrapedAt, &post.CreatedAt, &post.UpdatedAt, &post.MediaCount,
-        )
-        if err != nil {
-            return nil, fmt.Errorf("failed to scan post: %w", err)
-        }
-        posts = append(posts, post)
-    }
-
-    return posts, nil
+    return scanPosts(rows)
 }
 
 // GetPostsCount returns the total count of posts matching criteria
-func (db *DB) GetPostsCount(minLikes int) (int, error) {
+func (db *DB) GetPostsCount(ctx context.Context, q PostsQuery) (int, error) {
+    where, args := q.where()
     query := `
         SELECT COUNT(*) 
         FROM posts 
-        WHERE likes >= $1 
-            AND scraped_at >= NOW() - INTERVAL '5 days'`
+        ` + where
 
     var count int
-    err := db.conn.QueryRow(query, minLikes).Scan(&count)
+    err := db.conn.QueryRowContext(ctx, query, args...).Scan(&count)
     if err != nil {
         return 0, fmt.Errorf("failed to get posts count: %w", err)
     }
@@ -63,86 +163,103 @@ func (db *DB) GetPostsCount(minLikes int) (int, error) {
     return count, nil
 }
 
-// GetPostsForExport retrieves posts for CSV export
-func (db *DB) GetPostsForExport(minLikes int) ([]*models.Post, error) {
-    query := `
-        SELECT id, group_id, group_name, post_id, author_id, author_name, content,
-               post_url, timestamp, likes, comments, shares, post_type, scraped_at
+// ExportPosts opens a cursor over the posts for CSV and JSON export: those
+// with at least minLikes likes scraped in the past days days, most liked
+// first. Rows are scanned one at a time so exports don't hold every post in
+// memory; the caller must close the cursor.
+func (db *DB) ExportPosts(ctx context.Context, minLikes, days int) (*PostRows, error) {
+    query := fmt.Sprintf(`
+        SELECT %s
         FROM posts 
         WHERE likes >= $1 
-            AND scraped_at >= NOW() - INTERVAL '5 days'
-        ORDER BY likes DESC`
+            AND scraped_at >= $2
+        ORDER BY likes DESC`, postColumns)
 
-    rows, err := db.conn.Query(query, minLikes)
+    rows, err := db.conn.QueryContext(ctx, query, minLikes, time.Now().AddDate(0, 0, -days))
     if err != nil {
         return nil, fmt.Errorf("failed to query posts for export: %w", err)
     }
-    defer rows.Close()
 
-    var posts []*models.Post
-    for rows.Next() {
-        post := &models.Post{}
-        err := rows.Scan(
-            &post.ID, &post.GroupID, &post.GroupName, &post.PostID, &post.AuthorID,
-            &post.AuthorName, &post.Content, &post.PostURL, &post.Timestamp,
-            &post.Likes, &post.Comments, &post.Shares, &post.PostType, &post.ScrapedAt,
-        )
-        if err != nil {
-            return nil, fmt.Errorf("failed to scan post: %w", err)
-        }
-        posts = append(posts, post)
-    }
+    return &PostRows{rows: rows}, nil
+}
 
-    return posts, nil
+// PostRows iterates over posts selected with postColumns
+type PostRows struct {
+    rows *sql.Rows
 }
 
-// GetScrapingStats returns comprehensive scraping statistics
-func (db *DB) GetScrapingStats() (map[string]interface{}, error) {
+// Next advances to the next post, returning false when there are no more
+// or the query failed; check Err afterwards
+func (pr *PostRows) Next() bool {
+    return pr.rows.Next()
+}
+
+// Post scans the current row
+func (pr *PostRows) Post() (*models.Post, error) {
+    return scanPost(pr.rows)
+}
+
+func (pr *PostRows) Err() error {
+    return pr.rows.Err()
+}
+
+func (pr *PostRows) Close() error {
+    return pr.rows.Close()
+}
+
+// GetScrapingStats returns comprehensive scraping statistics. Windowed
+// figures cover posts scraped in the past days days, and high engagement
+// means at least minLikes likes.
+func (db *DB) GetScrapingStats(ctx context.Context, minLikes, days int) (map[string]interface{}, error) {
     stats := make(map[string]interface{})
+    since := time.Now().AddDate(0, 0, -days)
 
     // Total posts
     var totalPosts int
-    err := db.conn.QueryRow("SELECT COUNT(*) FROM posts").Scan(&totalPosts)
+    err := db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM posts").Scan(&totalPosts)
     if err != nil {
         return nil, fmt.Errorf("failed to get total posts: %w", err)
     }
     stats["total_posts"] = totalPosts
 
-    // High engagement posts (1000+ likes in past 5 days)
+    // High engagement posts in the window
     var highEngagementPosts int
-    err = db.conn.QueryRow(`
+    err = db.conn.QueryRowContext(ctx, `
         SELECT COUNT(*) FROM posts 
-        WHERE likes >= 1000 AND scraped_at >= NOW() - INTERVAL '5 days'
-    `).Scan(&highEngagementPosts)
+        WHERE likes >= $1 AND scraped_at >= $2
+    `, minLikes, since).Scan(&highEngagementPosts)
     if err != nil {
         return nil, fmt.Errorf("failed to get high engagement posts: %w", err)
     }
     stats["high_engagement_posts"] = highEngagementPosts
 
-    // Average likes
-    var avgLikes sql.NullFloat64
-    err = db.conn.QueryRow(`
-        SELECT AVG(likes) FROM posts 
-        WHERE scraped_at >= NOW() - INTERVAL '5 days'
-    `).Scan(&avgLikes)
+    // Average, median and 90th percentile likes; a few viral posts skew the
+    // average, so the median is the better picture of a typical post
+    var avgLikes, medianLikes, p90Likes sql.NullFloat64
+    err = db.conn.QueryRowContext(ctx, `
+        SELECT AVG(likes),
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY likes),
+               percentile_cont(0.9) WITHIN GROUP (ORDER BY likes)
+        FROM posts 
+        WHERE scraped_at >= $1
+    `, since).Scan(&avgLikes, &medianLikes, &p90Likes)
     if err != nil {
-        return nil, fmt.Errorf("failed to get average likes: %w", err)
-    }
-    if avgLikes.Valid {
-        stats["average_likes"] = avgLikes.Float64
-    } else {
-        stats["average_likes"] = 0
+        return nil, fmt.Errorf("failed to get likes distribution: %w", err)
     }
+    // All three are NULL when no posts were scraped in the window
+    stats["average_likes"] = avgLikes.Float64
+    stats["median_likes"] = medianLikes.Float64
+    stats["p90_likes"] = p90Likes.Float64
 
     // Top group by post count
     var topGroup sql.NullString
-    err = db.conn.QueryRow(`
+    err = db.conn.QueryRowContext(ctx, `
         SELECT group_name FROM posts 
-        WHERE scraped_at >= NOW() - INTERVAL '5 days'
+        WHERE scraped_at >= $1
         GROUP BY group_name 
         ORDER BY COUNT(*) DESC 
         LIMIT 1
-    `).Scan(&topGroup)
+    `, since).Scan(&topGroup)
     if err != nil && err != sql.ErrNoRows {
         return nil, fmt.Errorf("failed to get top group: %w", err)
     }
@@ -154,7 +271,7 @@ func (db *DB) GetScrapingStats() (map[string]interface{}, error) {
 
     // Last scraped timestamp
     var lastScraped sql.NullString
-    err = db.conn.QueryRow(`
+    err = db.conn.QueryRowContext(ctx, `
         SELECT MAX(scraped_at)::text FROM posts
     `).Scan(&lastScraped)
     if err != nil {
@@ -168,21 +285,21 @@ func (db *DB) GetScrapingStats() (map[string]interface{}, error) {
 
     // Number of groups scraped
     var groupsScraped int
-    err = db.conn.QueryRow(`
+    err = db.conn.QueryRowContext(ctx, `
         SELECT COUNT(DISTINCT group_id) FROM posts 
-        WHERE scraped_at >= NOW() - INTERVAL '5 days'
-    `).Scan(&groupsScraped)
+        WHERE scraped_at >= $1
+    `, since).Scan(&groupsScraped)
     if err != nil {
         return nil, fmt.Errorf("failed to get groups scraped: %w", err)
     }
     stats["groups_scraped"] = groupsScraped
 
     // Posts by type
-    rows, err := db.conn.Query(`
+    rows, err := db.conn.QueryContext(ctx, `
         SELECT post_type, COUNT(*) FROM posts 
-        WHERE scraped_at >= NOW() - INTERVAL '5 days'
+        WHERE scraped_at >= $1
         GROUP BY post_type
-    `)
+    `, since)
     if err != nil {
         return nil, fmt.Errorf("failed to get posts by type: %w", err)
     }
@@ -202,22 +319,37 @@ func (db *DB) GetScrapingStats() (map[string]interface{}, error) {
     return stats, nil
 }
 
+// GetPostTotals returns the number of stored posts and when the latest was
+// scraped, which is the zero time when there are none
+func (db *DB) GetPostTotals(ctx context.Context) (int, time.Time, error) {
+    var (
+        total       int
+        lastScraped sql.NullTime
+    )
+    err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*), MAX(scraped_at) FROM posts`).Scan(&total, &lastScraped)
+    if err != nil {
+        return 0, time.Time{}, fmt.Errorf("failed to get post totals: %w", err)
+    }
+    return total, lastScraped.Time, nil
+}
+
 // Ping checks if the database connection is alive
 func (db *DB) Ping() error {
     return db.conn.Ping()
 }
 
-// GetTopAuthors returns authors with most high-engagement posts
-func (db *DB) GetTopAuthors(limit int) ([]map[string]interface{}, error) {
+// GetTopAuthors returns authors with most high-engagement posts, those with
+// at least minLikes likes scraped in the past days days
+func (db *DB) GetTopAuthors(ctx context.Context, minLikes, days, limit int) ([]map[string]interface{}, error) {
     query := `
         SELECT author_name, COUNT(*) as post_count, AVG(likes) as avg_likes
         FROM posts 
-        WHERE likes >= 1000 AND scraped_at >= NOW() - INTERVAL '5 days'
+        WHERE likes >= $1 AND scraped_at >= $2
         GROUP BY author_name 
         ORDER BY post_count DESC, avg_likes DESC 
-        LIMIT $1`
+        LIMIT $3`
 
-    rows, err := db.conn.Query(query, limit)
+    rows, err := db.conn.QueryContext(ctx, query, minLikes, time.Now().AddDate(0, 0, -days), limit)
     if err != nil {
         return nil, fmt.Errorf("failed to query top authors: %w", err)
     }
@@ -245,7 +377,7 @@ func (db *DB) GetTopAuthors(limit int) ([]map[string]interface{}, error) {
 }
 
 // GetEngagementTrends returns engagement trends over time
-func (db *DB) GetEngagementTrends() ([]map[string]interface{}, error) {
+func (db *DB) GetEngagementTrends(ctx context.Context) ([]map[string]interface{}, error) {
     query := `
         SELECT 
             DATE(scraped_at) as date,
@@ -257,7 +389,7 @@ func (db *DB) GetEngagementTrends() ([]map[string]interface{}, error) {
         GROUP BY DATE(scraped_at)
         ORDER BY date DESC`
 
-    rows, err := db.conn.Query(query)
+    rows, err := db.conn.QueryContext(ctx, query)
     if err != nil {
         return nil, fmt.Errorf("failed to query engagement trends: %w", err)
     }
@@ -283,4 +415,255 @@ func (db *DB) GetEngagementTrends() ([]map[string]interface{}, error) {
     }
 
     return trends, nil
-}
\ No newline at end of file
+}
+// SearchPosts returns a page of posts matching the search criteria together
+// with the total number of matches. The content and author matches are served
+// by the trigram indexes of migration 014, the hashtag match by its GIN index.
+func (db *DB) SearchPosts(ctx context.Context, search PostSearch) ([]*models.Post, int, error) {
+    var (
+        conditions []string
+        args       []interface{}
+    )
+    addCondition := func(condition string, arg interface{}) {
+        args = append(args, arg)
+        conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+    }
+
+    if search.Query != "" {
+        addCondition("content ILIKE $%d", "%"+escapeLike(search.Query)+"%")
+    }
+    if search.Author != "" {
+        addCondition("author_name ILIKE $%d", "%"+escapeLike(search.Author)+"%")
+    }
+    if search.Hashtag != "" {
+        addCondition("hashtags @> ARRAY[$%d]", strings.TrimPrefix(search.Hashtag, "#"))
+    }
+    if search.Group != "" {
+        args = append(args, search.Group, "%"+escapeLike(search.Group)+"%")
+        conditions = append(conditions, fmt.Sprintf("(group_id = $%d OR group_name ILIKE $%d)", len(args)-1, len(args)))
+    }
+
+    where := ""
+    if len(conditions) > 0 {
+        where = "WHERE " + strings.Join(conditions, " AND ")
+    }
+
+    var total int
+    if err := db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM posts "+where, args...).Scan(&total); err != nil {
+        return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+    }
+
+    query := fmt.Sprintf(`
+        SELECT %s
+        FROM posts
+        %s
+        ORDER BY likes DESC, timestamp DESC
+        LIMIT $%d OFFSET $%d`, postColumns, where, len(args)+1, len(args)+2)
+    args = append(args, search.PageSize, (search.Page-1)*search.PageSize)
+
+    rows, err := db.conn.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to search posts: %w", err)
+    }
+    defer rows.Close()
+
+    posts, err := scanPosts(rows)
+    if err != nil {
+        return nil, 0, err
+    }
+    return posts, total, nil
+}
+
+// GetPostByPostID returns the stored post with the given Facebook post ID.
+// A post shared in several groups is stored once per group; the most
+// recently scraped copy is returned.
+func (db *DB) GetPostByPostID(ctx context.Context, postID string) (*models.Post, error) {
+    query := fmt.Sprintf(`SELECT %s FROM posts WHERE post_id = $1 ORDER BY scraped_at DESC LIMIT 1`, postColumns)
+
+    rows, err := db.conn.QueryContext(ctx, query, postID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query post: %w", err)
+    }
+    defer rows.Close()
+
+    posts, err := scanPosts(rows)
+    if err != nil {
+        return nil, err
+    }
+    if len(posts) == 0 {
+        return nil, ErrPostNotFound
+    }
+    return posts[0], nil
+}
+
+// GetRawPost returns the scraped JSON stored with a post, from its most
+// recently scraped copy. It is nil for posts saved before raw JSON was kept.
+func (db *DB) GetRawPost(ctx context.Context, postID string) (json.RawMessage, error) {
+    var raw []byte
+    err := db.conn.QueryRowContext(ctx, `
+        SELECT raw_json FROM posts
+        WHERE post_id = $1
+        ORDER BY scraped_at DESC
+        LIMIT 1`, postID).Scan(&raw)
+    if err == sql.ErrNoRows {
+        return nil, ErrPostNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to query raw post: %w", err)
+    }
+
+    return json.RawMessage(raw), nil
+}
+
+// GetEngagementHistory returns the engagement recorded by each scrape of a
+// post, oldest first, across every group the post was found in
+func (db *DB) GetEngagementHistory(ctx context.Context, postID string) ([]*models.EngagementSnapshot, error) {
+    rows, err := db.conn.QueryContext(ctx, `
+        SELECT group_id, post_id, likes, comments, shares, captured_at
+        FROM engagement_snapshots
+        WHERE post_id = $1
+        ORDER BY captured_at ASC`, postID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query engagement history: %w", err)
+    }
+    defer rows.Close()
+
+    var snapshots []*models.EngagementSnapshot
+    for rows.Next() {
+        snapshot := &models.EngagementSnapshot{}
+        if err := rows.Scan(&snapshot.GroupID, &snapshot.PostID, &snapshot.Likes, &snapshot.Comments,
+            &snapshot.Shares, &snapshot.CapturedAt); err != nil {
+            return nil, fmt.Errorf("failed to scan engagement snapshot: %w", err)
+        }
+        snapshots = append(snapshots, snapshot)
+    }
+
+    return snapshots, rows.Err()
+}
+
+// GetRecentErrors returns the latest recorded scrape errors, newest first,
+// optionally only those of one group or Page
+func (db *DB) GetRecentErrors(ctx context.Context, groupID string, limit int) ([]*models.ScrapeError, error) {
+    rows, err := db.conn.QueryContext(ctx, `
+        SELECT id, group_id, strategy, url, COALESCE(status_code, 0), error, occurred_at
+        FROM scrape_errors
+        WHERE $1 = '' OR group_id = $1
+        ORDER BY occurred_at DESC, id DESC
+        LIMIT $2`, groupID, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query scrape errors: %w", err)
+    }
+    defer rows.Close()
+
+    var scrapeErrors []*models.ScrapeError
+    for rows.Next() {
+        scrapeErr := &models.ScrapeError{}
+        if err := rows.Scan(&scrapeErr.ID, &scrapeErr.GroupID, &scrapeErr.Strategy, &scrapeErr.URL,
+            &scrapeErr.StatusCode, &scrapeErr.Error, &scrapeErr.OccurredAt); err != nil {
+            return nil, fmt.Errorf("failed to scan scrape error: %w", err)
+        }
+        scrapeErrors = append(scrapeErrors, scrapeErr)
+    }
+
+    return scrapeErrors, rows.Err()
+}
+
+// GetGroups lists every group or Page with stored posts, most posts first.
+// The name is the one seen by the latest scrape.
+func (db *DB) GetGroups(ctx context.Context) ([]*models.GroupSummary, error) {
+    rows, err := db.conn.QueryContext(ctx, `
+        SELECT group_id,
+            COALESCE((ARRAY_AGG(group_name ORDER BY scraped_at DESC))[1], '') AS group_name,
+            COUNT(*) AS post_count,
+            MAX(scraped_at) AS last_scraped
+        FROM posts
+        GROUP BY group_id
+        ORDER BY post_count DESC, group_id`)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query groups: %w", err)
+    }
+    defer rows.Close()
+
+    var groups []*models.GroupSummary
+    for rows.Next() {
+        group := &models.GroupSummary{}
+        if err := rows.Scan(&group.GroupID, &group.GroupName, &group.PostCount, &group.LastScraped); err != nil {
+            return nil, fmt.Errorf("failed to scan group: %w", err)
+        }
+        groups = append(groups, group)
+    }
+
+    return groups, rows.Err()
+}
+
+// GetStatsByGroup returns the per-group counterpart of GetScrapingStats, with
+// the groups that have the most high-engagement posts first. As there, high
+// engagement means at least minLikes likes on a post scraped in the past days
+// days; the other figures cover every stored post.
+func (db *DB) GetStatsByGroup(ctx context.Context, minLikes, days int) ([]*models.GroupStats, error) {
+    since := time.Now().AddDate(0, 0, -days)
+
+    rows, err := db.conn.QueryContext(ctx, `
+        SELECT group_id,
+            COALESCE((ARRAY_AGG(group_name ORDER BY scraped_at DESC))[1], '') AS group_name,
+            COUNT(*) AS post_count,
+            AVG(likes)::float8 AS average_likes,
+            COUNT(*) FILTER (WHERE likes >= $1 AND scraped_at >= $2) AS high_engagement_posts,
+            MAX(scraped_at) AS last_scraped
+        FROM posts
+        GROUP BY group_id
+        ORDER BY high_engagement_posts DESC, post_count DESC, group_id`, minLikes, since)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query group stats: %w", err)
+    }
+    defer rows.Close()
+
+    var groups []*models.GroupStats
+    for rows.Next() {
+        group := &models.GroupStats{}
+        if err := rows.Scan(&group.GroupID, &group.GroupName, &group.PostCount, &group.AverageLikes,
+            &group.HighEngagementPosts, &group.LastScraped); err != nil {
+            return nil, fmt.Errorf("failed to scan group stats: %w", err)
+        }
+        groups = append(groups, group)
+    }
+
+    return groups, rows.Err()
+}
+
+// scanPosts reads rows selected with postColumns
+func scanPosts(rows *sql.Rows) ([]*models.Post, error) {
+    var posts []*models.Post
+    for rows.Next() {
+        post, err := scanPost(rows)
+        if err != nil {
+            return nil, err
+        }
+        posts = append(posts, post)
+    }
+
+    return posts, rows.Err()
+}
+
+// scanPost scans the current row of a query selecting postColumns
+func scanPost(rows *sql.Rows) (*models.Post, error) {
+    post := &models.Post{}
+    err := rows.Scan(
+        &post.ID, &post.GroupID, &post.GroupName, &post.PostID, &post.AuthorID,
+        &post.AuthorName, &post.Content, &post.PostURL, &post.Timestamp,
+        &post.Likes, &post.Comments, &post.Shares, &post.Images, &post.Videos,
+        pq.Array(&post.Links), pq.Array(&post.Hashtags), pq.Array(&post.Mentions), &post.PostType,
+        &post.ScrapedAt, &post.CreatedAt, &post.UpdatedAt, &post.MediaCount,
+        &post.ReactionBreakdown, &post.SourceType, &post.IsSponsored, &post.Language,
+        &post.LinkPreview, &post.MentionLinks, &post.ContentHash,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("failed to scan post: %w", err)
+    }
+    return post, nil
+}
+
+// escapeLike escapes the ILIKE wildcards in user input
+func escapeLike(s string) string {
+    return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
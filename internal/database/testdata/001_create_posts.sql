@@ -0,0 +1,26 @@
+-- The posts table as it stood before migrations/002; the tests build it first
+-- and then run the real migrations on top
+CREATE TABLE IF NOT EXISTS posts (
+    id SERIAL PRIMARY KEY,
+    group_id VARCHAR(255) NOT NULL,
+    group_name VARCHAR(255),
+    post_id VARCHAR(255) UNIQUE NOT NULL,
+    author_name VARCHAR(255),
+    author_id VARCHAR(255),
+    content TEXT,
+    post_url TEXT,
+    timestamp TIMESTAMP,
+    likes INTEGER DEFAULT 0,
+    comments INTEGER DEFAULT 0,
+    shares INTEGER DEFAULT 0,
+    post_type VARCHAR(50),
+    images JSONB DEFAULT '[]'::jsonb,
+    videos JSONB DEFAULT '[]'::jsonb,
+    mentions TEXT[],
+    hashtags TEXT[],
+    links TEXT[],
+    media_count INTEGER DEFAULT 0,
+    scraped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -4,6 +4,8 @@ import (
     "encoding/json"
     "fmt"
     "os"
+    "sort"
+    "sync"
     "time"
 
     "github.com/sirupsen/logrus"
@@ -16,23 +18,99 @@ type Metrics struct {
     FailedPosts     int                    `json:"failed_posts"`
     LastRun         time.Time              `json:"last_run"`
     AverageRunTime  time.Duration          `json:"average_run_time"`
+    TotalRunTime    time.Duration          `json:"total_run_time"`
     ErrorRate       float64                `json:"error_rate"`
     GroupMetrics    map[string]GroupMetric `json:"group_metrics"`
+    RunsHistory     []RunRecord            `json:"runs_history"` // most recent MaxRunHistory runs, oldest first
+    Cycles          int                    `json:"cycles"`       // full passes over all groups
+    LastCycle       CycleRecord            `json:"last_cycle"`
+}
+
+// MaxRunHistory caps how many runs are kept in the metrics file
+const MaxRunHistory = 500
+
+// RunRecord is one scraping run in the metrics history
+type RunRecord struct {
+    Timestamp time.Time     `json:"timestamp"`
+    Group     string        `json:"group"`
+    Posts     int           `json:"posts"`
+    Duration  time.Duration `json:"duration"`
+    Errors    int           `json:"errors"`
+    Failed    bool          `json:"failed,omitempty"` // the run aborted before scraping posts
+}
+
+// CycleRecord is one full pass of the scraper over all groups and searches
+type CycleRecord struct {
+    Started      time.Time     `json:"started"`
+    Duration     time.Duration `json:"duration"`
+    Posts        int           `json:"posts"`
+    FailedGroups int           `json:"failed_groups"`
 }
 
 type GroupMetric struct {
     PostsScraped   int           `json:"posts_scraped"`
     LastScraped    time.Time     `json:"last_scraped"`
+    Runs           int           `json:"runs"`
     AverageRunTime time.Duration `json:"average_run_time"`
+    TotalRunTime   time.Duration `json:"total_run_time"`
     ErrorCount     int           `json:"error_count"`
+    EmptyRuns      int           `json:"consecutive_empty_runs"` // runs in a row that produced no posts
 }
 
+// ErrorRate is the percentage of the group's scraped posts that failed
+func (gm GroupMetric) ErrorRate() float64 {
+    if gm.PostsScraped == 0 {
+        return 0
+    }
+    return float64(gm.ErrorCount) / float64(gm.PostsScraped) * 100
+}
+
+// recordRun adds a run's duration and updates the mean run time
+func (gm *GroupMetric) recordRun(duration time.Duration) {
+    gm.Runs++
+    gm.TotalRunTime += duration
+    gm.AverageRunTime = gm.TotalRunTime / time.Duration(gm.Runs)
+}
+
+// clone returns a deep copy so callers can read it while runs are recorded
+func (m *Metrics) clone() *Metrics {
+    c := *m
+    c.GroupMetrics = make(map[string]GroupMetric, len(m.GroupMetrics))
+    for groupID, gm := range m.GroupMetrics {
+        c.GroupMetrics[groupID] = gm
+    }
+    c.RunsHistory = append([]RunRecord(nil), m.RunsHistory...)
+    return &c
+}
+
+// recordHistory appends a run, dropping the oldest beyond MaxRunHistory
+func (m *Metrics) recordHistory(run RunRecord) {
+    m.RunsHistory = append(m.RunsHistory, run)
+    if len(m.RunsHistory) > MaxRunHistory {
+        m.RunsHistory = append([]RunRecord(nil), m.RunsHistory[len(m.RunsHistory)-MaxRunHistory:]...)
+    }
+}
+
+// Monitor is safe for concurrent use by scraper workers
 type Monitor struct {
-    metrics    *Metrics
-    logger     *logrus.Logger
+    mu          sync.Mutex // guards metrics and seq
+    metrics     *Metrics
+    seq         uint64 // bumped on every update, orders snapshots
+    logger      *logrus.Logger
     metricsFile string
+    fileMu      sync.Mutex // guards writes to metricsFile and saved
+    saved       uint64     // seq of the snapshot last written
+}
+
+// metricsSnapshot is the encoded metrics at one update, written to the
+// metrics file after m.mu is released
+type metricsSnapshot struct {
+    data []byte
+    seq  uint64
 }
 
+// NewMonitor loads the metrics in metricsFile and saves every update back to
+// it. An empty metricsFile keeps the metrics in memory only.
 func NewMonitor(logger *logrus.Logger, metricsFile string) *Monitor {
     monitor := &Monitor{
         metrics: &Metrics{
@@ -48,6 +126,8 @@ func NewMonitor(logger *logrus.Logger, metricsFile string) *Monitor {
 }
 
 func (m *Monitor) RecordScrapingRun(groupID string, postsScraped int, duration time.Duration, errors int) {
+    m.mu.Lock()
+
     m.metrics.ScrapingRuns++
     m.metrics.TotalPosts += postsScraped
     m.metrics.SuccessfulPosts += postsScraped - errors
@@ -55,11 +135,8 @@ func (m *Monitor) RecordScrapingRun(groupID string, postsScraped int, duration t
     m.metrics.LastRun = time.Now()
 
     // Update average run time
-    if m.metrics.ScrapingRuns > 1 {
-        m.metrics.AverageRunTime = (m.metrics.AverageRunTime + duration) / 2
-    } else {
-        m.metrics.AverageRunTime = duration
-    }
+    m.metrics.TotalRunTime += duration
+    m.metrics.AverageRunTime = m.metrics.TotalRunTime / time.Duration(m.metrics.ScrapingRuns)
 
     // Calculate error rate
     if m.metrics.TotalPosts > 0 {
@@ -71,43 +148,126 @@ func (m *Monitor) RecordScrapingRun(groupID string, postsScraped int, duration t
     groupMetric.PostsScraped += postsScraped
     groupMetric.LastScraped = time.Now()
     groupMetric.ErrorCount += errors
-    
-    if groupMetric.AverageRunTime == 0 {
-        groupMetric.AverageRunTime = duration
+    groupMetric.recordRun(duration)
+    if postsScraped > 0 {
+        groupMetric.EmptyRuns = 0
     } else {
-        groupMetric.AverageRunTime = (groupMetric.AverageRunTime + duration) / 2
+        groupMetric.EmptyRuns++
     }
     
     m.metrics.GroupMetrics[groupID] = groupMetric
+    m.metrics.recordHistory(RunRecord{
+        Timestamp: time.Now(),
+        Group:     groupID,
+        Posts:     postsScraped,
+        Duration:  duration,
+        Errors:    errors,
+    })
+    snapshot := m.snapshot()
+    m.mu.Unlock()
 
     // Save metrics
-    m.saveMetrics()
+    m.saveMetrics(snapshot)
 
     m.logger.Infof("Recorded scraping run for group %s: %d posts, %v duration, %d errors", 
         groupID, postsScraped, duration, errors)
 }
 
+// RecordScrapingFailure records a run that failed before any posts were scraped
+func (m *Monitor) RecordScrapingFailure(groupID string, duration time.Duration) {
+    m.mu.Lock()
+
+    m.metrics.ScrapingRuns++
+    m.metrics.LastRun = time.Now()
+    m.metrics.TotalRunTime += duration
+    m.metrics.AverageRunTime = m.metrics.TotalRunTime / time.Duration(m.metrics.ScrapingRuns)
+
+    groupMetric := m.metrics.GroupMetrics[groupID]
+    groupMetric.LastScraped = time.Now()
+    groupMetric.ErrorCount++
+    groupMetric.recordRun(duration)
+    groupMetric.EmptyRuns++
+    m.metrics.GroupMetrics[groupID] = groupMetric
+    m.metrics.recordHistory(RunRecord{
+        Timestamp: time.Now(),
+        Group:     groupID,
+        Duration:  duration,
+        Errors:    1,
+        Failed:    true,
+    })
+    snapshot := m.snapshot()
+    m.mu.Unlock()
+
+    m.saveMetrics(snapshot)
+
+    m.logger.Warnf("Recorded failed scraping run for group %s after %v", groupID, duration)
+}
+
+// RecordCycle records a completed pass over all groups. The groups themselves
+// are recorded with RecordScrapingRun and RecordScrapingFailure.
+func (m *Monitor) RecordCycle(cycle CycleRecord) {
+    m.mu.Lock()
+    m.metrics.Cycles++
+    m.metrics.LastCycle = cycle
+    cycles := m.metrics.Cycles
+    snapshot := m.snapshot()
+    m.mu.Unlock()
+
+    m.saveMetrics(snapshot)
+
+    m.logger.Infof("Recorded scraping cycle %d: %d posts, %d failed groups, %v duration",
+        cycles, cycle.Posts, cycle.FailedGroups, cycle.Duration)
+}
+
+// GetMetrics returns a snapshot of the metrics
 func (m *Monitor) GetMetrics() *Metrics {
-    return m.metrics
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.metrics.clone()
+}
+
+// Reset clears all counters, group metrics and run history and rewrites the
+// metrics file
+func (m *Monitor) Reset() {
+    m.mu.Lock()
+    old := m.metrics
+    m.metrics = &Metrics{
+        GroupMetrics: make(map[string]GroupMetric),
+    }
+    snapshot := m.snapshot()
+    m.mu.Unlock()
+
+    m.saveMetrics(snapshot)
+
+    m.logger.Infof("Reset metrics: %d runs, %d posts (%d failed), %d groups and %d history entries cleared",
+        old.ScrapingRuns, old.TotalPosts, old.FailedPosts, len(old.GroupMetrics), len(old.RunsHistory))
+}
+
+// GetHistory returns the recorded runs, oldest first
+func (m *Monitor) GetHistory() []RunRecord {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return append([]RunRecord(nil), m.metrics.RunsHistory...)
 }
 
 func (m *Monitor) GetHealthStatus() map[string]interface{} {
+    metrics := m.GetMetrics()
     status := map[string]interface{}{
         "status":           "healthy",
-        "last_run":         m.metrics.LastRun.Format(time.RFC3339),
-        "total_runs":       m.metrics.ScrapingRuns,
-        "error_rate":       fmt.Sprintf("%.2f%%", m.metrics.ErrorRate),
-        "average_runtime":  m.metrics.AverageRunTime.String(),
+        "last_run":         metrics.LastRun.Format(time.RFC3339),
+        "total_runs":       metrics.ScrapingRuns,
+        "error_rate":       fmt.Sprintf("%.2f%%", metrics.ErrorRate),
+        "average_runtime":  metrics.AverageRunTime.String(),
     }
 
     // Check if last run was too long ago
-    if time.Since(m.metrics.LastRun) > 24*time.Hour {
+    if time.Since(metrics.LastRun) > 24*time.Hour {
         status["status"] = "warning"
         status["warning"] = "No scraping runs in the last 24 hours"
     }
 
     // Check error rate
-    if m.metrics.ErrorRate > 10 {
+    if metrics.ErrorRate > 10 {
         status["status"] = "warning"
         status["warning"] = "High error rate detected"
     }
@@ -116,6 +276,7 @@ func (m *Monitor) GetHealthStatus() map[string]interface{} {
 }
 
 func (m *Monitor) GenerateReport() string {
+    metrics := m.GetMetrics()
     report := fmt.Sprintf(`
 Facebook Scraper Monitoring Report
 ==================================
@@ -129,20 +290,22 @@ Overall Statistics:
 - Error Rate: %.2f%%
 - Average Run Time: %s
 - Last Run: %s
+- Scraping Cycles: %d
 
 Group Performance:
 `, 
         time.Now().Format("2006-01-02 15:04:05"),
-        m.metrics.ScrapingRuns,
-        m.metrics.TotalPosts,
-        m.metrics.SuccessfulPosts,
-        m.metrics.FailedPosts,
-        m.metrics.ErrorRate,
-        m.metrics.AverageRunTime,
-        m.metrics.LastRun.Format("2006-01-02 15:04:05"),
+        metrics.ScrapingRuns,
+        metrics.TotalPosts,
+        metrics.SuccessfulPosts,
+        metrics.FailedPosts,
+        metrics.ErrorRate,
+        metrics.AverageRunTime,
+        metrics.LastRun.Format("2006-01-02 15:04:05"),
+        metrics.Cycles,
     )
 
-    for groupID, metric := range m.metrics.GroupMetrics {
+    for groupID, metric := range metrics.GroupMetrics {
         report += fmt.Sprintf(`
 - Group %s:
   Posts Scraped: %d
@@ -162,42 +325,111 @@ Group Performance:
 }
 
 func (m *Monitor) loadMetrics() {
+    if m.metricsFile == "" {
+        return
+    }
     if _, err := os.Stat(m.metricsFile); os.IsNotExist(err) {
         m.logger.Info("No existing metrics file found, starting fresh")
         return
     }
 
-    data, err := os.ReadFile(m.metricsFile)
+    metrics, err := ReadMetrics(m.metricsFile)
     if err != nil {
-        m.logger.Warnf("Failed to read metrics file: %v", err)
+        m.logger.Warnf("Failed to load metrics: %v", err)
         return
     }
+    m.metrics = metrics
 
-    if err := json.Unmarshal(data, m.metrics); err != nil {
-        m.logger.Warnf("Failed to parse metrics file: %v", err)
-        return
+    m.logger.Info("Loaded existing metrics from file")
+}
+
+// ReadMetrics loads the metrics saved by a scraper's Monitor, so other
+// processes such as the API can report the same numbers
+func ReadMetrics(metricsFile string) (*Metrics, error) {
+    data, err := os.ReadFile(metricsFile)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read metrics file: %w", err)
     }
 
-    m.logger.Info("Loaded existing metrics from file")
+    metrics := &Metrics{}
+    if err := json.Unmarshal(data, metrics); err != nil {
+        return nil, fmt.Errorf("failed to parse metrics file: %w", err)
+    }
+    if metrics.GroupMetrics == nil {
+        metrics.GroupMetrics = make(map[string]GroupMetric)
+    }
+
+    // Files written before totals were tracked only have the averages;
+    // seed the totals from them so the mean carries on from there
+    if metrics.TotalRunTime == 0 && metrics.ScrapingRuns > 0 {
+        metrics.TotalRunTime = metrics.AverageRunTime * time.Duration(metrics.ScrapingRuns)
+    }
+    for groupID, gm := range metrics.GroupMetrics {
+        if gm.Runs == 0 && gm.AverageRunTime > 0 {
+            gm.Runs = 1
+            gm.TotalRunTime = gm.AverageRunTime
+            metrics.GroupMetrics[groupID] = gm
+        }
+    }
+
+    return metrics, nil
 }
 
-func (m *Monitor) saveMetrics() {
+// snapshot encodes the metrics for saveMetrics, or returns nil when they are
+// kept in memory only; callers must hold m.mu
+func (m *Monitor) snapshot() *metricsSnapshot {
+    if m.metricsFile == "" {
+        return nil
+    }
     data, err := json.MarshalIndent(m.metrics, "", "  ")
     if err != nil {
         m.logger.Errorf("Failed to marshal metrics: %v", err)
+        return nil
+    }
+    m.seq++
+    return &metricsSnapshot{data: data, seq: m.seq}
+}
+
+// saveMetrics writes a snapshot to the metrics file without holding m.mu, so
+// workers recording runs don't wait on the disk. Snapshots older than the one
+// already written are dropped.
+func (m *Monitor) saveMetrics(snapshot *metricsSnapshot) {
+    if snapshot == nil {
+        return
+    }
+
+    m.fileMu.Lock()
+    defer m.fileMu.Unlock()
+    if snapshot.seq <= m.saved {
         return
     }
 
-    if err := os.WriteFile(m.metricsFile, data, 0644); err != nil {
+    if err := os.WriteFile(m.metricsFile, snapshot.data, 0644); err != nil {
         m.logger.Errorf("Failed to save metrics: %v", err)
         return
     }
+    m.saved = snapshot.seq
 }
 
 // AlertManager handles alerting based on metrics
 type AlertManager struct {
-    monitor *Monitor
-    logger  *logrus.Logger
+    monitor  *Monitor
+    logger   *logrus.Logger
+    webhooks []Webhook
+    groups   map[string]GroupThresholds
+}
+
+// GroupThresholds override the global alert checks for one group; zero
+// fields are not checked
+type GroupThresholds struct {
+    MaxHoursSinceScrape int     // alert when the group hasn't been scraped for longer
+    MaxErrorRate        float64 // percent of the group's posts that failed
+    MaxEmptyRuns        int     // alert after this many runs in a row without posts
+}
+
+// SetGroupThresholds adds per-group checks, keyed by group ID
+func (am *AlertManager) SetGroupThresholds(thresholds map[string]GroupThresholds) {
+    am.groups = thresholds
 }
 
 func NewAlertManager(monitor *Monitor, logger *logrus.Logger) *AlertManager {
@@ -226,13 +458,48 @@ func (am *AlertManager) CheckAlerts() []string {
         alerts = append(alerts, "ALERT: No posts have been scraped")
     }
 
+    groupIDs := make([]string, 0, len(am.groups))
+    for groupID := range am.groups {
+        groupIDs = append(groupIDs, groupID)
+    }
+    sort.Strings(groupIDs)
+    for _, groupID := range groupIDs {
+        alerts = append(alerts, checkGroup(groupID, metrics.GroupMetrics[groupID], am.groups[groupID])...)
+    }
+
+    return alerts
+}
+
+func checkGroup(groupID string, metric GroupMetric, thresholds GroupThresholds) []string {
+    var alerts []string
+
+    if thresholds.MaxHoursSinceScrape > 0 {
+        limit := time.Duration(thresholds.MaxHoursSinceScrape) * time.Hour
+        if metric.LastScraped.IsZero() {
+            alerts = append(alerts, fmt.Sprintf("ALERT: Group %s has never been scraped", groupID))
+        } else if time.Since(metric.LastScraped) > limit {
+            alerts = append(alerts, fmt.Sprintf("ALERT: Group %s hasn't been scraped in over %d hours",
+                groupID, thresholds.MaxHoursSinceScrape))
+        }
+    }
+
+    if thresholds.MaxErrorRate > 0 && metric.ErrorRate() > thresholds.MaxErrorRate {
+        alerts = append(alerts, fmt.Sprintf("ALERT: Group %s has a high error rate: %.2f%%", groupID, metric.ErrorRate()))
+    }
+
+    if thresholds.MaxEmptyRuns > 0 && metric.EmptyRuns >= thresholds.MaxEmptyRuns {
+        alerts = append(alerts, fmt.Sprintf("ALERT: Group %s produced no posts in its last %d runs", groupID, metric.EmptyRuns))
+    }
+
     return alerts
 }
 
+// SendAlerts logs the alerts and forwards them to the configured webhooks
 func (am *AlertManager) SendAlerts(alerts []string) {
     for _, alert := range alerts {
         am.logger.Warn(alert)
-        // Here you could integrate with external alerting systems
-        // like Slack, email, PagerDuty, etc.
+    }
+    if len(alerts) > 0 && len(am.webhooks) > 0 {
+        am.notifyWebhooks(alerts)
     }
 }
\ No newline at end of file
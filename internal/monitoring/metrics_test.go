@@ -0,0 +1,91 @@
+package monitoring
+
+import (
+    "fmt"
+    "io"
+    "path/filepath"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+func newTestMonitor(t *testing.T, metricsFile string) *Monitor {
+    t.Helper()
+
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return NewMonitor(logger, metricsFile)
+}
+
+func TestRecordScrapingRunAverageRunTime(t *testing.T) {
+    monitor := newTestMonitor(t, "")
+    for _, duration := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+        monitor.RecordScrapingRun("group", 10, duration, 0)
+    }
+
+    metrics := monitor.GetMetrics()
+    if metrics.AverageRunTime != 2*time.Second {
+        t.Errorf("AverageRunTime = %v, want 2s", metrics.AverageRunTime)
+    }
+    if metrics.TotalRunTime != 6*time.Second {
+        t.Errorf("TotalRunTime = %v, want 6s", metrics.TotalRunTime)
+    }
+    if gm := metrics.GroupMetrics["group"]; gm.AverageRunTime != 2*time.Second || gm.Runs != 3 {
+        t.Errorf("group AverageRunTime = %v over %d runs, want 2s over 3", gm.AverageRunTime, gm.Runs)
+    }
+}
+
+func TestRecordScrapingFailureCountsTowardsAverage(t *testing.T) {
+    monitor := newTestMonitor(t, "")
+    monitor.RecordScrapingRun("group", 10, time.Second, 0)
+    monitor.RecordScrapingFailure("group", 3*time.Second)
+
+    metrics := monitor.GetMetrics()
+    if metrics.AverageRunTime != 2*time.Second {
+        t.Errorf("AverageRunTime = %v, want 2s", metrics.AverageRunTime)
+    }
+    if gm := metrics.GroupMetrics["group"]; gm.ErrorCount != 1 || gm.EmptyRuns != 1 {
+        t.Errorf("group ErrorCount = %d, EmptyRuns = %d, want 1 and 1", gm.ErrorCount, gm.EmptyRuns)
+    }
+}
+
+// Run with -race: workers record runs while others read the metrics, and the
+// metrics file must end up with the latest snapshot
+func TestMonitorConcurrentRecordAndRead(t *testing.T) {
+    metricsFile := filepath.Join(t.TempDir(), "metrics.json")
+    monitor := newTestMonitor(t, metricsFile)
+
+    const workers, runs = 8, 25
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        wg.Add(2)
+        go func(group string) {
+            defer wg.Done()
+            for i := 0; i < runs; i++ {
+                monitor.RecordScrapingRun(group, 1, time.Millisecond, 0)
+            }
+        }(fmt.Sprintf("group-%d", w))
+        go func() {
+            defer wg.Done()
+            for i := 0; i < runs; i++ {
+                metrics := monitor.GetMetrics()
+                _ = len(metrics.GroupMetrics) + len(metrics.RunsHistory)
+                _ = monitor.GetHistory()
+            }
+        }()
+    }
+    wg.Wait()
+
+    if got := monitor.GetMetrics().ScrapingRuns; got != workers*runs {
+        t.Fatalf("ScrapingRuns = %d, want %d", got, workers*runs)
+    }
+    saved, err := ReadMetrics(metricsFile)
+    if err != nil {
+        t.Fatalf("ReadMetrics: %v", err)
+    }
+    if saved.ScrapingRuns != workers*runs || len(saved.GroupMetrics) != workers {
+        t.Errorf("saved %d runs over %d groups, want %d over %d", saved.ScrapingRuns, len(saved.GroupMetrics), workers*runs, workers)
+    }
+}
@@ -0,0 +1,73 @@
+package monitoring
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "time"
+)
+
+// webhookTimeout bounds each delivery attempt
+const webhookTimeout = 10 * time.Second
+
+// Webhook is an HTTP endpoint alerts are POSTed to as JSON
+type Webhook struct {
+    URL     string
+    Headers map[string]string // e.g. an Authorization header
+}
+
+// webhookPayload is the body sent to every webhook
+type webhookPayload struct {
+    Alerts    []string  `json:"alerts"`
+    Timestamp time.Time `json:"timestamp"`
+}
+
+// SetWebhooks forwards alerts sent with SendAlerts to the given endpoints
+func (am *AlertManager) SetWebhooks(webhooks []Webhook) {
+    am.webhooks = webhooks
+}
+
+// notifyWebhooks posts the alerts as one batch to every webhook, retrying
+// each once on failure
+func (am *AlertManager) notifyWebhooks(alerts []string) {
+    body, err := json.Marshal(webhookPayload{Alerts: alerts, Timestamp: time.Now()})
+    if err != nil {
+        am.logger.Errorf("Failed to encode alerts: %v", err)
+        return
+    }
+
+    client := &http.Client{Timeout: webhookTimeout}
+    for _, webhook := range am.webhooks {
+        err := postWebhook(client, webhook, body)
+        if err != nil {
+            am.logger.Warnf("Alert webhook %s failed, retrying: %v", webhook.URL, err)
+            err = postWebhook(client, webhook, body)
+        }
+        if err != nil {
+            am.logger.Errorf("Failed to deliver alerts to %s: %v", webhook.URL, err)
+        }
+    }
+}
+
+func postWebhook(client *http.Client, webhook Webhook, body []byte) error {
+    req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    for name, value := range webhook.Headers {
+        req.Header.Set(name, value)
+    }
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return fmt.Errorf("unexpected status %d", resp.StatusCode)
+    }
+    return nil
+}
@@ -0,0 +1,128 @@
+package scraper
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/cookiejar"
+    "net/url"
+    "time"
+)
+
+// DefaultAccountCooldown is how long an account is skipped after being
+// rate limited or checkpointed
+const DefaultAccountCooldown = 30 * time.Minute
+
+// ErrNoAccountsAvailable is returned when every account is cooling down
+var ErrNoAccountsAvailable = errors.New("all Facebook accounts are cooling down")
+
+// account is one logged-in Facebook session backed by its own cookies file
+type account struct {
+    cookiesFile   string
+    format        cookieFormat
+    jar           *cookiejar.Jar
+    client        *http.Client
+    userID        string
+    cooldownUntil time.Time
+}
+
+func (acc *account) label() string {
+    if acc.userID != "" {
+        return "c_user=" + acc.userID
+    }
+    return acc.cookiesFile
+}
+
+// accountJar forwards cookie handling to whichever account is active
+type accountJar struct {
+    am *AuthManager
+}
+
+func (j *accountJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+    j.am.activeAccount().jar.SetCookies(u, cookies)
+}
+
+func (j *accountJar) Cookies(u *url.URL) []*http.Cookie {
+    return j.am.activeAccount().jar.Cookies(u)
+}
+
+func (am *AuthManager) addAccount(cookiesFile string) error {
+    jar, err := cookiejar.New(nil)
+    if err != nil {
+        return fmt.Errorf("failed to create cookie jar: %w", err)
+    }
+
+    am.accounts = append(am.accounts, &account{
+        cookiesFile: cookiesFile,
+        jar:         jar,
+        client: &http.Client{
+            Jar:       jar,
+            Timeout:   30 * time.Second,
+            Transport: am.transport,
+        },
+    })
+    return nil
+}
+
+// AddAccounts registers additional cookies files, one per Facebook account,
+// to rotate through when the active account is rate limited or checkpointed
+func (am *AuthManager) AddAccounts(cookiesFiles []string, cooldown time.Duration) error {
+    known := make(map[string]bool)
+    for _, acc := range am.accounts {
+        known[acc.cookiesFile] = true
+    }
+
+    for _, file := range cookiesFiles {
+        if known[file] {
+            continue
+        }
+        if err := am.addAccount(file); err != nil {
+            return err
+        }
+        known[file] = true
+    }
+
+    if cooldown > 0 {
+        am.cooldown = cooldown
+    }
+    return nil
+}
+
+func (am *AuthManager) activeAccount() *account {
+    am.mu.Lock()
+    defer am.mu.Unlock()
+    return am.accounts[am.active]
+}
+
+func (am *AuthManager) accountAvailable(acc *account) bool {
+    am.mu.Lock()
+    defer am.mu.Unlock()
+    return time.Now().After(acc.cooldownUntil)
+}
+
+// ActiveUserID returns the c_user of the account currently in use
+func (am *AuthManager) ActiveUserID() string {
+    return am.activeAccount().userID
+}
+
+// RotateAccount puts the active account on cooldown and switches to the next
+// account that isn't cooling down, returning its c_user
+func (am *AuthManager) RotateAccount(reason string) (string, error) {
+    am.mu.Lock()
+    defer am.mu.Unlock()
+
+    current := am.accounts[am.active]
+    current.cooldownUntil = time.Now().Add(am.cooldown)
+    am.logger.Warnf("Account %s cooling down for %v: %s", current.label(), am.cooldown, reason)
+
+    for i := 1; i <= len(am.accounts); i++ {
+        next := (am.active + i) % len(am.accounts)
+        if time.Now().After(am.accounts[next].cooldownUntil) {
+            am.active = next
+            am.logger.Infof("Switched to Facebook account %s", am.accounts[next].label())
+            return am.accounts[next].userID, nil
+        }
+    }
+
+    return "", ErrNoAccountsAvailable
+}
@@ -3,15 +3,18 @@ package scraper
 
 import (
     "encoding/json"
+    "errors"
     "fmt"
     "io/ioutil"
     "net/http"
-    "net/http/cookiejar"
     "net/url"
     "os"
+    "sync"
+    "sync/atomic"
     "time"
     "strings"
 
+    "github.com/PuerkitoBio/goquery"
     "github.com/sirupsen/logrus"
 )
 
@@ -30,60 +33,78 @@ type CookieStore struct {
 }
 
 type AuthManager struct {
-    client      *http.Client
-    cookieJar   *cookiejar.Jar
-    cookiesFile string
-    userAgent   string
-    logger      *logrus.Logger
+    client    *http.Client
+    transport *http.Transport
+    accounts  []*account
+    active    int
+    cooldown  time.Duration
+    mu        sync.Mutex
+    userAgent string
+    logger    *logrus.Logger
 }
 
 func NewAuthManager(cookiesFile, userAgent string, logger *logrus.Logger) (*AuthManager, error) {
-    jar, err := cookiejar.New(nil)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create cookie jar: %w", err)
-    }
-
-    client := &http.Client{
-        Jar:     jar,
-        Timeout: 30 * time.Second,
-        Transport: &http.Transport{
-            MaxIdleConns:        10,
-            IdleConnTimeout:     30 * time.Second,
-            DisableCompression:  false,
-            TLSHandshakeTimeout: 10 * time.Second,
-        },
+    transport := &http.Transport{
+        MaxIdleConns:        10,
+        IdleConnTimeout:     30 * time.Second,
+        DisableCompression:  false,
+        TLSHandshakeTimeout: 10 * time.Second,
     }
 
-    return &AuthManager{
-        client:      client,
-        cookieJar:   jar,
-        cookiesFile: cookiesFile,
+    am := &AuthManager{
+        transport:   transport,
+        cooldown:    DefaultAccountCooldown,
         userAgent:   userAgent,
         logger:      logger,
-    }, nil
+    }
+
+    if err := am.addAccount(cookiesFile); err != nil {
+        return nil, err
+    }
+
+    // The shared client always talks through the active account's cookie jar
+    am.client = &http.Client{
+        Jar:       &accountJar{am: am},
+        Timeout:   30 * time.Second,
+        Transport: transport,
+    }
+
+    return am, nil
 }
 
 func (am *AuthManager) LoadCookies() error {
-    am.logger.Info("Loading cookies from file...")
+    for _, acc := range am.accounts {
+        if err := am.loadAccountCookies(acc); err != nil {
+            return err
+        }
+    }
+    return nil
+}
 
-    if _, err := os.Stat(am.cookiesFile); os.IsNotExist(err) {
-        return fmt.Errorf("cookies file not found: %s", am.cookiesFile)
+func (am *AuthManager) loadAccountCookies(acc *account) error {
+    am.logger.Infof("Loading cookies from file %s...", acc.cookiesFile)
+
+    if _, err := os.Stat(acc.cookiesFile); os.IsNotExist(err) {
+        return fmt.Errorf("cookies file not found: %s", acc.cookiesFile)
     }
 
-    data, err := ioutil.ReadFile(am.cookiesFile)
+    data, err := ioutil.ReadFile(acc.cookiesFile)
     if err != nil {
         return fmt.Errorf("failed to read cookies file: %w", err)
     }
 
-    var cookieStore map[string][]Cookie
-    if err := json.Unmarshal(data, &cookieStore); err != nil {
-        return fmt.Errorf("failed to parse cookies file: %w", err)
+    var facebookCookies []Cookie
+    acc.format = detectCookieFormat(acc.cookiesFile, data)
+    switch acc.format {
+    case cookieFormatNetscape:
+        facebookCookies, err = parseNetscapeCookies(data)
+    case cookieFormatExtension:
+        facebookCookies, err = parseExtensionCookies(data)
+    default:
+        facebookCookies, err = parseCookieStore(data)
     }
-
-    // Load cookies for facebook.com
-    facebookCookies, exists := cookieStore["facebook.com"]
-    if !exists {
-        return fmt.Errorf("no Facebook cookies found in cookies file")
+    if err != nil {
+        return err
     }
 
     // Parse Facebook URL
@@ -114,14 +135,52 @@ func (am *AuthManager) LoadCookies() error {
         httpCookies = append(httpCookies, httpCookie)
     }
 
-    am.cookieJar.SetCookies(fbURL, httpCookies)
-    am.logger.Infof("Loaded %d cookies for Facebook", len(httpCookies))
+    acc.jar.SetCookies(fbURL, httpCookies)
+    for _, cookie := range httpCookies {
+        if cookie.Name == "c_user" {
+            acc.userID = cookie.Value
+        }
+    }
+    am.logger.Infof("Loaded %d cookies for Facebook account %s", len(httpCookies), acc.label())
 
     return nil
 }
 
+// ValidateAuth validates every account. With several accounts, ones that
+// fail are put on cooldown and an error is only returned if none pass.
 func (am *AuthManager) ValidateAuth() error {
-    am.logger.Info("Validating Facebook authentication...")
+    if len(am.accounts) == 1 {
+        return am.validateAccount(am.accounts[0])
+    }
+
+    valid := 0
+    var lastErr error
+    for _, acc := range am.accounts {
+        if err := am.validateAccount(acc); err != nil {
+            am.logger.Warnf("Account %s failed validation: %v", acc.label(), err)
+            am.mu.Lock()
+            acc.cooldownUntil = time.Now().Add(am.cooldown)
+            am.mu.Unlock()
+            lastErr = err
+            continue
+        }
+        valid++
+    }
+
+    if valid == 0 {
+        return fmt.Errorf("no account passed validation, last error: %w", lastErr)
+    }
+
+    // Make sure we start on an account that passed
+    if am.accountAvailable(am.activeAccount()) {
+        return nil
+    }
+    _, err := am.RotateAccount("failed validation")
+    return err
+}
+
+func (am *AuthManager) validateAccount(acc *account) error {
+    am.logger.Infof("Validating Facebook authentication for account %s...", acc.label())
 
     // Use a more realistic endpoint - try the notifications page
     req, err := http.NewRequest("GET", "https://www.facebook.com/notifications", nil)
@@ -133,7 +192,7 @@ func (am *AuthManager) ValidateAuth() error {
     req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36")
     req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7")
     req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-    req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+    req.Header.Set("Accept-Encoding", acceptEncoding)
     req.Header.Set("DNT", "1")
     req.Header.Set("Connection", "keep-alive")
     req.Header.Set("Upgrade-Insecure-Requests", "1")
@@ -143,7 +202,7 @@ func (am *AuthManager) ValidateAuth() error {
     req.Header.Set("Sec-Fetch-User", "?1")
     req.Header.Set("Cache-Control", "max-age=0")
 
-    resp, err := am.client.Do(req)
+    resp, err := acc.client.Do(req)
     if err != nil {
         return fmt.Errorf("failed to validate authentication: %w", err)
     }
@@ -152,7 +211,7 @@ func (am *AuthManager) ValidateAuth() error {
     am.logger.Infof("Validation response: Status=%d, URL=%s", resp.StatusCode, resp.Request.URL.String())
 
     // Read response body for debugging
-    body, err := ioutil.ReadAll(resp.Body)
+    body, err := readBody(resp)
     if err != nil {
         am.logger.Warnf("Failed to read response body: %v", err)
     } else {
@@ -169,7 +228,7 @@ func (am *AuthManager) ValidateAuth() error {
         }
         
         // Check for failure indicators
-        if strings.Contains(bodyStr, "login") || strings.Contains(bodyStr, "Log In") {
+        if containsLoginMarkers(bodyStr) {
             return fmt.Errorf("authentication failed: redirected to login page")
         }
         
@@ -192,7 +251,7 @@ func (am *AuthManager) ValidateAuth() error {
     switch resp.StatusCode {
     case http.StatusOK:
         // Even if 200, check if we got the real page
-        if strings.Contains(resp.Request.URL.String(), "login") {
+        if isLoginURL(resp.Request.URL) {
             return fmt.Errorf("authentication failed: redirected to login page")
         }
         am.logger.Info("Authentication validated successfully")
@@ -210,15 +269,103 @@ func (am *AuthManager) ValidateAuth() error {
     }
 }
 
+// SetProxies routes requests through the given HTTP(S) or SOCKS5 proxies,
+// rotating through them per request when more than one is configured
+func (am *AuthManager) SetProxies(proxies []string) error {
+    if len(proxies) == 0 {
+        am.transport.Proxy = nil
+        return nil
+    }
+
+    var proxyURLs []*url.URL
+    for _, proxy := range proxies {
+        proxyURL, err := url.Parse(proxy)
+        if err != nil {
+            return fmt.Errorf("invalid proxy URL %q: %w", proxy, err)
+        }
+        switch proxyURL.Scheme {
+        case "http", "https", "socks5":
+        default:
+            return fmt.Errorf("unsupported proxy scheme %q in %s", proxyURL.Scheme, proxy)
+        }
+        proxyURLs = append(proxyURLs, proxyURL)
+    }
+
+    var next uint64
+    am.transport.Proxy = func(req *http.Request) (*url.URL, error) {
+        i := atomic.AddUint64(&next, 1) - 1
+        return proxyURLs[i%uint64(len(proxyURLs))], nil
+    }
+
+    am.logger.Infof("Using %d proxy(ies) for Facebook requests", len(proxyURLs))
+    return nil
+}
+
+// ErrAuthExpired is returned when Facebook serves a login page instead of
+// the requested content, usually because the session cookies expired
+var ErrAuthExpired = errors.New("facebook session expired")
+
+func isLoginURL(u *url.URL) bool {
+    return strings.HasPrefix(u.Path, "/login")
+}
+
+// loginMarkers match the login form Facebook serves in place of the requested
+// content, and the checkpoint redirects used for locked sessions. Posts often
+// mention "login" in passing, so plain text matches are not enough.
+var loginMarkers = []string{
+    "form#login_form",
+    `input[name="pass"]`,
+    `form[action*="/checkpoint/"]`,
+    `meta[http-equiv="refresh"][content*="/checkpoint/"]`,
+}
+
+func containsLoginMarkers(body string) bool {
+    doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+    if err != nil {
+        return false
+    }
+
+    for _, selector := range loginMarkers {
+        if doc.Find(selector).Length() > 0 {
+            return true
+        }
+    }
+    return false
+}
+
+// ActiveCookies returns the active account's Facebook cookies, for handing
+// to a browser session
+func (am *AuthManager) ActiveCookies() []Cookie {
+    fbURL, _ := url.Parse("https://www.facebook.com")
+
+    var cookies []Cookie
+    for _, cookie := range am.activeAccount().jar.Cookies(fbURL) {
+        cookies = append(cookies, Cookie{
+            Name:  cookie.Name,
+            Value: cookie.Value,
+        })
+    }
+    return cookies
+}
+
 func (am *AuthManager) GetAuthenticatedClient() *http.Client {
     return am.client
 }
 
 func (am *AuthManager) SaveCookies() error {
-    am.logger.Info("Saving current cookies...")
+    for _, acc := range am.accounts {
+        if err := am.saveAccountCookies(acc); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (am *AuthManager) saveAccountCookies(acc *account) error {
+    am.logger.Infof("Saving current cookies for account %s...", acc.label())
 
     fbURL, _ := url.Parse("https://www.facebook.com")
-    cookies := am.cookieJar.Cookies(fbURL)
+    cookies := acc.jar.Cookies(fbURL)
 
     var cookieData []Cookie
     for _, cookie := range cookies {
@@ -233,16 +380,25 @@ func (am *AuthManager) SaveCookies() error {
         })
     }
 
-    cookieStore := map[string][]Cookie{
-        "facebook.com": cookieData,
+    // Keep the file in the format it was loaded from
+    var data []byte
+    var err error
+    switch acc.format {
+    case cookieFormatNetscape:
+        data = formatNetscapeCookies(cookieData)
+    case cookieFormatExtension:
+        data, err = formatExtensionCookies(cookieData)
+    default:
+        cookieStore := map[string][]Cookie{
+            "facebook.com": cookieData,
+        }
+        data, err = json.MarshalIndent(cookieStore, "", "  ")
     }
-
-    data, err := json.MarshalIndent(cookieStore, "", "  ")
     if err != nil {
         return fmt.Errorf("failed to marshal cookies: %w", err)
     }
 
-    err = ioutil.WriteFile(am.cookiesFile, data, 0600)
+    err = ioutil.WriteFile(acc.cookiesFile, data, 0600)
     if err != nil {
         return fmt.Errorf("failed to write cookies file: %w", err)
     }
@@ -272,24 +428,49 @@ Required cookies:
 6. Update the configs/cookies.json file with these values`)
 }
 
-func (am *AuthManager) validateCookieFormat() error {
+// RequiredCookies are the Facebook cookies needed for an authenticated session
+var RequiredCookies = []string{"c_user", "xs", "datr"}
+
+// CheckRequiredCookies reports, for each required cookie of the active
+// account, nil when it is present and well-formed or an error describing the problem
+func (am *AuthManager) CheckRequiredCookies() map[string]error {
+    return checkRequiredCookies(am.activeAccount())
+}
+
+func checkRequiredCookies(acc *account) map[string]error {
     fbURL, _ := url.Parse("https://www.facebook.com")
-    cookies := am.cookieJar.Cookies(fbURL)
+    cookies := acc.jar.Cookies(fbURL)
     
-    requiredCookies := []string{"c_user", "xs", "datr"}
     cookieMap := make(map[string]*http.Cookie)
-    
     for _, cookie := range cookies {
         cookieMap[cookie.Name] = cookie
     }
     
-    for _, required := range requiredCookies {
+    results := make(map[string]error)
+    for _, required := range RequiredCookies {
         if cookie, exists := cookieMap[required]; !exists {
-            return fmt.Errorf("missing required cookie: %s", required)
+            results[required] = fmt.Errorf("missing required cookie: %s", required)
         } else if cookie.Value == "" {
-            return fmt.Errorf("empty value for required cookie: %s", required)
+            results[required] = fmt.Errorf("empty value for required cookie: %s", required)
         } else if required == "c_user" && !isNumeric(cookie.Value) {
-            return fmt.Errorf("c_user cookie should be numeric, got: %s", cookie.Value)
+            results[required] = fmt.Errorf("c_user cookie should be numeric, got: %s", cookie.Value)
+        } else {
+            results[required] = nil
+        }
+    }
+    
+    return results
+}
+
+// ValidateCookieFormat checks that the loaded cookies include every required
+// cookie with a sensible value
+func (am *AuthManager) ValidateCookieFormat() error {
+    for _, acc := range am.accounts {
+        results := checkRequiredCookies(acc)
+        for _, required := range RequiredCookies {
+            if err := results[required]; err != nil {
+                return fmt.Errorf("%s: %w", acc.cookiesFile, err)
+            }
         }
     }
     
@@ -0,0 +1,117 @@
+package scraper
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/sirupsen/logrus"
+)
+
+// newTestScraper returns a scraper whose Facebook URLs point at baseURL, with
+// no database and no delay between pages
+func newTestScraper(t *testing.T, baseURL string) *FacebookScraper {
+    t.Helper()
+
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+
+    fs, err := NewFacebookScraper("cookies.json", "test-agent", 0, logger, nil)
+    if err != nil {
+        t.Fatalf("NewFacebookScraper: %v", err)
+    }
+    fs.baseURL = baseURL
+    fs.mobileURL = baseURL
+    fs.SetMaxPages(1)
+    return fs
+}
+
+const loginPage = `<html><body>
+<form id="login_form" action="/login/device-based/regular/login/" method="post">
+<input type="text" name="email"><input type="password" name="pass">
+<button type="submit">Log In</button>
+</form></body></html>`
+
+const postPage = `<html><body>
+<div data-ft='{"top_level_post_id":"42"}'>
+<h3><a href="/jane.doe">Jane Doe</a></h3>
+<p>Anyone else unable to login since the update?</p>
+</div></body></html>`
+
+func TestContainsLoginMarkers(t *testing.T) {
+    tests := []struct {
+        name string
+        body string
+        want bool
+    }{
+        {"login form", loginPage, true},
+        {"password field only", `<form><input name="pass" type="password"></form>`, true},
+        {"checkpoint form", `<form action="/checkpoint/?next=%2F" method="post"></form>`, true},
+        {"checkpoint refresh", `<meta http-equiv="refresh" content="0; url=/checkpoint/block/">`, true},
+        {"post mentioning login", postPage, false},
+        {"log in link", `<a href="/help">Log In help</a>`, false},
+        {"empty", "", false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := containsLoginMarkers(tt.body); got != tt.want {
+                t.Errorf("containsLoginMarkers() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestFetchGroupPostsLoginPageServedWith200(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        fmt.Fprint(w, loginPage)
+    }))
+    defer server.Close()
+
+    fs := newTestScraper(t, server.URL)
+    _, err := fs.FetchGroupPosts(context.Background(), "123")
+    if !errors.Is(err, ErrAuthExpired) {
+        t.Fatalf("FetchGroupPosts() error = %v, want ErrAuthExpired", err)
+    }
+}
+
+func TestFetchGroupPostsLoginPageOnOneStrategy(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == "/groups/123" {
+            fmt.Fprint(w, loginPage)
+            return
+        }
+        fmt.Fprint(w, postPage)
+    }))
+    defer server.Close()
+
+    fs := newTestScraper(t, server.URL)
+    posts, err := fs.FetchGroupPosts(context.Background(), "123")
+    if err != nil {
+        t.Fatalf("FetchGroupPosts() error = %v", err)
+    }
+    if len(posts) != 1 || posts[0].ID != "42" {
+        t.Fatalf("FetchGroupPosts() = %+v, want post 42", posts)
+    }
+}
+
+func TestFetchHTMLLoginRedirect(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == "/login.php" {
+            fmt.Fprint(w, loginPage)
+            return
+        }
+        http.Redirect(w, r, "/login.php?next=%2Fgroups%2F123", http.StatusFound)
+    }))
+    defer server.Close()
+
+    fs := newTestScraper(t, server.URL)
+    _, err := fs.fetchHTML(context.Background(), server.URL+"/groups/123", "test")
+    if !errors.Is(err, ErrAuthExpired) {
+        t.Fatalf("fetchHTML() error = %v, want ErrAuthExpired", err)
+    }
+}
@@ -0,0 +1,50 @@
+package scraper
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "facebook-scraper/pkg/types"
+)
+
+// Backend names accepted by scraper.backend in config.yaml
+const (
+    BackendHTTP     = "http"
+    BackendSelenium = "selenium"
+    BackendChromedp = "chromedp"
+)
+
+// GroupScraper fetches the raw posts of a group. Filtering and storage are
+// left to FacebookScraper so every backend is treated the same way.
+type GroupScraper interface {
+    FetchGroupPosts(ctx context.Context, groupID string) ([]types.ScrapedPost, error)
+}
+
+// BrowserOptions configures the browser-based backends
+type BrowserOptions struct {
+    Headless bool
+
+    // Selenium only
+    Browser     string // firefox (default) or chrome
+    DriverPath  string // geckodriver / chromedriver binary
+    DriverPort  int
+    MaxScrolls  int
+    WaitTimeout time.Duration // how long to wait for posts to (re)load
+}
+
+// NewBackend returns the GroupScraper registered under name. The HTTP backend
+// is fs itself; browser backends reuse its parser and cookies.
+func NewBackend(name string, fs *FacebookScraper, opts BrowserOptions) (GroupScraper, error) {
+    switch name {
+    case "", BackendHTTP:
+        return fs, nil
+    case BackendChromedp:
+        return newChromedpBackend(fs, opts)
+    case BackendSelenium:
+        return newSeleniumBackend(fs, opts)
+    default:
+        return nil, fmt.Errorf("unknown scraper backend %q (expected %s, %s or %s)",
+            name, BackendHTTP, BackendSelenium, BackendChromedp)
+    }
+}
@@ -1,19 +1,32 @@
 package scraper
 
-/*import (
+import (
     "context"
     "fmt"
     "strings"
+    "sync"
     "time"
 
     "github.com/chromedp/cdproto/network"
     "github.com/chromedp/chromedp"
     "github.com/sirupsen/logrus"
+    "facebook-scraper/pkg/types"
 )
 
-func NewBrowserScraper(logger *logrus.Logger) *EnhancedBrowserScraper {
+// EnhancedBrowserScraper drives a real Chrome instance through chromedp so
+// that posts loaded by scrolling or "See more" links can be scraped
+type EnhancedBrowserScraper struct {
+    logger *logrus.Logger
+    ctx    context.Context
+    cancel context.CancelFunc
+
+    startOnce sync.Once
+    startErr  error
+}
+
+func NewBrowserScraper(logger *logrus.Logger, headless bool) *EnhancedBrowserScraper {
     opts := append(chromedp.DefaultExecAllocatorOptions[:],
-        chromedp.Flag("headless", false), // Set to true for production
+        chromedp.Flag("headless", headless),
         chromedp.Flag("disable-gpu", true),
         chromedp.Flag("disable-web-security", true),
         chromedp.Flag("disable-features", "VizDisplayCompositor"),
@@ -22,19 +35,70 @@ func NewBrowserScraper(logger *logrus.Logger) *EnhancedBrowserScraper {
         chromedp.UserAgent("Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"),
     )
 
-    allocCtx, _ := chromedp.NewExecAllocator(context.Background(), opts...)
-    ctx, cancel := chromedp.NewContext(allocCtx)
+    allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
+    ctx, ctxCancel := chromedp.NewContext(allocCtx)
 
     return &EnhancedBrowserScraper{
         logger: logger,
         ctx:    ctx,
-        cancel: cancel,
+        cancel: func() {
+            ctxCancel()
+            allocCancel()
+        },
     }
 }
 
-func (ebs *EnhancedBrowserScraper) ScrapeGroupWithScrolling(groupID string, cookies []Cookie) (string, error) {
+// chromedpBackend adapts EnhancedBrowserScraper to GroupScraper, feeding the
+// rendered HTML through the HTTP scraper's parser
+type chromedpBackend struct {
+    browser *EnhancedBrowserScraper
+    fs      *FacebookScraper
+}
+
+func newChromedpBackend(fs *FacebookScraper, opts BrowserOptions) (GroupScraper, error) {
+    return &chromedpBackend{
+        browser: NewBrowserScraper(fs.logger, opts.Headless),
+        fs:      fs,
+    }, nil
+}
+
+func (cb *chromedpBackend) FetchGroupPosts(ctx context.Context, groupID string) ([]types.ScrapedPost, error) {
+    html, err := cb.browser.ScrapeGroupWithScrolling(ctx, groupID, cb.fs.authManager.ActiveCookies())
+    if err != nil {
+        return nil, err
+    }
+
+    return cb.fs.parseGroupPosts(html, groupID)
+}
+
+func (cb *chromedpBackend) Close() error {
+    cb.browser.Close()
+    return nil
+}
+
+// start launches the shared browser. Tabs created from a context whose
+// browser isn't running yet would each get a browser of their own
+func (ebs *EnhancedBrowserScraper) start() error {
+    ebs.startOnce.Do(func() {
+        ebs.startErr = chromedp.Run(ebs.ctx)
+    })
+    return ebs.startErr
+}
+
+// ScrapeGroupWithScrolling loads the group in a tab of its own, which is
+// closed when the scrape finishes or ctx is done; the browser stays up for
+// other callers
+func (ebs *EnhancedBrowserScraper) ScrapeGroupWithScrolling(ctx context.Context, groupID string, cookies []Cookie) (string, error) {
     ebs.logger.Infof("Starting enhanced browser scraping for group %s", groupID)
 
+    if err := ebs.start(); err != nil {
+        return "", fmt.Errorf("failed to start browser: %w", err)
+    }
+    tabCtx, cancel := chromedp.NewContext(ebs.ctx)
+    defer cancel()
+    stop := context.AfterFunc(ctx, cancel)
+    defer stop()
+
     // Multiple URL strategies
     urls := []string{
         fmt.Sprintf("https://m.facebook.com/groups/%s", groupID),
@@ -48,8 +112,11 @@ func (ebs *EnhancedBrowserScraper) ScrapeGroupWithScrolling(groupID string, cook
     for _, url := range urls {
         ebs.logger.Infof("Trying URL: %s", url)
         
-        html, err := ebs.scrapeURL(url, cookies)
+        html, err := ebs.scrapeURL(tabCtx, url, cookies)
         if err != nil {
+            if ctx.Err() != nil {
+                return "", ctx.Err()
+            }
             ebs.logger.Warnf("Failed to scrape %s: %v", url, err)
             lastError = err
             continue
@@ -71,10 +138,10 @@ func (ebs *EnhancedBrowserScraper) ScrapeGroupWithScrolling(groupID string, cook
     return finalHTML, nil
 }
 
-func (ebs *EnhancedBrowserScraper) scrapeURL(url string, cookies []Cookie) (string, error) {
+func (ebs *EnhancedBrowserScraper) scrapeURL(ctx context.Context, url string, cookies []Cookie) (string, error) {
     var html string
     
-    err := chromedp.Run(ebs.ctx,
+    err := chromedp.Run(ctx,
         chromedp.Navigate(url),
         chromedp.WaitVisible("body", chromedp.ByQuery),
         // Set cookies
@@ -208,10 +275,6 @@ func (ebs *EnhancedBrowserScraper) clickSeeMoreButtons(ctx context.Context) erro
             `a[href*="show_older"]`,
             `a[href*="bacr"]`,
             `a[href*="more"]`,
-            `a:contains("See More")`,
-            `a:contains("Show older")`,
-            `a:contains("Load more")`,
-            `button:contains("See more")`,
         }
         
         clicked := false
@@ -269,8 +332,7 @@ func (ebs *EnhancedBrowserScraper) infiniteScroll(ctx context.Context) error {
         // Check if we've reached old enough posts
         var hasOldPosts bool
         chromedp.Evaluate(`
-            const timeElements = document.querySelectorAll('[data-utime], time[datetime]');
-            return Array.from(timeElements).some(el => {
+            Array.from(document.querySelectorAll('[data-utime], time[datetime]')).some(el => {
                 const timeStr = el.getAttribute('data-utime') || el.getAttribute('datetime');
                 if (timeStr) {
                     const postTime = timeStr.includes('-') ? new Date(timeStr) : new Date(parseInt(timeStr) * 1000);
@@ -278,7 +340,7 @@ func (ebs *EnhancedBrowserScraper) infiniteScroll(ctx context.Context) error {
                     return postTime <= fiveDaysAgo;
                 }
                 return false;
-            });
+            })
         `, &hasOldPosts).Do(ctx)
         
         if hasOldPosts {
@@ -321,4 +383,4 @@ func (ebs *EnhancedBrowserScraper) Close() {
     if ebs.cancel != nil {
         ebs.cancel()
     }
-}*/
\ No newline at end of file
+}
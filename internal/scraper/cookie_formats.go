@@ -0,0 +1,243 @@
+package scraper
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const netscapeCookieHeader = "# Netscape HTTP Cookie File"
+
+// cookieFormat identifies the on-disk layout of a cookies file
+type cookieFormat int
+
+const (
+    // cookieFormatStore is the project's {"facebook.com": [...]} JSON format
+    cookieFormatStore cookieFormat = iota
+    // cookieFormatNetscape is the tab-separated cookies.txt format
+    cookieFormatNetscape
+    // cookieFormatExtension is the JSON array exported by Cookie-Editor and EditThisCookie
+    cookieFormatExtension
+)
+
+// extensionCookie is a single entry of a Cookie-Editor / EditThisCookie export
+type extensionCookie struct {
+    Name           string  `json:"name"`
+    Value          string  `json:"value"`
+    Domain         string  `json:"domain"`
+    Path           string  `json:"path"`
+    Secure         bool    `json:"secure"`
+    HttpOnly       bool    `json:"httpOnly"`
+    HostOnly       bool    `json:"hostOnly"`
+    Session        bool    `json:"session"`
+    ExpirationDate float64 `json:"expirationDate,omitempty"`
+}
+
+// detectCookieFormat works out which format a cookies file uses
+func detectCookieFormat(path string, data []byte) cookieFormat {
+    if isNetscapeCookieFile(path, data) {
+        return cookieFormatNetscape
+    }
+    if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+        return cookieFormatExtension
+    }
+    return cookieFormatStore
+}
+
+// parseCookieStore parses the project's {"facebook.com": [...]} JSON format
+func parseCookieStore(data []byte) ([]Cookie, error) {
+    var cookieStore map[string][]Cookie
+    if err := json.Unmarshal(data, &cookieStore); err != nil {
+        return nil, fmt.Errorf("failed to parse cookies file: %w", err)
+    }
+
+    // Load cookies for facebook.com
+    facebookCookies, exists := cookieStore["facebook.com"]
+    if !exists {
+        return nil, fmt.Errorf("no Facebook cookies found in cookies file")
+    }
+
+    return facebookCookies, nil
+}
+
+// isNetscapeCookieFile detects the tab-separated cookies.txt format by its
+// header line or a .txt extension
+func isNetscapeCookieFile(path string, data []byte) bool {
+    if bytes.HasPrefix(bytes.TrimSpace(data), []byte(netscapeCookieHeader)) {
+        return true
+    }
+    return strings.EqualFold(filepath.Ext(path), ".txt")
+}
+
+// parseNetscapeCookies parses a Netscape cookies.txt export, keeping only
+// facebook.com cookies
+func parseNetscapeCookies(data []byte) ([]Cookie, error) {
+    var cookies []Cookie
+
+    scanner := bufio.NewScanner(bytes.NewReader(data))
+    lineNum := 0
+    for scanner.Scan() {
+        lineNum++
+        line := strings.TrimSpace(scanner.Text())
+
+        // Browsers mark HttpOnly cookies with a "#HttpOnly_" domain prefix
+        httpOnly := false
+        if strings.HasPrefix(line, "#HttpOnly_") {
+            httpOnly = true
+            line = strings.TrimPrefix(line, "#HttpOnly_")
+        }
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+
+        // domain, include subdomains, path, secure, expiry, name, value
+        fields := strings.Split(line, "\t")
+        if len(fields) < 7 {
+            return nil, fmt.Errorf("invalid cookies.txt line %d: expected 7 tab-separated fields, got %d", lineNum, len(fields))
+        }
+
+        domain := fields[0]
+        if !strings.HasSuffix(strings.TrimPrefix(domain, "."), "facebook.com") {
+            continue
+        }
+
+        cookie := Cookie{
+            Name:     fields[5],
+            Value:    fields[6],
+            Domain:   domain,
+            Path:     fields[2],
+            Secure:   strings.EqualFold(fields[3], "TRUE"),
+            HttpOnly: httpOnly,
+        }
+
+        // An expiry of 0 marks a session cookie
+        if expiry, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expiry > 0 {
+            cookie.Expires = time.Unix(expiry, 0).UTC().Format(time.RFC3339)
+        }
+
+        cookies = append(cookies, cookie)
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read cookies.txt: %w", err)
+    }
+
+    if len(cookies) == 0 {
+        return nil, fmt.Errorf("no Facebook cookies found in cookies file")
+    }
+
+    return cookies, nil
+}
+
+// parseExtensionCookies parses the JSON array exported by browser cookie
+// extensions, keeping only facebook.com cookies
+func parseExtensionCookies(data []byte) ([]Cookie, error) {
+    var exported []extensionCookie
+    if err := json.Unmarshal(data, &exported); err != nil {
+        return nil, fmt.Errorf("failed to parse cookies file: %w", err)
+    }
+
+    var cookies []Cookie
+    for _, ec := range exported {
+        if !strings.HasSuffix(strings.TrimPrefix(ec.Domain, "."), "facebook.com") {
+            continue
+        }
+
+        cookie := Cookie{
+            Name:     ec.Name,
+            Value:    ec.Value,
+            Domain:   ec.Domain,
+            Path:     ec.Path,
+            Secure:   ec.Secure,
+            HttpOnly: ec.HttpOnly,
+        }
+
+        // expirationDate is fractional unix seconds and absent for session cookies
+        if !ec.Session && ec.ExpirationDate > 0 {
+            sec := int64(ec.ExpirationDate)
+            nsec := int64((ec.ExpirationDate - float64(sec)) * 1e9)
+            cookie.Expires = time.Unix(sec, nsec).UTC().Format(time.RFC3339)
+        }
+
+        cookies = append(cookies, cookie)
+    }
+
+    if len(cookies) == 0 {
+        return nil, fmt.Errorf("no Facebook cookies found in cookies file")
+    }
+
+    return cookies, nil
+}
+
+// formatExtensionCookies renders cookies as a Cookie-Editor style JSON array
+func formatExtensionCookies(cookies []Cookie) ([]byte, error) {
+    exported := make([]extensionCookie, 0, len(cookies))
+    for _, cookie := range cookies {
+        ec := extensionCookie{
+            Name:     cookie.Name,
+            Value:    cookie.Value,
+            Domain:   cookie.Domain,
+            Path:     cookie.Path,
+            Secure:   cookie.Secure,
+            HttpOnly: cookie.HttpOnly,
+            Session:  true,
+        }
+        if ec.Domain == "" {
+            ec.Domain = ".facebook.com"
+        }
+        if ec.Path == "" {
+            ec.Path = "/"
+        }
+        if expires, err := time.Parse(time.RFC3339, cookie.Expires); err == nil && expires.Unix() > 0 {
+            ec.Session = false
+            ec.ExpirationDate = float64(expires.Unix())
+        }
+        exported = append(exported, ec)
+    }
+
+    return json.MarshalIndent(exported, "", "  ")
+}
+
+// formatNetscapeCookies renders cookies in the Netscape cookies.txt format
+func formatNetscapeCookies(cookies []Cookie) []byte {
+    var buf bytes.Buffer
+    buf.WriteString(netscapeCookieHeader + "\n\n")
+
+    for _, cookie := range cookies {
+        domain := cookie.Domain
+        if domain == "" {
+            domain = ".facebook.com"
+        }
+        path := cookie.Path
+        if path == "" {
+            path = "/"
+        }
+
+        var expiry int64
+        if expires, err := time.Parse(time.RFC3339, cookie.Expires); err == nil && expires.Unix() > 0 {
+            expiry = expires.Unix()
+        }
+
+        prefix := ""
+        if cookie.HttpOnly {
+            prefix = "#HttpOnly_"
+        }
+
+        fmt.Fprintf(&buf, "%s%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+            prefix, domain, netscapeBool(strings.HasPrefix(domain, ".")), path,
+            netscapeBool(cookie.Secure), expiry, cookie.Name, cookie.Value)
+    }
+
+    return buf.Bytes()
+}
+
+func netscapeBool(b bool) string {
+    if b {
+        return "TRUE"
+    }
+    return "FALSE"
+}
@@ -0,0 +1,135 @@
+package scraper
+
+import (
+    "io"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+
+    "github.com/sirupsen/logrus"
+)
+
+// fixtureCookies are the Facebook cookies every file in testdata/cookies holds
+var fixtureCookies = []Cookie{
+    {Name: "c_user", Value: "100012345", Domain: ".facebook.com", Path: "/", Secure: true, Expires: "2030-01-01T00:00:00Z"},
+    {Name: "xs", Value: "35%3Aabc", Domain: ".facebook.com", Path: "/", Secure: true, HttpOnly: true},
+}
+
+func readFixture(t *testing.T, name string) (string, []byte) {
+    t.Helper()
+
+    path := filepath.Join("testdata", "cookies", name)
+    data, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return path, data
+}
+
+func TestDetectCookieFormat(t *testing.T) {
+    tests := []struct {
+        file string
+        want cookieFormat
+    }{
+        {"store.json", cookieFormatStore},
+        {"cookie-editor.json", cookieFormatExtension},
+        {"editthiscookie.json", cookieFormatExtension},
+        {"cookies.txt", cookieFormatNetscape},
+        {"netscape-export", cookieFormatNetscape},
+    }
+
+    for _, tt := range tests {
+        path, data := readFixture(t, tt.file)
+        if got := detectCookieFormat(path, data); got != tt.want {
+            t.Errorf("detectCookieFormat(%s) = %d, want %d", tt.file, got, tt.want)
+        }
+    }
+}
+
+func TestParseCookieFormats(t *testing.T) {
+    // EditThisCookie gives every cookie an expiry
+    withExpiry := append([]Cookie(nil), fixtureCookies...)
+    withExpiry[1].Expires = "2030-01-01T00:00:00Z"
+
+    tests := []struct {
+        file  string
+        parse func([]byte) ([]Cookie, error)
+        want  []Cookie
+    }{
+        {"store.json", parseCookieStore, fixtureCookies},
+        {"cookie-editor.json", parseExtensionCookies, fixtureCookies},
+        {"editthiscookie.json", parseExtensionCookies, withExpiry},
+        {"cookies.txt", parseNetscapeCookies, fixtureCookies},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.file, func(t *testing.T) {
+            _, data := readFixture(t, tt.file)
+            got, err := tt.parse(data)
+            if err != nil {
+                t.Fatalf("parse: %v", err)
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("parsed %+v, want %+v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestLoadCookiesEveryFormat(t *testing.T) {
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+
+    for _, file := range []string{"store.json", "cookie-editor.json", "editthiscookie.json", "cookies.txt", "netscape-export"} {
+        t.Run(file, func(t *testing.T) {
+            am, err := NewAuthManager(filepath.Join("testdata", "cookies", file), "test-agent", logger)
+            if err != nil {
+                t.Fatalf("NewAuthManager: %v", err)
+            }
+            if err := am.LoadCookies(); err != nil {
+                t.Fatalf("LoadCookies: %v", err)
+            }
+            if got := am.ActiveUserID(); got != "100012345" {
+                t.Errorf("ActiveUserID() = %q, want the c_user cookie", got)
+            }
+        })
+    }
+}
+
+func TestParseCookiesWithoutFacebookCookies(t *testing.T) {
+    if _, err := parseCookieStore([]byte(`{"example.com": []}`)); err == nil {
+        t.Error("parseCookieStore() accepted a file without facebook.com")
+    }
+    if _, err := parseExtensionCookies([]byte(`[{"name": "a", "value": "b", "domain": ".example.com"}]`)); err == nil {
+        t.Error("parseExtensionCookies() accepted a file without Facebook cookies")
+    }
+    if _, err := parseNetscapeCookies([]byte(netscapeCookieHeader + "\n.example.com\tTRUE\t/\tFALSE\t0\ta\tb\n")); err == nil {
+        t.Error("parseNetscapeCookies() accepted a file without Facebook cookies")
+    }
+    if _, err := parseNetscapeCookies([]byte(".facebook.com\tTRUE\t/\n")); err == nil {
+        t.Error("parseNetscapeCookies() accepted a line with missing fields")
+    }
+}
+
+func TestFormatCookiesRoundTrip(t *testing.T) {
+    got, err := parseNetscapeCookies(formatNetscapeCookies(fixtureCookies))
+    if err != nil {
+        t.Fatalf("parseNetscapeCookies: %v", err)
+    }
+    if !reflect.DeepEqual(got, fixtureCookies) {
+        t.Errorf("Netscape round trip = %+v, want %+v", got, fixtureCookies)
+    }
+
+    data, err := formatExtensionCookies(fixtureCookies)
+    if err != nil {
+        t.Fatalf("formatExtensionCookies: %v", err)
+    }
+    got, err = parseExtensionCookies(data)
+    if err != nil {
+        t.Fatalf("parseExtensionCookies: %v", err)
+    }
+    if !reflect.DeepEqual(got, fixtureCookies) {
+        t.Errorf("extension round trip = %+v, want %+v", got, fixtureCookies)
+    }
+}
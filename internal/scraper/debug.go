@@ -0,0 +1,55 @@
+package scraper
+
+import (
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "time"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// pageLabel names a page of a scraping strategy in raw HTML dumps
+func pageLabel(label string, page int) string {
+    if page <= 1 {
+        return label
+    }
+    return fmt.Sprintf("%s-page%d", label, page)
+}
+
+// saveRawHTML writes a fetched page to <rawHTMLDir>/<label>-<timestamp>.html
+// with a .meta sidecar holding the status and final URL. It only logs
+// failures so debugging never breaks a scrape.
+func (fs *FacebookScraper) saveRawHTML(label, requestURL string, resp *http.Response, body []byte) {
+    if fs.rawHTMLDir == "" {
+        return
+    }
+
+    if err := os.MkdirAll(fs.rawHTMLDir, 0755); err != nil {
+        fs.logger.Warnf("Failed to create raw HTML directory: %v", err)
+        return
+    }
+
+    name := fmt.Sprintf("%s-%s",
+        strings.Trim(unsafeFilenameChars.ReplaceAllString(label, "_"), "_"),
+        time.Now().Format("20060102-150405.000"))
+    base := filepath.Join(fs.rawHTMLDir, name)
+
+    meta := fmt.Sprintf("url: %s\nfinal_url: %s\nstatus: %d\nfetched_at: %s\n",
+        requestURL, resp.Request.URL, resp.StatusCode, time.Now().Format(time.RFC3339))
+
+    if err := ioutil.WriteFile(base+".html", body, 0644); err != nil {
+        fs.logger.Warnf("Failed to save raw HTML: %v", err)
+        return
+    }
+    if err := ioutil.WriteFile(base+".meta", []byte(meta), 0644); err != nil {
+        fs.logger.Warnf("Failed to save raw HTML metadata: %v", err)
+        return
+    }
+
+    fs.logger.Debugf("Saved raw HTML to %s.html", base)
+}
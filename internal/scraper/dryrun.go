@@ -0,0 +1,42 @@
+package scraper
+
+import "facebook-scraper/pkg/types"
+
+// DryRunResult is what one group, Page or search would have saved
+type DryRunResult struct {
+    SourceType string
+    SourceID   string
+    Scraped    int
+    Posts      []types.ScrapedPost // posts that passed the filter
+    Filter     types.FilterStats
+}
+
+// SetDryRun makes scraping stop after filtering: nothing is written to the
+// database or output file and the results are kept for DryRunResults instead.
+// The scraper needs no database in this mode.
+func (fs *FacebookScraper) SetDryRun(enabled bool) {
+    fs.dryRunMu.Lock()
+    defer fs.dryRunMu.Unlock()
+    fs.dryRun = enabled
+    fs.dryRunResults = nil
+}
+
+// DryRunResults returns the results of every source scraped in dry-run mode,
+// in the order they finished
+func (fs *FacebookScraper) DryRunResults() []DryRunResult {
+    fs.dryRunMu.Lock()
+    defer fs.dryRunMu.Unlock()
+    return append([]DryRunResult(nil), fs.dryRunResults...)
+}
+
+func (fs *FacebookScraper) isDryRun() bool {
+    fs.dryRunMu.Lock()
+    defer fs.dryRunMu.Unlock()
+    return fs.dryRun
+}
+
+func (fs *FacebookScraper) recordDryRun(result DryRunResult) {
+    fs.dryRunMu.Lock()
+    defer fs.dryRunMu.Unlock()
+    fs.dryRunResults = append(fs.dryRunResults, result)
+}
@@ -0,0 +1,65 @@
+package scraper
+
+import (
+    "bytes"
+    "compress/flate"
+    "compress/gzip"
+    "compress/zlib"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net/http"
+    "strings"
+)
+
+// acceptEncoding lists the encodings readBody can decode. Go's transport only
+// decompresses responses transparently when it sets Accept-Encoding itself,
+// which the browser-like headers override. Brotli isn't in the standard
+// library, so br isn't offered.
+const acceptEncoding = "gzip, deflate"
+
+// readBody reads a response body, decoding it according to its
+// Content-Encoding
+func readBody(resp *http.Response) ([]byte, error) {
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, err
+    }
+    return decodeBody(body, resp.Header.Get("Content-Encoding"))
+}
+
+func decodeBody(body []byte, contentEncoding string) ([]byte, error) {
+    // Encodings are listed in the order they were applied
+    encodings := strings.Split(contentEncoding, ",")
+    for i := len(encodings) - 1; i >= 0; i-- {
+        var reader io.Reader
+        switch encoding := strings.ToLower(strings.TrimSpace(encodings[i])); encoding {
+        case "", "identity":
+            continue
+        case "gzip", "x-gzip":
+            gz, err := gzip.NewReader(bytes.NewReader(body))
+            if err != nil {
+                return nil, fmt.Errorf("failed to decode gzip body: %w", err)
+            }
+            defer gz.Close()
+            reader = gz
+        case "deflate":
+            // Servers send deflate both zlib-wrapped, as the spec says, and raw
+            if zr, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+                defer zr.Close()
+                reader = zr
+            } else {
+                reader = flate.NewReader(bytes.NewReader(body))
+            }
+        default:
+            return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+        }
+
+        decoded, err := ioutil.ReadAll(reader)
+        if err != nil {
+            return nil, fmt.Errorf("failed to decode %s body: %w", strings.TrimSpace(encodings[i]), err)
+        }
+        body = decoded
+    }
+    return body, nil
+}
@@ -0,0 +1,149 @@
+package scraper
+
+import (
+    "bytes"
+    "compress/flate"
+    "compress/gzip"
+    "compress/zlib"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+const encodingTestPage = `<html><body><div role="article">Hello, encoded world</div></body></html>`
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+    t.Helper()
+
+    var buf bytes.Buffer
+    w := gzip.NewWriter(&buf)
+    w.Write(data)
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return buf.Bytes()
+}
+
+func zlibBytes(t *testing.T, data []byte) []byte {
+    t.Helper()
+
+    var buf bytes.Buffer
+    w := zlib.NewWriter(&buf)
+    w.Write(data)
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return buf.Bytes()
+}
+
+func flateBytes(t *testing.T, data []byte) []byte {
+    t.Helper()
+
+    var buf bytes.Buffer
+    w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+    if err != nil {
+        t.Fatal(err)
+    }
+    w.Write(data)
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return buf.Bytes()
+}
+
+// fetchEncoded serves body with the given Content-Encoding and reads it back
+// through readBody, asking for compression the way the scraper does
+func fetchEncoded(t *testing.T, contentEncoding string, body []byte) ([]byte, error) {
+    t.Helper()
+
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if contentEncoding != "" {
+            w.Header().Set("Content-Encoding", contentEncoding)
+        }
+        w.Header().Set("Content-Type", "text/html; charset=utf-8")
+        w.Write(body)
+    }))
+    defer server.Close()
+
+    req, err := http.NewRequest("GET", server.URL, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // Setting Accept-Encoding stops the transport decompressing on its own
+    req.Header.Set("Accept-Encoding", acceptEncoding)
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    return readBody(resp)
+}
+
+func TestReadBodyEncodings(t *testing.T) {
+    page := []byte(encodingTestPage)
+
+    tests := []struct {
+        name            string
+        contentEncoding string
+        body            []byte
+    }{
+        {"no encoding", "", page},
+        {"identity", "identity", page},
+        {"gzip", "gzip", gzipBytes(t, page)},
+        {"x-gzip", "x-gzip", gzipBytes(t, page)},
+        {"deflate with zlib wrapper", "deflate", zlibBytes(t, page)},
+        {"raw deflate", "deflate", flateBytes(t, page)},
+        {"mixed case", "GZip", gzipBytes(t, page)},
+        {"stacked, applied in order", "deflate, gzip", gzipBytes(t, zlibBytes(t, page))},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := fetchEncoded(t, tt.contentEncoding, tt.body)
+            if err != nil {
+                t.Fatalf("readBody: %v", err)
+            }
+            if string(got) != encodingTestPage {
+                t.Errorf("readBody() = %q, want the page", got)
+            }
+        })
+    }
+}
+
+func TestReadBodyEncodingErrors(t *testing.T) {
+    tests := []struct {
+        name            string
+        contentEncoding string
+        body            []byte
+        wantErr         string
+    }{
+        {"unsupported", "br", []byte("not brotli"), `unsupported content encoding "br"`},
+        {"corrupt gzip", "gzip", []byte("not gzip at all"), "gzip"},
+        {"truncated gzip", "gzip", gzipBytes(t, []byte(encodingTestPage))[:20], "gzip"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := fetchEncoded(t, tt.contentEncoding, tt.body)
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Errorf("readBody() error = %v, want one mentioning %q", err, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestDecodeBodyLeavesPlainBodies(t *testing.T) {
+    got, err := decodeBody([]byte(encodingTestPage), " identity ")
+    if err != nil || !bytes.Equal(got, []byte(encodingTestPage)) {
+        t.Errorf("decodeBody() = %q, %v, want the body unchanged", got, err)
+    }
+
+    // The decoded body is complete, not a prefix of it
+    large := strings.Repeat(encodingTestPage, 1000)
+    got, err = decodeBody(gzipBytes(t, []byte(large)), "gzip")
+    if err != nil || len(got) != len(large) {
+        t.Errorf("decodeBody() of %d bytes = %d bytes, %v", len(large), len(got), err)
+    }
+}
@@ -66,7 +66,7 @@ func (ep *EnhancedParser) parseJSONPost(jsonStr string) types.ScrapedPost {
 
     // Extract content
     if message, ok := data["message"].(string); ok {
-        post.Content = message
+        post.Content = cleanContent(message)
     }
 
     // Extract engagement metrics
@@ -110,7 +110,7 @@ func (ep *EnhancedParser) ParseMobileFormat(doc *goquery.Document, groupID strin
 }
 
 func (ep *EnhancedParser) extractMobilePost(s *goquery.Selection, groupID string) types.ScrapedPost {
-    post := types.ScrapedPost{GroupID: groupID}
+    post := types.ScrapedPost{GroupID: groupID, IsSponsored: isSponsored(s)}
 
     // Extract post ID from data-ft attribute
     if dataFt, exists := s.Attr("data-ft"); exists {
@@ -119,13 +119,13 @@ func (ep *EnhancedParser) extractMobilePost(s *goquery.Selection, groupID string
 
     // Extract author
     authorLink := s.Find("h3 a, .actor a").First()
-    post.AuthorName = strings.TrimSpace(authorLink.Text())
+    post.AuthorName = cleanContent(authorLink.Text())
     if href, exists := authorLink.Attr("href"); exists {
         post.AuthorID = ep.extractUserIDFromHref(href)
     }
 
     // Extract content
-    post.Content = strings.TrimSpace(s.Find(".userContent, [data-testid='post_message']").Text())
+    post.Content = cleanContent(s.Find(".userContent, [data-testid='post_message']").Text())
 
     // Extract timestamp
     timeElem := s.Find("abbr[data-utime]").First()
@@ -233,12 +233,9 @@ func (ep *EnhancedParser) extractMobileImages(s *goquery.Selection) []types.Medi
     s.Find("img").Each(func(i int, img *goquery.Selection) {
         if src, exists := img.Attr("src"); exists {
             if ep.isValidFacebookImage(src) {
-                alt, _ := img.Attr("alt")
-                images = append(images, types.MediaItem{
-                    URL:         src,
-                    Type:        "image",
-                    Description: alt,
-                })
+                if item, ok := imageItem(img, src); ok {
+                    images = append(images, item)
+                }
             }
         }
     })
@@ -251,20 +248,14 @@ func (ep *EnhancedParser) extractMobileVideos(s *goquery.Selection) []types.Medi
 
     s.Find("video").Each(func(i int, video *goquery.Selection) {
         if src, exists := video.Attr("src"); exists {
-            videos = append(videos, types.MediaItem{
-                URL:  src,
-                Type: "video",
-            })
+            videos = append(videos, videoItem(video, src))
         }
     })
 
     // Also look for video thumbnails and data attributes
     s.Find("[data-video-id]").Each(func(i int, elem *goquery.Selection) {
         if videoID, exists := elem.Attr("data-video-id"); exists {
-            videos = append(videos, types.MediaItem{
-                URL:  ep.constructVideoURL(videoID),
-                Type: "video",
-            })
+            videos = append(videos, videoItem(elem, ep.constructVideoURL(videoID)))
         }
     })
 
@@ -272,6 +263,12 @@ func (ep *EnhancedParser) extractMobileVideos(s *goquery.Selection) []types.Medi
 }
 
 func (ep *EnhancedParser) isValidFacebookImage(src string) bool {
+    return isFacebookImageHost(src)
+}
+
+// isFacebookImageHost reports whether src is served from a host Facebook
+// uses for post media
+func isFacebookImageHost(src string) bool {
     return strings.Contains(src, "facebook.com") || 
            strings.Contains(src, "fbcdn.net") ||
            strings.Contains(src, "fbsbx.com")
@@ -297,7 +294,7 @@ func (ep *EnhancedParser) ParseDesktopFormat(doc *goquery.Document, groupID stri
 }
 
 func (ep *EnhancedParser) extractDesktopPost(s *goquery.Selection, groupID string) types.ScrapedPost {
-    post := types.ScrapedPost{GroupID: groupID}
+    post := types.ScrapedPost{GroupID: groupID, IsSponsored: isSponsored(s)}
 
     // Desktop posts have different structure
     // Extract ID from various possible attributes
@@ -307,10 +304,10 @@ func (ep *EnhancedParser) extractDesktopPost(s *goquery.Selection, groupID strin
 
     // Extract author from desktop layout
     authorElem := s.Find("h4 a, [data-testid='story-subtitle'] a").First()
-    post.AuthorName = strings.TrimSpace(authorElem.Text())
+    post.AuthorName = cleanContent(authorElem.Text())
 
     // Extract content from desktop layout
-    post.Content = strings.TrimSpace(s.Find("[data-testid='post_message'], .userContent").Text())
+    post.Content = cleanContent(s.Find("[data-testid='post_message'], .userContent").Text())
 
     // Extract timestamp from desktop layout
     timeElem := s.Find("time, [data-testid='story-subtitle'] a").First()
@@ -1,13 +1,18 @@
 package scraper
 
 import (
+    "context"
     "encoding/json"
+    "errors"
     "fmt"
-    "io/ioutil"
+    "html"
+    "io"
+    "math"
     "net/http"
     "regexp"
     "strconv"
     "strings"
+    "sync"
     "time"
     "net/url"
 
@@ -18,15 +23,53 @@ import (
     "facebook-scraper/pkg/types"
 )
 
+// reactionTypes are the reaction kinds Facebook shows in its breakdowns
+var reactionTypes = []string{"like", "love", "care", "haha", "wow", "sad", "angry"}
+
+// countPattern matches engagement counts including thousands separators and
+// K/M/B abbreviations. The abbreviation must end a word so "12 members" or
+// "2 Bob" isn't read as millions or billions.
+const countPattern = `(\d[\d,.]*(?:\s?[KkMmBb]\b)?)`
+
+// Source types stored with each post
+const (
+    SourceGroup  = "group"
+    SourcePage   = "page"
+    SourceSearch = "search"
+)
+
+// DefaultMaxPages is how many result pages are followed when not configured
+const DefaultMaxPages = 5
+
 type FacebookScraper struct {
     authManager   *AuthManager
     client        *http.Client
     logger        *logrus.Logger
     db            *database.DB
+    filter        *types.PostFilter
+    groupFilters  map[string]*types.PostFilter
+    backend       GroupScraper
+    fallback      GroupScraper
     rateLimit     time.Duration
-    userAgent     string
+    limiter       *rateLimiter
+    jitterPercent int
+    userAgents    *UserAgentPool
+    comments      bool
+    selectors     Selectors
+    rawHTMLDir    string
+    maxPages      int
+    groupNames    map[string]string
+    namesMu       sync.Mutex
     baseURL       string
     mobileURL     string
+    outputFormat  string
+    output        []types.ScrapedPost
+    outputMu      sync.Mutex
+    detectLang    bool
+    webhook       *PostWebhook
+    dryRun        bool
+    dryRunResults []DryRunResult
+    dryRunMu      sync.Mutex
 }
 
 type ScrapingStats struct {
@@ -43,16 +86,149 @@ func NewFacebookScraper(cookiesFile, userAgent string, rateLimit time.Duration,
         return nil, fmt.Errorf("failed to create auth manager: %w", err)
     }
 
-    return &FacebookScraper{
+    fs := &FacebookScraper{
         authManager: authManager,
         client:      authManager.GetAuthenticatedClient(),
         logger:      logger,
         db:          db,
+        filter:      &types.PostFilter{MinLikes: 1000, DaysBack: 5, ExcludeSponsored: true},
         rateLimit:   rateLimit,
-        userAgent:   userAgent,
+        userAgents:  NewUserAgentPool(nil, userAgent),
+        groupNames:  make(map[string]string),
+        maxPages:    DefaultMaxPages,
+        selectors:   DefaultSelectors(),
         baseURL:     "https://www.facebook.com",
         mobileURL:   "https://m.facebook.com",
-    }, nil
+    }
+    fs.backend = fs
+
+    return fs, nil
+}
+
+// SetFilter replaces the filter applied to scraped posts before they are
+// saved, rejecting filters with invalid regex patterns
+func (fs *FacebookScraper) SetFilter(filter *types.PostFilter) error {
+    if _, err := compilePatterns(filter); err != nil {
+        return err
+    }
+    fs.filter = filter
+    return nil
+}
+
+// SetGroupFilters overrides the filter for individual groups or Pages, keyed
+// by ID; other sources keep the filter from SetFilter
+func (fs *FacebookScraper) SetGroupFilters(filters map[string]*types.PostFilter) error {
+    for sourceID, filter := range filters {
+        if _, err := compilePatterns(filter); err != nil {
+            return fmt.Errorf("group %s: %w", sourceID, err)
+        }
+    }
+    fs.groupFilters = filters
+    return nil
+}
+
+// filterFor returns the filter applied to posts from a source
+func (fs *FacebookScraper) filterFor(sourceID string) *types.PostFilter {
+    if filter, ok := fs.groupFilters[sourceID]; ok {
+        return filter
+    }
+    return fs.filter
+}
+
+// SetRequestsPerMinute caps outbound requests with a token bucket shared by
+// all workers. While set, it replaces the fixed delay between result pages;
+// 0 turns it off.
+func (fs *FacebookScraper) SetRequestsPerMinute(perMinute int) {
+    fs.limiter = newRateLimiter(perMinute, 1)
+    if fs.limiter != nil {
+        fs.limiter.jitter = fs.jitterPercent
+    }
+}
+
+// SetJitterPercent randomizes the delay between requests by up to percent in
+// either direction, both the fixed delay and the limiter's waits
+func (fs *FacebookScraper) SetJitterPercent(percent int) {
+    fs.jitterPercent = percent
+    if fs.limiter != nil {
+        fs.limiter.jitter = percent
+    }
+}
+
+// throttle waits the jittered delay between result pages, unless the
+// requests-per-minute limiter is already pacing requests
+func (fs *FacebookScraper) throttle(ctx context.Context) error {
+    if fs.limiter != nil {
+        return nil
+    }
+    return SleepContext(ctx, Jitter(fs.rateLimit, fs.jitterPercent))
+}
+
+// SetDetectLanguage enables guessing each post's language before filtering,
+// which filter Languages relies on
+func (fs *FacebookScraper) SetDetectLanguage(enabled bool) {
+    fs.detectLang = enabled
+}
+
+// SetBackends chooses the backend used to fetch posts and an optional
+// fallback tried when it fails or returns nothing
+func (fs *FacebookScraper) SetBackends(backend, fallback GroupScraper) {
+    if backend != nil {
+        fs.backend = backend
+    }
+    fs.fallback = fallback
+}
+
+// SetScrapeComments enables parsing and storing the top-level comments of
+// each post
+func (fs *FacebookScraper) SetScrapeComments(enabled bool) {
+    fs.comments = enabled
+}
+
+// SetMaxPages bounds how many pages of older posts are followed per URL
+func (fs *FacebookScraper) SetMaxPages(pages int) {
+    if pages > 0 {
+        fs.maxPages = pages
+    }
+}
+
+// SetRawHTMLDir saves every fetched page and its response metadata to dir
+// for debugging selectors; an empty dir disables it
+func (fs *FacebookScraper) SetRawHTMLDir(dir string) {
+    fs.rawHTMLDir = dir
+}
+
+// SetSelectors overrides the CSS selector lists used to find posts; empty
+// lists keep the built-in defaults
+func (fs *FacebookScraper) SetSelectors(selectors Selectors) {
+    if len(selectors.Post) > 0 {
+        fs.selectors.Post = selectors.Post
+    }
+    if len(selectors.Author) > 0 {
+        fs.selectors.Author = selectors.Author
+    }
+    if len(selectors.Content) > 0 {
+        fs.selectors.Content = selectors.Content
+    }
+}
+
+// SetUserAgents rotates requests through the given user agents, keeping the
+// constructor's user agent when the list is empty
+func (fs *FacebookScraper) SetUserAgents(agents []string) {
+    if len(agents) > 0 {
+        fs.userAgents = NewUserAgentPool(agents, "")
+    }
+    fs.logger.Infof("Rotating through %d user agent(s)", fs.userAgents.Size())
+}
+
+// AddAccounts registers extra cookies files to rotate through when the
+// active account gets rate limited or checkpointed
+func (fs *FacebookScraper) AddAccounts(cookiesFiles []string, cooldown time.Duration) error {
+    return fs.authManager.AddAccounts(cookiesFiles, cooldown)
+}
+
+// SetProxies configures the proxies used for all Facebook requests
+func (fs *FacebookScraper) SetProxies(proxies []string) error {
+    return fs.authManager.SetProxies(proxies)
 }
 
 func (fs *FacebookScraper) Initialize() error {
@@ -63,6 +239,10 @@ func (fs *FacebookScraper) Initialize() error {
         return fmt.Errorf("failed to load cookies: %w", err)
     }
 
+    if err := fs.authManager.ValidateCookieFormat(); err != nil {
+        return fmt.Errorf("invalid cookies file: %w", err)
+    }
+
     // Validate authentication
     if err := fs.authManager.ValidateAuth(); err != nil {
         return fmt.Errorf("authentication validation failed: %w", err)
@@ -72,12 +252,108 @@ func (fs *FacebookScraper) Initialize() error {
     return nil
 }
 
-func (fs *FacebookScraper) ScrapeGroup(groupID string) error {
+// ScrapeGroup scrapes a group, saves the posts that pass the filter and
+// returns the resulting statistics
+func (fs *FacebookScraper) ScrapeGroup(ctx context.Context, groupID string) (*ScrapingStats, error) {
+    return fs.scrapeSource(ctx, SourceGroup, groupID, fs.fetchGroupPosts)
+}
+
+// ScrapePage scrapes a Facebook Page through the same parsing, filter and
+// storage pipeline as groups. Pages are always fetched over HTTP.
+func (fs *FacebookScraper) ScrapePage(ctx context.Context, pageID string) (*ScrapingStats, error) {
+    return fs.scrapeSource(ctx, SourcePage, pageID, fs.FetchPagePosts)
+}
+
+func (fs *FacebookScraper) scrapeSource(ctx context.Context, sourceType, sourceID string,
+    fetch func(context.Context, string) ([]types.ScrapedPost, error)) (*ScrapingStats, error) {
     startTime := time.Now()
     stats := &ScrapingStats{}
 
-    fs.logger.Infof("Starting to scrape group: %s", groupID)
+    fs.logger.Infof("Starting to scrape %s: %s (account c_user=%s)", sourceType, sourceID, fs.authManager.ActiveUserID())
+
+    posts, err := fetch(ctx, sourceID)
+    if err != nil {
+        return nil, err
+    }
+
+    if fs.detectLang {
+        for i := range posts {
+            posts[i].Language = detectLanguage(posts[i].Content)
+        }
+    }
+
+    // Apply filters and save posts
+    filteredPosts, filterStats, err := BatchFilter(posts, fs.filterFor(sourceID))
+    if err != nil {
+        return nil, err
+    }
+    fs.logger.Infof("Filter results: %s", filterStats.String())
+
+    if fs.isDryRun() {
+        // Report what would have been saved; SavedPosts counts the matches
+        fs.recordDryRun(DryRunResult{
+            SourceType: sourceType,
+            SourceID:   sourceID,
+            Scraped:    len(posts),
+            Posts:      filteredPosts,
+            Filter:     filterStats,
+        })
+        stats.SavedPosts = len(filteredPosts)
+    } else {
+        fs.collectOutput(filteredPosts)
+        fs.savePosts(ctx, sourceType, sourceID, filteredPosts, stats)
+    }
+
+    stats.TotalPosts = len(posts)
+    stats.SkippedPosts = len(posts) - len(filteredPosts)
+    stats.ProcessingTime = time.Since(startTime)
+
+    fs.logger.Infof("Scraping completed for %s %s: %+v", sourceType, sourceID, stats)
+    return stats, nil
+}
+
+// savePosts stores the posts and their comments, counting them as saved or
+// errored in stats
+func (fs *FacebookScraper) savePosts(ctx context.Context, sourceType, sourceID string, posts []types.ScrapedPost, stats *ScrapingStats) {
+    // Save to database in a single transaction
+    dbPosts := make([]*models.Post, 0, len(posts))
+    for _, post := range posts {
+        dbPosts = append(dbPosts, fs.convertToDBPost(post, post.GroupID))
+    }
+    inserted, err := fs.db.SavePosts(ctx, dbPosts)
+    if err != nil {
+        fs.logger.Errorf("Failed to save %d posts for %s %s: %v", len(dbPosts), sourceType, sourceID, err)
+        stats.ErrorPosts = len(dbPosts)
+        return
+    }
+    stats.SavedPosts = len(dbPosts)
+    fs.notifyNewPosts(ctx, inserted)
+
+    for _, post := range posts {
+        if len(post.Comments) == 0 {
+            continue
+        }
+        if err := fs.db.SaveComments(ctx, post.GroupID, post.ID, fs.convertToDBComments(post)); err != nil {
+            fs.logger.Warnf("Failed to save comments for post %s: %v", post.ID, err)
+        }
+    }
+}
+
+// fetchGroupPosts fetches posts with the configured backend, retrying with the
+// fallback backend when the primary fails or finds nothing
+func (fs *FacebookScraper) fetchGroupPosts(ctx context.Context, groupID string) ([]types.ScrapedPost, error) {
+    posts, err := fs.backend.FetchGroupPosts(ctx, groupID)
+    if (err == nil && len(posts) > 0) || fs.fallback == nil || ctx.Err() != nil {
+        return posts, err
+    }
 
+    fs.logger.Warnf("Primary backend returned no posts for group %s (%v), trying fallback backend", groupID, err)
+    return fs.fallback.FetchGroupPosts(ctx, groupID)
+}
+
+// FetchGroupPosts implements GroupScraper over plain HTTP, trying the mobile
+// and desktop group URLs in turn
+func (fs *FacebookScraper) FetchGroupPosts(ctx context.Context, groupID string) ([]types.ScrapedPost, error) {
     // Try multiple URL strategies for better success rate
     urls := []string{
         fmt.Sprintf("%s/groups/%s", fs.mobileURL, groupID),
@@ -85,94 +361,217 @@ func (fs *FacebookScraper) ScrapeGroup(groupID string) error {
         fmt.Sprintf("%s/groups/%s", fs.baseURL, groupID),
     }
 
+    return fs.fetchURLs(ctx, SourceGroup, groupID, urls)
+}
+
+// FetchPagePosts fetches a Page's posts over HTTP, trying the mobile and
+// desktop Page URLs in turn
+func (fs *FacebookScraper) FetchPagePosts(ctx context.Context, pageID string) ([]types.ScrapedPost, error) {
+    urls := []string{
+        fmt.Sprintf("%s/%s", fs.mobileURL, pageID),
+        fmt.Sprintf("%s/%s/posts", fs.mobileURL, pageID),
+        fmt.Sprintf("%s/%s/posts", fs.baseURL, pageID),
+    }
+
+    posts, err := fs.fetchURLs(ctx, SourcePage, pageID, urls)
+    for i := range posts {
+        posts[i].SourceType = SourcePage
+        posts[i].URL = fs.generatePagePostURL(posts[i].ID, pageID)
+    }
+    return posts, err
+}
+
+// fetchURLs tries each URL strategy until one yields posts
+func (fs *FacebookScraper) fetchURLs(ctx context.Context, sourceType, sourceID string, urls []string) ([]types.ScrapedPost, error) {
     var posts []types.ScrapedPost
     var lastError error
+    loginServed := false
 
     for i, url := range urls {
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+
         fs.logger.Infof("Attempting scrape with URL strategy %d: %s", i+1, url)
         
-        groupPosts, err := fs.scrapeGroupURL(url, groupID)
+        sourcePosts, err := fs.scrapeGroupURL(ctx, url, sourceID, fmt.Sprintf("%s-%d", sourceID, i+1))
+        if errors.Is(err, ErrAuthExpired) {
+            // Other URL strategies will hit the same login wall
+            fs.recordScrapeError(ctx, sourceID, fmt.Sprintf("url %d", i+1), url, err)
+            return nil, fmt.Errorf("%s %s: %w", sourceType, sourceID, err)
+        }
         if err != nil {
             fs.logger.Warnf("URL strategy %d failed: %v", i+1, err)
+            fs.recordScrapeError(ctx, sourceID, fmt.Sprintf("url %d", i+1), url, err)
+            loginServed = loginServed || errors.Is(err, errLoginPage)
             lastError = err
             continue
         }
 
-        if len(groupPosts) > 0 {
-            posts = groupPosts
+        if len(sourcePosts) > 0 {
+            posts = sourcePosts
             fs.logger.Infof("Successfully scraped %d posts using URL strategy %d", len(posts), i+1)
             break
         }
     }
 
     if len(posts) == 0 {
-        return fmt.Errorf("all scraping strategies failed, last error: %v", lastError)
+        // A login form in the body may only affect one URL, so it counts as
+        // an expired session once no strategy got through
+        if loginServed {
+            return nil, fmt.Errorf("%s %s: %w: login page served", sourceType, sourceID, ErrAuthExpired)
+        }
+        return nil, fmt.Errorf("all scraping strategies failed, last error: %v", lastError)
     }
 
-    // Apply filters and save posts
-    filter := &types.PostFilter{
-        MinLikes: 1000,
-        DaysBack: 5,
+    return posts, nil
+}
+
+// errLoginPage is returned by scrapeGroupURL when a URL answers with a login
+// form instead of posts
+var errLoginPage = errors.New("login page served")
+
+// StatusError is returned when Facebook answers with an unexpected HTTP status
+type StatusError struct {
+    StatusCode int
+}
+
+func (e *StatusError) Error() string {
+    return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// recordScrapeError stores a failed URL strategy attempt so it can be
+// inspected later through the API. Nothing is stored without a database, as
+// in dry-run mode.
+func (fs *FacebookScraper) recordScrapeError(ctx context.Context, sourceID, strategy, url string, err error) {
+    if fs.db == nil || fs.isDryRun() || ctx.Err() != nil {
+        return
     }
 
-    filteredPosts, filterStats := fs.applyFilters(posts, filter)
-    fs.logger.Infof("Filter results: %s", filterStats.String())
+    scrapeErr := &models.ScrapeError{
+        GroupID:  sourceID,
+        Strategy: strategy,
+        URL:      url,
+        Error:    err.Error(),
+    }
+    var statusErr *StatusError
+    if errors.As(err, &statusErr) {
+        scrapeErr.StatusCode = statusErr.StatusCode
+    }
 
-    // Save to database
-    for _, post := range filteredPosts {
-        dbPost := fs.convertToDBPost(post, groupID)
-        if err := fs.db.SavePost(dbPost); err != nil {
-            fs.logger.Errorf("Failed to save post %s: %v", post.ID, err)
-            stats.ErrorPosts++
-        } else {
-            stats.SavedPosts++
+    if err := fs.db.SaveScrapeError(ctx, scrapeErr); err != nil {
+        fs.logger.Warnf("Failed to record scrape error for %s: %v", sourceID, err)
+    }
+}
+
+// scrapeGroupURL parses posts starting at url and follows the "see more"
+// cursor to older pages until they predate the DaysBack cutoff or maxPages
+// is reached. label names the strategy in raw HTML dumps.
+func (fs *FacebookScraper) scrapeGroupURL(ctx context.Context, url, groupID, label string) ([]types.ScrapedPost, error) {
+    var posts []types.ScrapedPost
+
+    pageURL := url
+    for page := 1; page <= fs.maxPages && pageURL != ""; page++ {
+        body, err := fs.fetchHTML(ctx, pageURL, pageLabel(label, page))
+        if err != nil {
+            if page == 1 || errors.Is(err, ErrAuthExpired) {
+                return nil, err
+            }
+            fs.logger.Warnf("Stopping pagination at page %d: %v", page, err)
+            break
         }
+
+        // Parse HTML and extract posts
+        pagePosts, err := fs.parseGroupPosts(body, groupID)
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse posts: %w", err)
+        }
+
+        if len(pagePosts) == 0 && page == 1 && containsLoginMarkers(body) {
+            return nil, fmt.Errorf("%w for %s", errLoginPage, url)
+        }
+        posts = append(posts, pagePosts...)
+
+        // Rate limiting
+        if err := fs.throttle(ctx); err != nil {
+            return nil, err
+        }
+
+        if fs.pastCutoff(groupID, pagePosts) {
+            fs.logger.Debugf("Page %d reaches past the %d day window, stopping", page, fs.filterFor(groupID).DaysBack)
+            break
+        }
+        pageURL = fs.nextPageURL(body, pageURL)
     }
 
-    stats.TotalPosts = len(posts)
-    stats.SkippedPosts = len(posts) - len(filteredPosts)
-    stats.ProcessingTime = time.Since(startTime)
+    return fs.deduplicatePosts(posts), nil
+}
 
-    fs.logger.Infof("Scraping completed for group %s: %+v", groupID, stats)
-    return nil
+// pastCutoff reports whether the page contains posts older than the DaysBack
+// window of the source's filter, so older pages can be skipped
+func (fs *FacebookScraper) pastCutoff(sourceID string, posts []types.ScrapedPost) bool {
+    filter := fs.filterFor(sourceID)
+    if filter == nil || filter.DaysBack <= 0 {
+        return false
+    }
+
+    cutoff := time.Now().AddDate(0, 0, -filter.DaysBack)
+    for _, post := range posts {
+        if post.PostTime.Before(cutoff) {
+            return true
+        }
+    }
+    return false
 }
 
-func (fs *FacebookScraper) scrapeGroupURL(url, groupID string) ([]types.ScrapedPost, error) {
+// fetchHTML downloads a Facebook page, rotating accounts on rate limits and
+// checkpoints and reporting ErrAuthExpired when redirected to a login page
+func (fs *FacebookScraper) fetchHTML(ctx context.Context, url, label string) (string, error) {
     fs.logger.Debugf("Scraping URL: %s", url)
 
-    req, err := http.NewRequest("GET", url, nil)
+    req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
     if err != nil {
-        return nil, fmt.Errorf("failed to create request: %w", err)
+        return "", fmt.Errorf("failed to create request: %w", err)
     }
 
     // Set comprehensive headers to mimic real browser
     fs.setRequestHeaders(req)
 
+    if err := fs.limiter.Wait(ctx); err != nil {
+        return "", err
+    }
+
     resp, err := fs.client.Do(req)
     if err != nil {
-        return nil, fmt.Errorf("failed to execute request: %w", err)
+        return "", fmt.Errorf("failed to execute request: %w", err)
     }
     defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+    body, err := readBody(resp)
+    if err != nil {
+        return "", fmt.Errorf("failed to read response body: %w", err)
     }
+    fs.saveRawHTML(label, url, resp, body)
 
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        return nil, fmt.Errorf("failed to read response body: %w", err)
+    // Rate limits and checkpoints are tied to the account, so move on to the next one
+    if resp.StatusCode == http.StatusTooManyRequests || strings.Contains(resp.Request.URL.Path, "checkpoint") {
+        reason := fmt.Sprintf("status %d at %s", resp.StatusCode, resp.Request.URL.Path)
+        if _, err := fs.authManager.RotateAccount(reason); err != nil {
+            return "", fmt.Errorf("account %s blocked (%s): %w", fs.authManager.ActiveUserID(), reason, err)
+        }
+        return "", fmt.Errorf("account blocked (%s), switched accounts: %w", reason, &StatusError{StatusCode: resp.StatusCode})
     }
 
-    // Parse HTML and extract posts
-    posts, err := fs.parseGroupPosts(string(body), groupID)
-    if err != nil {
-        return nil, fmt.Errorf("failed to parse posts: %w", err)
+    if resp.StatusCode != http.StatusOK {
+        return "", &StatusError{StatusCode: resp.StatusCode}
     }
 
-    // Rate limiting
-    time.Sleep(fs.rateLimit)
+    // Expired cookies get a login page served with a 200
+    if isLoginURL(resp.Request.URL) {
+        return "", fmt.Errorf("%w: redirected to %s", ErrAuthExpired, resp.Request.URL.Path)
+    }
 
-    return posts, nil
+    return string(body), nil
 }
 
 func (fs *FacebookScraper) parseGroupPosts(html, groupID string) ([]types.ScrapedPost, error) {
@@ -181,19 +580,12 @@ func (fs *FacebookScraper) parseGroupPosts(html, groupID string) ([]types.Scrape
         return nil, fmt.Errorf("failed to parse HTML: %w", err)
     }
 
+    fs.cacheGroupName(doc, groupID)
+
     var posts []types.ScrapedPost
 
     // Multiple selectors for different Facebook layouts
-    postSelectors := []string{
-        "div[data-ft]",                    // Classic mobile posts
-        "article[data-ft]",                // Article format posts
-        "div[role='article']",             // Semantic article posts
-        "div[id*='story']",                // Story format posts
-        ".story_body_container",           // Story body containers
-        "div[data-testid='story-subtitle']", // New format posts
-    }
-
-    for _, selector := range postSelectors {
+    for _, selector := range fs.selectors.Post {
         doc.Find(selector).Each(func(i int, s *goquery.Selection) {
             post := fs.extractPostData(s, groupID)
             if post.ID != "" && fs.isValidPost(post) {
@@ -218,7 +610,8 @@ func (fs *FacebookScraper) parseGroupPosts(html, groupID string) ([]types.Scrape
 
 func (fs *FacebookScraper) extractPostData(s *goquery.Selection, groupID string) types.ScrapedPost {
     post := types.ScrapedPost{
-        GroupID: groupID,
+        GroupID:    groupID,
+        SourceType: SourceGroup,
     }
 
     // Extract post ID
@@ -231,6 +624,8 @@ func (fs *FacebookScraper) extractPostData(s *goquery.Selection, groupID string)
         }
     }
 
+    post.IsSponsored = isSponsored(s)
+
     // Extract author information
     post.AuthorName = fs.extractAuthorName(s)
     post.AuthorID = fs.extractAuthorID(s)
@@ -240,6 +635,13 @@ func (fs *FacebookScraper) extractPostData(s *goquery.Selection, groupID string)
 
     // Extract engagement metrics
     post.LikesCount = fs.extractLikesCount(s)
+    post.Reactions = fs.extractReactions(s)
+    if len(post.Reactions) > 0 {
+        post.LikesCount = 0
+        for _, count := range post.Reactions {
+            post.LikesCount += count
+        }
+    }
     post.CommentsCount = fs.extractCommentsCount(s)
     post.SharesCount = fs.extractSharesCount(s)
 
@@ -250,9 +652,15 @@ func (fs *FacebookScraper) extractPostData(s *goquery.Selection, groupID string)
     post.Images = fs.extractImages(s)
     post.Videos = fs.extractVideos(s)
     post.Links = fs.extractLinks(s)
-    post.Mentions = fs.extractMentions(post.Content)
+    post.LinkPreview = fs.extractLinkPreview(s)
+    post.MentionLinks = fs.extractMentionLinks(s)
+    post.Mentions = mergeMentions(fs.extractMentions(post.Content), post.MentionLinks)
     post.Hashtags = fs.extractHashtags(post.Content)
 
+    if fs.comments {
+        post.Comments = fs.extractComments(s)
+    }
+
     // Determine post type
     post.PostType = fs.determinePostType(post)
     post.MediaCount = len(post.Images) + len(post.Videos)
@@ -263,6 +671,43 @@ func (fs *FacebookScraper) extractPostData(s *goquery.Selection, groupID string)
     return post
 }
 
+// sponsoredSelectors match the label and ad links Facebook adds to sponsored
+// posts
+var sponsoredSelectors = []string{
+    "[aria-label='Sponsored']",
+    "[data-testid='story-sponsored-label']",
+    "a[href*='/ads/about']",
+    "a[href*='/ads/preferences']",
+}
+
+// sponsoredLabels are the visible headers of ads and injected suggestions
+var sponsoredLabels = []string{"Sponsored", "Suggested for you"}
+
+// isSponsored reports whether a post container is an ad or a suggested post
+// rather than part of the group or Page feed
+func isSponsored(s *goquery.Selection) bool {
+    for _, selector := range sponsoredSelectors {
+        if s.Find(selector).Length() > 0 {
+            return true
+        }
+    }
+
+    // Labels are short elements of their own; matching them exactly keeps
+    // posts that merely mention the words
+    sponsored := false
+    s.Find("span, a, h3, h4").EachWithBreak(func(i int, elem *goquery.Selection) bool {
+        text := cleanContent(elem.Text())
+        for _, label := range sponsoredLabels {
+            if strings.EqualFold(text, label) {
+                sponsored = true
+                return false
+            }
+        }
+        return true
+    })
+    return sponsored
+}
+
 func (fs *FacebookScraper) extractPostIDFromDataFt(dataFt string) string {
     // Parse JSON-like data-ft attribute
     var ftData map[string]interface{}
@@ -286,17 +731,9 @@ func (fs *FacebookScraper) extractPostIDFromDataFt(dataFt string) string {
 
 func (fs *FacebookScraper) extractAuthorName(s *goquery.Selection) string {
     // Multiple selectors for author name
-    selectors := []string{
-        "h3 a",
-        ".actor a",
-        "[data-hovercard] strong",
-        "strong a",
-        ".profileLink",
-    }
-
-    for _, selector := range selectors {
-        if name := s.Find(selector).First().Text(); name != "" {
-            return strings.TrimSpace(name)
+    for _, selector := range fs.selectors.Author {
+        if name := cleanContent(s.Find(selector).First().Text()); name != "" {
+            return name
         }
     }
 
@@ -304,79 +741,148 @@ func (fs *FacebookScraper) extractAuthorName(s *goquery.Selection) string {
 }
 
 func (fs *FacebookScraper) extractAuthorID(s *goquery.Selection) string {
+    // The author's name links to their profile, by numeric ID or username
+    for _, selector := range fs.selectors.Author {
+        link := s.Find(selector).First()
+        if link.Length() > 0 && !link.Is("a") {
+            link = link.Closest("a")
+        }
+        if href, exists := link.Attr("href"); exists {
+            if id := fs.extractUserIDFromURL(href); id != "" {
+                return id
+            }
+            if username := profileUsername(href); username != "" {
+                return username
+            }
+        }
+    }
+
+    var authorID string
+
     // Look for profile links
-    s.Find("a[href*='/profile.php'], a[href*='/user/']").Each(func(i int, link *goquery.Selection) {
+    s.Find("a[href*='/profile.php'], a[href*='/user/']").EachWithBreak(func(i int, link *goquery.Selection) bool {
         if href, exists := link.Attr("href"); exists {
             if id := fs.extractUserIDFromURL(href); id != "" {
-                return
+                authorID = id
+                return false
             }
         }
+        return true
     })
 
-    return ""
+    return authorID
 }
 
 func (fs *FacebookScraper) extractPostContent(s *goquery.Selection) string {
     // Multiple selectors for post content
-    selectors := []string{
-        ".userContent",
-        "[data-testid='post_message']",
-        ".story_body_container p",
-        ".text_exposed_root",
-        "p",
-    }
-
-    for _, selector := range selectors {
-        if content := s.Find(selector).First().Text(); content != "" {
-            return strings.TrimSpace(content)
+    for _, selector := range fs.selectors.Content {
+        if content := cleanContent(s.Find(selector).First().Text()); content != "" {
+            return content
         }
     }
 
     return ""
 }
 
+// zeroWidth matches invisible characters Facebook puts between spans
+var zeroWidth = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\u2060", "", "\ufeff", "")
+
+// cleanContent unescapes HTML entities left in extracted text, drops
+// zero-width characters and collapses whitespace, including newlines and
+// non-breaking spaces, into single spaces
+func cleanContent(text string) string {
+    text = html.UnescapeString(text)
+    text = zeroWidth.Replace(text)
+    return strings.Join(strings.Fields(text), " ")
+}
+
 func (fs *FacebookScraper) extractLikesCount(s *goquery.Selection) int {
     // Look for like counts in various formats
     patterns := []string{
-        `(\d+)\s*likes?`,
-        `(\d+)\s*reactions?`,
-        `(\d+)\s*👍`,
-        `(\d+)\s*❤️`,
+        countPattern + `\s*likes?`,
+        countPattern + `\s*reactions?`,
+        countPattern + `\s*👍`,
+        countPattern + `\s*❤️`,
     }
 
     text := s.Text()
     for _, pattern := range patterns {
         re := regexp.MustCompile(pattern)
         if matches := re.FindStringSubmatch(text); len(matches) > 1 {
-            if count, err := strconv.Atoi(matches[1]); err == nil {
+            if count := parseAbbreviatedCount(matches[1]); count > 0 {
                 return count
             }
         }
     }
 
     // Look for like count in specific elements
-    s.Find("a[href*='reaction'], span[data-testid*='like']").Each(func(i int, elem *goquery.Selection) {
+    likes := 0
+    s.Find("a[href*='reaction'], span[data-testid*='like']").EachWithBreak(func(i int, elem *goquery.Selection) bool {
         text := elem.Text()
         if count := fs.extractNumberFromText(text); count > 0 {
-            return
+            likes = count
+            return false
         }
+        return true
     })
 
-    return 0
+    return likes
+}
+
+// extractReactions reads per-type reaction counts from the tooltip and aria
+// labels of the reaction icons, e.g. "Love: 1.2K people" or "345 Haha"
+func (fs *FacebookScraper) extractReactions(s *goquery.Selection) map[string]int {
+    kinds := strings.Join(reactionTypes, "|")
+    patterns := []*regexp.Regexp{
+        regexp.MustCompile(`(?i)^(` + kinds + `)\s*:?\s*` + countPattern),
+        regexp.MustCompile(`(?i)^` + countPattern + `\s*(` + kinds + `)`),
+    }
+
+    reactions := make(map[string]int)
+    s.Find("[aria-label], [title]").Each(func(i int, elem *goquery.Selection) {
+        label, exists := elem.Attr("aria-label")
+        if !exists {
+            label, _ = elem.Attr("title")
+        }
+        label = strings.TrimSpace(label)
+
+        for j, re := range patterns {
+            matches := re.FindStringSubmatch(label)
+            if len(matches) < 3 {
+                continue
+            }
+
+            kind, count := matches[1], matches[2]
+            if j == 1 {
+                kind, count = matches[2], matches[1]
+            }
+            kind = strings.ToLower(kind)
+            // The same icon can be labelled twice; keep the largest count
+            if n := parseAbbreviatedCount(count); n > reactions[kind] {
+                reactions[kind] = n
+            }
+            break
+        }
+    })
+
+    if len(reactions) == 0 {
+        return nil
+    }
+    return reactions
 }
 
 func (fs *FacebookScraper) extractCommentsCount(s *goquery.Selection) int {
     patterns := []string{
-        `(\d+)\s*comments?`,
-        `(\d+)\s*replies?`,
-        `(\d+)\s*💬`,
+        countPattern + `\s*comments?`,
+        countPattern + `\s*replies?`,
+        countPattern + `\s*💬`,
     }
 
     text := s.Text()
     for _, pattern := range patterns {
         re := regexp.MustCompile(pattern)
         if matches := re.FindStringSubmatch(text); len(matches) > 1 {
-            if count, err := strconv.Atoi(matches[1]); err == nil {
+            if count := parseAbbreviatedCount(matches[1]); count > 0 {
                 return count
             }
         }
@@ -387,16 +893,16 @@ func (fs *FacebookScraper) extractCommentsCount(s *goquery.Selection) int {
 
 func (fs *FacebookScraper) extractSharesCount(s *goquery.Selection) int {
     patterns := []string{
-        `(\d+)\s*shares?`,
-        `(\d+)\s*shared`,
-        `(\d+)\s*🔄`,
+        countPattern + `\s*shares?`,
+        countPattern + `\s*shared`,
+        countPattern + `\s*🔄`,
     }
 
     text := s.Text()
     for _, pattern := range patterns {
         re := regexp.MustCompile(pattern)
         if matches := re.FindStringSubmatch(text); len(matches) > 1 {
-            if count, err := strconv.Atoi(matches[1]); err == nil {
+            if count := parseAbbreviatedCount(matches[1]); count > 0 {
                 return count
             }
         }
@@ -406,43 +912,135 @@ func (fs *FacebookScraper) extractSharesCount(s *goquery.Selection) int {
 }
 
 func (fs *FacebookScraper) extractTimestamp(s *goquery.Selection) time.Time {
+    var postTime time.Time
+
     // Look for timestamp in various formats
-    s.Find("abbr[data-utime], time, [data-testid='story-subtitle'] a").Each(func(i int, elem *goquery.Selection) {
+    s.Find("abbr, time, [data-testid='story-subtitle'] a").EachWithBreak(func(i int, elem *goquery.Selection) bool {
         // Unix timestamp
         if utime, exists := elem.Attr("data-utime"); exists {
             if timestamp, err := strconv.ParseInt(utime, 10, 64); err == nil {
-                return
+                postTime = time.Unix(timestamp, 0)
+                return false
             }
         }
 
         // ISO datetime
         if datetime, exists := elem.Attr("datetime"); exists {
             if t, err := time.Parse(time.RFC3339, datetime); err == nil {
-                return
+                postTime = t
+                return false
+            }
+        }
+
+        // Full date in the tooltip of abbr elements
+        if title, exists := elem.Attr("title"); exists {
+            if t, ok := parseTimestampTitle(title); ok {
+                postTime = t
+                return false
             }
         }
 
         // Relative time text
         text := elem.Text()
         if t := fs.parseRelativeTime(text); !t.IsZero() {
-            return
+            postTime = t
+            return false
         }
+        return true
     })
 
+    if !postTime.IsZero() {
+        return postTime
+    }
+
     return time.Now() // Fallback to current time
 }
 
+// timestampTitleLayouts are the date formats of the tooltips Facebook puts on
+// post timestamps, e.g. "Monday, March 4, 2024 at 3:04 PM"
+var timestampTitleLayouts = []string{
+    "Monday, January 2, 2006 at 3:04 PM",
+    "January 2, 2006 at 3:04 PM",
+    "Monday, 2 January 2006 at 15:04",
+    "2 January 2006 at 15:04",
+}
+
+// parseTimestampTitle parses a timestamp tooltip in the local time zone
+func parseTimestampTitle(title string) (time.Time, bool) {
+    title = strings.Join(strings.Fields(title), " ")
+    for _, layout := range timestampTitleLayouts {
+        if t, err := time.ParseInLocation(layout, title, time.Local); err == nil {
+            return t, true
+        }
+    }
+    return time.Time{}, false
+}
+
+// extractComments parses the top-level comments rendered with a post. Replies
+// are nested inside their parent comment and are skipped.
+func (fs *FacebookScraper) extractComments(s *goquery.Selection) []types.Comment {
+    var comments []types.Comment
+
+    selectors := []string{
+        "div[data-sigil='comment']",                      // Mobile comments
+        "div[data-testid='UFI2Comment/root_depth_0']",    // Classic desktop comments
+        "div[role='article'][aria-label^='Comment by']",  // New desktop layout
+    }
+
+    for _, selector := range selectors {
+        s.Find(selector).Each(func(i int, c *goquery.Selection) {
+            // Skip replies, which sit inside another comment
+            if c.ParentsFiltered(selector).Length() > 0 {
+                return
+            }
+
+            comment := types.Comment{
+                AuthorName: cleanContent(c.Find("a strong, h3 a, a[role='link'] span").First().Text()),
+                AuthorID:   fs.extractAuthorID(c),
+                Text:       fs.extractCommentText(c),
+                Timestamp:  fs.extractTimestamp(c),
+            }
+            if label, exists := c.Find("[aria-label*='reaction']").First().Attr("aria-label"); exists {
+                comment.LikesCount = fs.extractNumberFromText(label)
+            }
+
+            if comment.Text != "" {
+                comments = append(comments, comment)
+            }
+        })
+
+        if len(comments) > 0 {
+            break
+        }
+    }
+
+    return comments
+}
+
+func (fs *FacebookScraper) extractCommentText(c *goquery.Selection) string {
+    selectors := []string{
+        "[data-sigil='comment-body']",
+        "[data-testid='comment-body']",
+        "div[dir='auto']",
+    }
+
+    for _, selector := range selectors {
+        if text := cleanContent(c.Find(selector).First().Text()); text != "" {
+            return text
+        }
+    }
+
+    return ""
+}
+
 func (fs *FacebookScraper) extractImages(s *goquery.Selection) []types.MediaItem {
     var images []types.MediaItem
 
     s.Find("img").Each(func(i int, img *goquery.Selection) {
         if src, exists := img.Attr("src"); exists && fs.isValidImageURL(src) {
-            alt, _ := img.Attr("alt")
-            images = append(images, types.MediaItem{
-                URL:         src,
-                Type:        "image",
-                Description: alt,
-            })
+            if item, ok := imageItem(img, src); ok {
+                images = append(images, item)
+            }
         }
     })
 
@@ -454,10 +1052,7 @@ func (fs *FacebookScraper) extractVideos(s *goquery.Selection) []types.MediaItem
 
     s.Find("video, [data-testid='video']").Each(func(i int, video *goquery.Selection) {
         if src, exists := video.Attr("src"); exists {
-            videos = append(videos, types.MediaItem{
-                URL:  src,
-                Type: "video",
-            })
+            videos = append(videos, videoItem(video, src))
         }
     })
 
@@ -481,6 +1076,76 @@ func (fs *FacebookScraper) extractLinks(s *goquery.Selection) []string {
     return links
 }
 
+// linkPreviewSelectors find the attachment card of a link post, most
+// specific first
+var linkPreviewSelectors = []string{
+    "[data-testid='story-attachment']",
+    "._6ks",  // desktop attachment
+    "._5rgu", // mobile attachment
+    "a[href*='l.facebook.com/l.php']",
+}
+
+// extractLinkPreview parses the title, description and domain of a shared
+// link's preview card, or returns nil when the post has none
+func (fs *FacebookScraper) extractLinkPreview(s *goquery.Selection) *types.LinkPreview {
+    for _, selector := range linkPreviewSelectors {
+        card := s.Find(selector).First()
+        if card.Length() == 0 {
+            continue
+        }
+
+        link := card
+        if !card.Is("a[href]") {
+            link = card.Find("a[href]").First()
+        }
+        href, _ := link.Attr("href")
+        target := externalLinkTarget(href)
+        if target == "" {
+            continue
+        }
+
+        preview := &types.LinkPreview{
+            URL:         fs.cleanURL(target),
+            Title:       cleanContent(card.Find("[data-testid='link-title'], ._6m6, .mbs, h3, h4").First().Text()),
+            Description: cleanContent(card.Find("[data-testid='link-description'], ._6m7, ._6m8").First().Text()),
+            Domain:      cleanContent(card.Find("[data-testid='link-domain'], ._6lz, ._6mb").First().Text()),
+        }
+        if preview.Title == "" && preview.Description == "" {
+            continue
+        }
+        if preview.Domain == "" {
+            if u, err := url.Parse(preview.URL); err == nil {
+                preview.Domain = strings.TrimPrefix(u.Hostname(), "www.")
+            }
+        }
+        if src, exists := card.Find("img").First().Attr("src"); exists && fs.isValidImageURL(src) {
+            preview.Image = src
+        }
+        return preview
+    }
+
+    return nil
+}
+
+// externalLinkTarget returns the outside URL an anchor points to, unwrapping
+// Facebook's l.php redirect, or "" for links within Facebook
+func externalLinkTarget(href string) string {
+    u, err := url.Parse(href)
+    if err != nil || u.Host == "" {
+        return ""
+    }
+    if strings.HasPrefix(u.Host, "l.facebook.com") || strings.HasPrefix(u.Host, "lm.facebook.com") {
+        if target := u.Query().Get("u"); target != "" {
+            return externalLinkTarget(target)
+        }
+        return ""
+    }
+    if strings.HasSuffix(u.Host, "facebook.com") || strings.HasSuffix(u.Host, "fbcdn.net") {
+        return ""
+    }
+    return u.String()
+}
+
 func (fs *FacebookScraper) extractMentions(content string) []string {
     re := regexp.MustCompile(`@([a-zA-Z0-9._]+)`)
     matches := re.FindAllStringSubmatch(content, -1)
@@ -495,6 +1160,112 @@ func (fs *FacebookScraper) extractMentions(content string) []string {
     return mentions
 }
 
+// nonProfilePaths are first path segments of Facebook links that don't
+// point to a person or Page
+var nonProfilePaths = map[string]bool{
+    "groups": true, "hashtag": true, "events": true, "watch": true, "photo.php": true,
+    "photo": true, "story.php": true, "permalink.php": true, "l.php": true, "sharer": true,
+    "sharer.php": true, "marketplace": true, "stories": true, "reel": true, "media": true,
+    "search": true, "home.php": true, "login": true, "ufi": true,
+}
+
+// extractMentionLinks pairs each profile link inside the post content with
+// its display name
+func (fs *FacebookScraper) extractMentionLinks(s *goquery.Selection) []types.Mention {
+    var content *goquery.Selection
+    for _, selector := range fs.selectors.Content {
+        if found := s.Find(selector).First(); found.Length() > 0 && cleanContent(found.Text()) != "" {
+            content = found
+            break
+        }
+    }
+    if content == nil {
+        return nil
+    }
+
+    var mentions []types.Mention
+    seen := make(map[string]bool)
+    content.Find("a[href]").Each(func(i int, link *goquery.Selection) {
+        href, _ := link.Attr("href")
+        name := strings.TrimPrefix(cleanContent(link.Text()), "@")
+        if name == "" || strings.HasPrefix(name, "#") {
+            return
+        }
+
+        userID := fs.extractUserIDFromURL(href)
+        if userID == "" {
+            userID = profileUsername(href)
+            if userID == "" {
+                return
+            }
+        }
+        if seen[userID] {
+            return
+        }
+        seen[userID] = true
+
+        mentions = append(mentions, types.Mention{
+            Name:   name,
+            UserID: userID,
+            URL:    fs.profileURL(href),
+        })
+    })
+
+    return mentions
+}
+
+// profileUsername returns the username of a vanity profile link such as
+// https://www.facebook.com/jane.doe, or "" for other links
+func profileUsername(href string) string {
+    u, err := url.Parse(href)
+    if err != nil || (u.Host != "" && !strings.HasSuffix(u.Host, "facebook.com")) {
+        return ""
+    }
+    segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+    if len(segments) != 1 || segments[0] == "" || nonProfilePaths[strings.ToLower(segments[0])] {
+        return ""
+    }
+    return segments[0]
+}
+
+// profileURL makes a profile link absolute and drops every query parameter
+// except the ID of profile.php links
+func (fs *FacebookScraper) profileURL(href string) string {
+    u, err := url.Parse(href)
+    if err != nil {
+        return href
+    }
+    if u.Host == "" {
+        if base, err := url.Parse(fs.baseURL); err == nil {
+            u = base.ResolveReference(u)
+        }
+    }
+    if id := u.Query().Get("id"); id != "" && strings.HasSuffix(u.Path, "profile.php") {
+        u.RawQuery = url.Values{"id": {id}}.Encode()
+    } else {
+        u.RawQuery = ""
+    }
+    u.Fragment = ""
+    return u.String()
+}
+
+// mergeMentions adds the names of linked mentions to the @handles found in
+// the text, keeping Mentions a flat list of everyone mentioned
+func mergeMentions(handles []string, links []types.Mention) []string {
+    mentions := handles
+    seen := make(map[string]bool)
+    for _, handle := range handles {
+        seen[strings.ToLower(handle)] = true
+    }
+    for _, link := range links {
+        if !seen[strings.ToLower(link.Name)] {
+            seen[strings.ToLower(link.Name)] = true
+            mentions = append(mentions, link.Name)
+        }
+    }
+    return mentions
+}
+
 func (fs *FacebookScraper) extractHashtags(content string) []string {
     re := regexp.MustCompile(`#([a-zA-Z0-9_]+)`)
     matches := re.FindAllStringSubmatch(content, -1)
@@ -510,6 +1281,9 @@ func (fs *FacebookScraper) extractHashtags(content string) []string {
 }
 
 func (fs *FacebookScraper) determinePostType(post types.ScrapedPost) string {
+    if len(post.Images) > 0 && len(post.Videos) > 0 {
+        return "mixed"
+    }
     if len(post.Videos) > 0 {
         return "video"
     }
@@ -519,18 +1293,15 @@ func (fs *FacebookScraper) determinePostType(post types.ScrapedPost) string {
     if len(post.Links) > 0 {
         return "link"
     }
-    if len(post.Images) > 0 && len(post.Videos) > 0 {
-        return "mixed"
-    }
     return "text"
 }
 
 // Helper functions
 func (fs *FacebookScraper) setRequestHeaders(req *http.Request) {
-    req.Header.Set("User-Agent", fs.userAgent)
+    req.Header.Set("User-Agent", fs.userAgents.Next())
     req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
     req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-    req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+    req.Header.Set("Accept-Encoding", acceptEncoding)
     req.Header.Set("DNT", "1")
     req.Header.Set("Connection", "keep-alive")
     req.Header.Set("Upgrade-Insecure-Requests", "1")
@@ -574,43 +1345,126 @@ func (fs *FacebookScraper) extractUserIDFromURL(href string) string {
 }
 
 func (fs *FacebookScraper) extractNumberFromText(text string) int {
-    re := regexp.MustCompile(`\d+`)
+    re := regexp.MustCompile(countPattern)
     if match := re.FindString(text); match != "" {
-        if num, err := strconv.Atoi(match); err == nil {
-            return num
-        }
+        return parseAbbreviatedCount(match)
     }
     return 0
 }
 
+// parseAbbreviatedCount converts engagement counts as Facebook displays them
+// ("999", "1,234", "1.2K", "3.4M", "2B") into an integer
+func parseAbbreviatedCount(s string) int {
+    s = strings.ToLower(strings.TrimSpace(s))
+    s = strings.ReplaceAll(s, ",", "")
+
+    multiplier := 1.0
+    switch {
+    case strings.HasSuffix(s, "k"):
+        multiplier = 1e3
+    case strings.HasSuffix(s, "m"):
+        multiplier = 1e6
+    case strings.HasSuffix(s, "b"):
+        multiplier = 1e9
+    }
+    if multiplier > 1 {
+        s = strings.TrimSpace(s[:len(s)-1])
+    }
+
+    num, err := strconv.ParseFloat(s, 64)
+    if err != nil {
+        return 0
+    }
+
+    return int(math.Round(num * multiplier))
+}
+
 func (fs *FacebookScraper) parseRelativeTime(text string) time.Time {
-    now := time.Now()
+    return relativeTime(text, time.Now())
+}
+
+// relativeUnits maps the unit words and abbreviations of relative timestamps
+// ("5 mins", "2 hrs", "3d") to their length
+var relativeUnits = map[string]time.Duration{
+    "s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+    "m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+    "h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+    "d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+    "w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "wks": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+    "mo": 30 * 24 * time.Hour, "month": 30 * 24 * time.Hour, "months": 30 * 24 * time.Hour,
+    "y": 365 * 24 * time.Hour, "yr": 365 * 24 * time.Hour, "yrs": 365 * 24 * time.Hour, "year": 365 * 24 * time.Hour, "years": 365 * 24 * time.Hour,
+}
+
+var relativePattern = regexp.MustCompile(`(\d+)\s*([a-z]+)`)
+
+// relativeTime resolves a relative timestamp such as "2 hrs" or "Yesterday at
+// 3:04 PM" against now, returning the zero time for text it doesn't know
+func relativeTime(text string, now time.Time) time.Time {
     text = strings.ToLower(strings.TrimSpace(text))
 
-    // Handle various relative time formats
-    patterns := map[string]time.Duration{
-        `(\d+)\s*min`:   time.Minute,
-        `(\d+)\s*hour`:  time.Hour,
-        `(\d+)\s*day`:   24 * time.Hour,
-        `(\d+)\s*week`:  7 * 24 * time.Hour,
-        `(\d+)\s*month`: 30 * 24 * time.Hour,
+    // Phrases that don't carry a number
+    switch {
+    case text == "now", strings.HasPrefix(text, "just now"), strings.HasPrefix(text, "just posted"),
+        strings.Contains(text, "few seconds"):
+        return now
+    case strings.HasPrefix(text, "yesterday"):
+        if clock, ok := clockTime(text); ok {
+            y, m, d := now.AddDate(0, 0, -1).Date()
+            return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, now.Location())
+        }
+        return now.Add(-24 * time.Hour)
     }
 
-    for pattern, unit := range patterns {
-        re := regexp.MustCompile(pattern)
-        if matches := re.FindStringSubmatch(text); len(matches) > 1 {
-            if num, err := strconv.Atoi(matches[1]); err == nil {
-                return now.Add(-time.Duration(num) * unit)
-            }
+    // "a minute ago" / "an hour ago" mean one unit
+    text = regexp.MustCompile(`^an?\s+`).ReplaceAllString(text, "1 ")
+
+    for _, match := range relativePattern.FindAllStringSubmatch(text, -1) {
+        unit, ok := relativeUnits[match[2]]
+        if !ok {
+            continue
+        }
+        if num, err := strconv.Atoi(match[1]); err == nil {
+            return now.Add(-time.Duration(num) * unit)
         }
     }
 
     return time.Time{}
 }
 
-func (fs *FacebookScraper) isValidImageURL(url string) bool {
-    return strings.Contains(url, "facebook.com") && 
-           (strings.Contains(url, ".jpg") || strings.Contains(url, ".png") || strings.Contains(url, ".gif"))
+// clockTime parses the time of day in text such as "yesterday at 3:04 pm"
+func clockTime(text string) (time.Time, bool) {
+    i := strings.Index(text, " at ")
+    if i < 0 {
+        return time.Time{}, false
+    }
+
+    clock := strings.TrimSpace(text[i+len(" at "):])
+    for _, layout := range []string{"3:04 pm", "3:04pm", "15:04"} {
+        if t, err := time.Parse(layout, clock); err == nil {
+            return t, true
+        }
+    }
+    return time.Time{}, false
+}
+
+func (fs *FacebookScraper) isValidImageURL(src string) bool {
+    if !isFacebookImageHost(src) {
+        return false
+    }
+
+    // CDN URLs usually carry signed query strings and no file extension
+    if strings.Contains(src, "fbcdn.net") || strings.Contains(src, "fbsbx.com") {
+        return true
+    }
+
+    u, err := url.Parse(src)
+    if err != nil {
+        return false
+    }
+    path := strings.ToLower(u.Path)
+    return strings.HasSuffix(path, ".jpg") || strings.HasSuffix(path, ".jpeg") ||
+           strings.HasSuffix(path, ".png") || strings.HasSuffix(path, ".gif") ||
+           strings.HasSuffix(path, ".webp")
 }
 
 func (fs *FacebookScraper) cleanURL(href string) string {
@@ -634,19 +1488,30 @@ func (fs *FacebookScraper) generatePostURL(postID, groupID string) string {
     return fmt.Sprintf("%s/groups/%s", fs.baseURL, groupID)
 }
 
+func (fs *FacebookScraper) generatePagePostURL(postID, pageID string) string {
+    if postID != "" {
+        return fmt.Sprintf("%s/%s/posts/%s", fs.baseURL, pageID, postID)
+    }
+    return fmt.Sprintf("%s/%s", fs.baseURL, pageID)
+}
+
 func (fs *FacebookScraper) isValidPost(post types.ScrapedPost) bool {
     return post.ID != "" && 
            (post.Content != "" || len(post.Images) > 0 || len(post.Videos) > 0) &&
            post.AuthorName != ""
 }
 
+// deduplicatePosts drops repeats of the same post in the same group; a post
+// shared in several groups is kept once per group, matching the database key
 func (fs *FacebookScraper) deduplicatePosts(posts []types.ScrapedPost) []types.ScrapedPost {
-    seen := make(map[string]bool)
+    type postKey struct{ groupID, postID string }
+    seen := make(map[postKey]bool)
     var unique []types.ScrapedPost
 
     for _, post := range posts {
-        if !seen[post.ID] {
-            seen[post.ID] = true
+        key := postKey{post.GroupID, post.ID}
+        if !seen[key] {
+            seen[key] = true
             unique = append(unique, post)
         }
     }
@@ -670,69 +1535,140 @@ func (fs *FacebookScraper) parseAlternativeFormat(doc *goquery.Document, groupID
     return posts
 }
 
-func (fs *FacebookScraper) applyFilters(posts []types.ScrapedPost, filter *types.PostFilter) ([]types.ScrapedPost, types.FilterStats) {
-    var filtered []types.ScrapedPost
-    stats := types.FilterStats{
-        TotalPosts: len(posts),
-    }
-
-    cutoffTime := time.Now().AddDate(0, 0, -filter.DaysBack)
-
-    for _, post := range posts {
-        passedLikes := filter.MinLikes == 0 || post.LikesCount >= filter.MinLikes
-        passedTime := filter.DaysBack == 0 || post.PostTime.After(cutoffTime)
-
-        if !passedLikes {
-            stats.LikesFiltered++
-        }
-        if !passedTime {
-            stats.TimeFiltered++
-        }
-
-        if passedLikes && passedTime {
-            filtered = append(filtered, post)
-        }
-    }
-
-    stats.FilteredPosts = len(filtered)
-    return filtered, stats
-}
-
 func (fs *FacebookScraper) convertToDBPost(post types.ScrapedPost, groupID string) *models.Post {
     // Convert images and videos to JSON strings
     imagesJSON, _ := json.Marshal(post.Images)
     videosJSON, _ := json.Marshal(post.Videos)
+    var linkPreviewJSON, mentionLinksJSON []byte
+    if len(post.MentionLinks) > 0 {
+        mentionLinksJSON, _ = json.Marshal(post.MentionLinks)
+    }
+    if post.LinkPreview != nil {
+        linkPreviewJSON, _ = json.Marshal(post.LinkPreview)
+    }
+
+    // Keep the whole scraped post so fields can be re-derived without re-scraping
+    rawJSON, err := json.Marshal(post)
+    if err != nil {
+        fs.logger.Warnf("Failed to encode raw JSON of post %s: %v", post.ID, err)
+    }
+
+    groupName := fs.getGroupName(groupID)
+    if post.SourceType == SourceSearch {
+        groupName = fmt.Sprintf("Search: %s", post.SearchQuery)
+    }
 
     return &models.Post{
         GroupID:     groupID,
-        GroupName:   fs.getGroupName(groupID),
+        GroupName:   groupName,
         PostID:      post.ID,
         AuthorID:    post.AuthorID,
         AuthorName:  post.AuthorName,
         Content:     post.Content,
+        ContentHash: ContentHash(post.Content),
         PostURL:     post.URL,
         Timestamp:   post.PostTime,
         Likes:       post.LikesCount,
         Comments:    post.CommentsCount,
         Shares:      post.SharesCount,
         PostType:    post.PostType,
+        IsSponsored: post.IsSponsored,
+        Language:    post.Language,
+        SourceType:  post.SourceType,
+        SearchQuery: post.SearchQuery,
         ScrapedAt:   time.Now(),
         Images:      string(imagesJSON),
         Videos:      string(videosJSON),
         Mentions:    post.Mentions,
         Hashtags:    post.Hashtags,
         Links:       post.Links,
+        LinkPreview: string(linkPreviewJSON),
+        MentionLinks: string(mentionLinksJSON),
         MediaCount:  post.MediaCount,
+
+        ReactionBreakdown: post.Reactions,
+        RawJSON:           string(rawJSON),
     }
 }
 
+func (fs *FacebookScraper) convertToDBComments(post types.ScrapedPost) []*models.Comment {
+    comments := make([]*models.Comment, 0, len(post.Comments))
+    for _, c := range post.Comments {
+        comments = append(comments, &models.Comment{
+            GroupID:    post.GroupID,
+            PostID:     post.ID,
+            AuthorName: c.AuthorName,
+            AuthorID:   c.AuthorID,
+            Content:    c.Text,
+            Timestamp:  c.Timestamp,
+            Likes:      c.LikesCount,
+            ScrapedAt:  time.Now(),
+        })
+    }
+    return comments
+}
+
+// getGroupName returns the name parsed from the group's page, or a
+// placeholder when it couldn't be found
 func (fs *FacebookScraper) getGroupName(groupID string) string {
-    // This could be enhanced to fetch actual group names
-    // For now, return a placeholder
+    fs.namesMu.Lock()
+    defer fs.namesMu.Unlock()
+
+    if name, ok := fs.groupNames[groupID]; ok {
+        return name
+    }
     return fmt.Sprintf("Group_%s", groupID)
 }
 
+// cacheGroupName remembers the group name from the first page fetched for it
+func (fs *FacebookScraper) cacheGroupName(doc *goquery.Document, groupID string) {
+    fs.namesMu.Lock()
+    defer fs.namesMu.Unlock()
+
+    if _, ok := fs.groupNames[groupID]; ok {
+        return
+    }
+    if name := extractGroupName(doc); name != "" {
+        fs.logger.Debugf("Group %s is %q", groupID, name)
+        fs.groupNames[groupID] = name
+    }
+}
+
+var unreadCountPrefix = regexp.MustCompile(`^\(\d+\+?\)\s*`)
+
+// extractGroupName reads the group name from the header or page title,
+// e.g. "(20+) NETFLIX RECOMMENDATIONS | Facebook"
+func extractGroupName(doc *goquery.Document) string {
+    candidates := []string{
+        doc.Find("meta[property='og:title']").AttrOr("content", ""),
+        doc.Find("h1").First().Text(),
+        doc.Find("title").First().Text(),
+    }
+
+    for _, name := range candidates {
+        name = unreadCountPrefix.ReplaceAllString(strings.TrimSpace(name), "")
+        name = strings.TrimSpace(strings.TrimSuffix(name, "| Facebook"))
+
+        switch strings.ToLower(name) {
+        case "", "facebook", "log in or sign up to view", "log into facebook":
+            continue
+        }
+        return name
+    }
+
+    return ""
+}
+
 func (fs *FacebookScraper) Close() error {
+    // Shut down any browser backends
+    for _, backend := range []GroupScraper{fs.backend, fs.fallback} {
+        if closer, ok := backend.(io.Closer); ok && backend != GroupScraper(fs) {
+            if err := closer.Close(); err != nil {
+                fs.logger.Warnf("Failed to close scraper backend: %v", err)
+            }
+        }
+    }
+
     if fs.authManager != nil {
         return fs.authManager.SaveCookies()
     }
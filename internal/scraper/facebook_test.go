@@ -0,0 +1,397 @@
+package scraper
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/PuerkitoBio/goquery"
+
+    "facebook-scraper/pkg/types"
+)
+
+// parseFragment parses an HTML fragment for the extract* helpers
+func parseFragment(t *testing.T, fragment string) *goquery.Selection {
+    t.Helper()
+
+    doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+    if err != nil {
+        t.Fatalf("parse HTML: %v", err)
+    }
+    return doc.Selection
+}
+
+func TestPastCutoffUsesSourceFilter(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    if err := fs.SetFilter(&types.PostFilter{DaysBack: 5}); err != nil {
+        t.Fatal(err)
+    }
+    if err := fs.SetGroupFilters(map[string]*types.PostFilter{"archive": {DaysBack: 30}}); err != nil {
+        t.Fatal(err)
+    }
+
+    posts := []types.ScrapedPost{{ID: "1", PostTime: time.Now().AddDate(0, 0, -10)}}
+    if !fs.pastCutoff("news", posts) {
+        t.Error("10 day old post is within the default 5 day window")
+    }
+    if fs.pastCutoff("archive", posts) {
+        t.Error("10 day old post is past the group's 30 day window")
+    }
+}
+
+func TestExtractAuthorID(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+
+    tests := []struct {
+        name string
+        html string
+        want string
+    }{
+        {
+            "profile.php id",
+            `<div><h3><a href="/profile.php?id=123&amp;ref=group">Jane Doe</a></h3><p>Hello</p></div>`,
+            "123",
+        },
+        {
+            "user path",
+            `<div><h3><a href="/groups/99/user/456/">Jane Doe</a></h3></div>`,
+            "456",
+        },
+        {
+            "vanity link",
+            `<div><h3><a href="https://m.facebook.com/jane.doe?refid=18">Jane Doe</a></h3></div>`,
+            "jane.doe",
+        },
+        {
+            "author link before mentions",
+            `<div><h3><a href="/jane.doe">Jane Doe</a></h3><p>Thanks <a href="/profile.php?id=789">Bob</a></p></div>`,
+            "jane.doe",
+        },
+        {
+            "missing author",
+            `<div><p>No author here</p></div>`,
+            "",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := fs.extractAuthorID(parseFragment(t, tt.html)); got != tt.want {
+                t.Errorf("extractAuthorID() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestExtractTimestamp(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+
+    tests := []struct {
+        name string
+        html string
+        want time.Time
+    }{
+        {
+            "data-utime",
+            `<div><abbr data-utime="1709564640">Mar 4</abbr></div>`,
+            time.Unix(1709564640, 0),
+        },
+        {
+            "datetime",
+            `<div><time datetime="2024-03-04T15:04:00Z">Mar 4</time></div>`,
+            time.Date(2024, 3, 4, 15, 4, 0, 0, time.UTC),
+        },
+        {
+            "abbr title",
+            `<div><abbr title="Monday, March 4, 2024 at 3:04 PM">March 4</abbr></div>`,
+            time.Date(2024, 3, 4, 15, 4, 0, 0, time.Local),
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := fs.extractTimestamp(parseFragment(t, tt.html)); !got.Equal(tt.want) {
+                t.Errorf("extractTimestamp() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestExtractTimestampRelativeText(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+
+    got := fs.extractTimestamp(parseFragment(t, `<div><abbr>3 hours</abbr></div>`))
+    if want := time.Now().Add(-3 * time.Hour); got.Sub(want).Abs() > time.Minute {
+        t.Errorf("extractTimestamp() = %v, want about %v", got, want)
+    }
+}
+
+func TestExtractTimestampMissing(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+
+    got := fs.extractTimestamp(parseFragment(t, `<div><p>No time here</p></div>`))
+    if time.Since(got).Abs() > time.Minute {
+        t.Errorf("extractTimestamp() = %v, want the current time", got)
+    }
+}
+
+func TestParseAbbreviatedCount(t *testing.T) {
+    tests := []struct {
+        in   string
+        want int
+    }{
+        {"999", 999},
+        {"1.2K", 1200},
+        {"1.2k", 1200},
+        {"1,234", 1234},
+        {"12,345", 12345},
+        {"3.4M", 3400000},
+        {"3.4m", 3400000},
+        {"2B", 2000000000},
+        {" 15K ", 15000},
+        {"", 0},
+        {"K", 0},
+        {"lots", 0},
+        {"1.2.3K", 0},
+    }
+
+    for _, tt := range tests {
+        if got := parseAbbreviatedCount(tt.in); got != tt.want {
+            t.Errorf("parseAbbreviatedCount(%q) = %d, want %d", tt.in, got, tt.want)
+        }
+    }
+}
+
+func TestExtractNumberFromText(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+
+    tests := map[string]int{
+        "1.2K likes":      1200,
+        "12,345 comments": 12345,
+        "3 shares":        3,
+        "2.5M views":      2500000,
+        "4 K reactions":   4000,
+        "no count":        0,
+        "12 members":      12,
+        "7 more":          7,
+        "3 Bob":           3,
+        "5 BIG reasons":   5,
+    }
+    for text, want := range tests {
+        if got := fs.extractNumberFromText(text); got != want {
+            t.Errorf("extractNumberFromText(%q) = %d, want %d", text, got, want)
+        }
+    }
+}
+
+func TestExtractReactions(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    post := parseFragment(t, `<div>
+<span aria-label="Love: 1.2K people"></span>
+<span title="345 Haha"></span>
+<span aria-label="Like: 2 Bob and others"></span>
+<span aria-label="7 Wow"></span>
+<span aria-label="Angry: 3 more"></span>
+</div>`)
+
+    want := map[string]int{"love": 1200, "haha": 345, "like": 2, "wow": 7, "angry": 3}
+    if got := fs.extractReactions(post); !reflect.DeepEqual(got, want) {
+        t.Errorf("extractReactions() = %v, want %v", got, want)
+    }
+}
+
+func TestRelativeTime(t *testing.T) {
+    now := time.Date(2024, 3, 4, 12, 30, 0, 0, time.UTC)
+
+    tests := []struct {
+        text string
+        want time.Time
+    }{
+        {"Just now", now},
+        {"now", now},
+        {"a few seconds ago", now},
+        {"30 secs", now.Add(-30 * time.Second)},
+        {"a minute ago", now.Add(-time.Minute)},
+        {"5m", now.Add(-5 * time.Minute)},
+        {"an hour ago", now.Add(-time.Hour)},
+        {"2 hrs", now.Add(-2 * time.Hour)},
+        {"3 hours", now.Add(-3 * time.Hour)},
+        {"3d", now.AddDate(0, 0, -3)},
+        {"2 days ago", now.AddDate(0, 0, -2)},
+        {"1w", now.AddDate(0, 0, -7)},
+        {"Yesterday", now.Add(-24 * time.Hour)},
+        {"Yesterday at 3:04 PM", time.Date(2024, 3, 3, 15, 4, 0, 0, time.UTC)},
+        {"Yesterday at 09:15", time.Date(2024, 3, 3, 9, 15, 0, 0, time.UTC)},
+        {"March 4 at 3:04 PM", time.Time{}},
+        {"Sponsored", time.Time{}},
+        {"", time.Time{}},
+    }
+
+    for _, tt := range tests {
+        if got := relativeTime(tt.text, now); !got.Equal(tt.want) {
+            t.Errorf("relativeTime(%q) = %v, want %v", tt.text, got, tt.want)
+        }
+    }
+}
+
+func TestDeterminePostType(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    image := types.MediaItem{URL: "https://scontent.xx.fbcdn.net/v/photo.jpg", Type: "image"}
+    video := types.MediaItem{URL: "https://video.xx.fbcdn.net/v/clip.mp4", Type: "video"}
+
+    tests := []struct {
+        name string
+        post types.ScrapedPost
+        want string
+    }{
+        {"image and video", types.ScrapedPost{Images: []types.MediaItem{image}, Videos: []types.MediaItem{video}}, "mixed"},
+        {"video", types.ScrapedPost{Videos: []types.MediaItem{video}}, "video"},
+        {"image", types.ScrapedPost{Images: []types.MediaItem{image}, Links: []string{"https://example.com"}}, "image"},
+        {"link", types.ScrapedPost{Links: []string{"https://example.com"}}, "link"},
+        {"text", types.ScrapedPost{Content: "Hello"}, "text"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := fs.determinePostType(tt.post); got != tt.want {
+                t.Errorf("determinePostType() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestIsValidImageURL(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+
+    tests := []struct {
+        src  string
+        want bool
+    }{
+        {"https://scontent.fnbo1-1.fna.fbcdn.net/v/t39.30808-6/412345_n.jpg?stp=dst-jpg_s640x640&_nc_cat=1&oh=00_AbC&oe=65F0", true},
+        {"https://scontent-lhr8-1.xx.fbcdn.net/v/t1.6435-9/12345?_nc_ht=scontent&oh=abc", true},
+        {"https://lookaside.fbsbx.com/lookaside/crawler/media/?media_id=123", true},
+        {"https://www.facebook.com/images/photo.png", true},
+        {"https://www.facebook.com/photo.php?fbid=123", false},
+        {"https://example.com/photo.jpg", false},
+        {"", false},
+    }
+
+    for _, tt := range tests {
+        if got := fs.isValidImageURL(tt.src); got != tt.want {
+            t.Errorf("isValidImageURL(%q) = %v, want %v", tt.src, got, tt.want)
+        }
+    }
+}
+
+func TestCleanContent(t *testing.T) {
+    tests := []struct {
+        name string
+        in   string
+        want string
+    }{
+        {"entities", "Fish &amp; chips &lt;3 &quot;best&quot; &#39;ever&#39;", `Fish & chips <3 "best" 'ever'`},
+        {"multi-line", "  First line\n\n\tSecond   line\r\nThird  ", "First line Second line Third"},
+        {"non-breaking spaces", "Hello\u00a0\u00a0world", "Hello world"},
+        {"zero-width", "Sum\u200bmer\u200d sale\ufeff\u2060", "Summer sale"},
+        {"empty", " \n\t ", ""},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := cleanContent(tt.in); got != tt.want {
+                t.Errorf("cleanContent(%q) = %q, want %q", tt.in, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestExtractPostContentAndAuthorAreCleaned(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    post := parseFragment(t, `<div><h3><a href="/jane.doe">Jane&nbsp;
+    Doe</a></h3><p>Tom &amp;amp; Jerry<br>
+    <span>reunion</span>&#8203; tonight</p></div>`)
+
+    if got, want := fs.extractPostContent(post), "Tom & Jerry reunion tonight"; got != want {
+        t.Errorf("extractPostContent() = %q, want %q", got, want)
+    }
+    if got, want := fs.extractAuthorName(post), "Jane Doe"; got != want {
+        t.Errorf("extractAuthorName() = %q, want %q", got, want)
+    }
+}
+
+func TestIsSponsored(t *testing.T) {
+    tests := []struct {
+        name string
+        html string
+        want bool
+    }{
+        {"aria label", `<div><h3><a href="/brand">Brand</a></h3><span aria-label="Sponsored"></span></div>`, true},
+        {"sponsored label", `<div><h3><a href="/brand">Brand</a></h3><span>Sponsored</span><p>Buy now</p></div>`, true},
+        {"suggested label", `<div><h4>Suggested for you</h4><p>Join this group</p></div>`, true},
+        {"ad link", `<div><a href="https://www.facebook.com/ads/about/?entry_product=ad_preferences">Why am I seeing this ad?</a></div>`, true},
+        {"mentions sponsored", `<div><h3><a href="/jane.doe">Jane</a></h3><p><span>Who sponsored the event?</span></p></div>`, false},
+        {"plain post", `<div><h3><a href="/jane.doe">Jane</a></h3><p>Hello group</p></div>`, false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := isSponsored(parseFragment(t, tt.html)); got != tt.want {
+                t.Errorf("isSponsored() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestExtractMentionLinks(t *testing.T) {
+    fs := newTestScraper(t, "https://www.facebook.com")
+    post := parseFragment(t, `<div>
+<h3><a href="/author.name">Author Name</a></h3>
+<p>Thanks <a href="/profile.php?id=100012345&amp;__cft__[0]=AZX&amp;ref=mention">Jane Doe</a>
+and <a href="https://m.facebook.com/john.smith?refid=18&amp;__tn__=R">@John Smith</a>
+for <a href="/hashtag/teamwork">#teamwork</a>, see <a href="/groups/123/posts/456">this post</a>,
+<a href="https://example.com/article">our article</a>
+and <a href="/profile.php?id=100012345">Jane again</a></p>
+</div>`)
+
+    want := []types.Mention{
+        {Name: "Jane Doe", UserID: "100012345", URL: "https://www.facebook.com/profile.php?id=100012345"},
+        {Name: "John Smith", UserID: "john.smith", URL: "https://m.facebook.com/john.smith"},
+    }
+    if got := fs.extractMentionLinks(post); !reflect.DeepEqual(got, want) {
+        t.Errorf("extractMentionLinks() = %+v, want %+v", got, want)
+    }
+}
+
+func TestExtractMentionLinksWithoutContent(t *testing.T) {
+    fs := newTestScraper(t, "https://www.facebook.com")
+    post := parseFragment(t, `<div><h3><a href="/author.name">Author Name</a></h3></div>`)
+
+    if got := fs.extractMentionLinks(post); got != nil {
+        t.Errorf("extractMentionLinks() = %+v, want nil", got)
+    }
+}
+
+func TestMergeMentions(t *testing.T) {
+    links := []types.Mention{{Name: "Jane Doe"}, {Name: "bob"}}
+    got := mergeMentions([]string{"Bob", "alice"}, links)
+    if want := []string{"Bob", "alice", "Jane Doe"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("mergeMentions() = %v, want %v", got, want)
+    }
+}
+
+func TestDeduplicatePostsPerGroup(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    posts := []types.ScrapedPost{
+        {ID: "1", GroupID: "a", Content: "first"},
+        {ID: "1", GroupID: "a", Content: "repeat on the next page"},
+        {ID: "1", GroupID: "b", Content: "shared into another group"},
+        {ID: "2", GroupID: "a", Content: "second"},
+    }
+
+    got := fs.deduplicatePosts(posts)
+    want := []types.ScrapedPost{posts[0], posts[2], posts[3]}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("deduplicatePosts() = %+v, want %+v", got, want)
+    }
+}
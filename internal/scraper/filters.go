@@ -1,85 +1,165 @@
 package scraper
 
 import (
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "regexp"
     "strings"
     "time"
     "facebook-scraper/pkg/types"
 )
 
-// ApplyFilter applies the filter to a single post
-func ApplyFilter(post types.ScrapedPost, filter *types.PostFilter) bool {
+// keywordPatterns are a filter's KeywordRegex and ExcludeRegex, compiled
+type keywordPatterns struct {
+    include []*regexp.Regexp
+    exclude []*regexp.Regexp
+}
+
+// compilePatterns validates the filter's keyword and reaction options and
+// compiles its regex patterns
+func compilePatterns(filter *types.PostFilter) (*keywordPatterns, error) {
+    switch filter.KeywordMatchMode {
+    case "", types.KeywordMatchAny, types.KeywordMatchAll:
+    default:
+        return nil, fmt.Errorf("invalid keyword match mode %q (want %q or %q)",
+            filter.KeywordMatchMode, types.KeywordMatchAny, types.KeywordMatchAll)
+    }
+    for kind := range filter.MinReactions {
+        if !isReactionType(kind) {
+            return nil, fmt.Errorf("invalid min_reactions type %q (want one of %s)", kind, strings.Join(reactionTypes, ", "))
+        }
+    }
+
+    patterns := &keywordPatterns{}
+    for _, pattern := range filter.KeywordRegex {
+        re, err := regexp.Compile(pattern)
+        if err != nil {
+            return nil, fmt.Errorf("invalid keyword_regex pattern %q: %w", pattern, err)
+        }
+        patterns.include = append(patterns.include, re)
+    }
+    for _, pattern := range filter.ExcludeRegex {
+        re, err := regexp.Compile(pattern)
+        if err != nil {
+            return nil, fmt.Errorf("invalid exclude_regex pattern %q: %w", pattern, err)
+        }
+        patterns.exclude = append(patterns.exclude, re)
+    }
+    return patterns, nil
+}
+
+func matchesAnyPattern(content string, patterns []*regexp.Regexp) bool {
+    for _, re := range patterns {
+        if re.MatchString(content) {
+            return true
+        }
+    }
+    return false
+}
+
+// ApplyFilter applies the filter to a single post and returns the criterion
+// that rejected it, or types.FilterPassed. Posts never pass a filter with
+// invalid regex patterns; BatchFilter reports those as errors.
+func ApplyFilter(post types.ScrapedPost, filter *types.PostFilter) types.FilterReason {
+    patterns, err := compilePatterns(filter)
+    if err != nil {
+        return types.FilterKeyword
+    }
+    return applyFilter(post, filter, patterns)
+}
+
+func applyFilter(post types.ScrapedPost, filter *types.PostFilter, patterns *keywordPatterns) types.FilterReason {
+    // Ads and suggestions aren't part of the feed being monitored
+    if filter.ExcludeSponsored && post.IsSponsored {
+        return types.FilterSponsored
+    }
+
     // Check likes threshold
     if filter.MinLikes > 0 && post.LikesCount < filter.MinLikes {
-        return false
+        return types.FilterLikes
     }
     
     if filter.MaxLikes > 0 && post.LikesCount > filter.MaxLikes {
-        return false
+        return types.FilterLikes
     }
     
     // Check comments threshold
     if filter.MinComments > 0 && post.CommentsCount < filter.MinComments {
-        return false
+        return types.FilterComments
     }
     
     // Check shares threshold
     if filter.MinShares > 0 && post.SharesCount < filter.MinShares {
-        return false
+        return types.FilterShares
+    }
+    
+    // Check combined engagement
+    if filter.MinTotalEngagement > 0 && totalEngagement(post) < filter.MinTotalEngagement {
+        return types.FilterEngagement
+    }
+    
+    // Check per-reaction thresholds; posts without a breakdown have none
+    for kind, min := range filter.MinReactions {
+        if min > 0 && post.Reactions[strings.ToLower(kind)] < min {
+            return types.FilterReaction
+        }
     }
     
     // Check time range
     if filter.DaysBack > 0 {
         cutoffTime := time.Now().AddDate(0, 0, -filter.DaysBack)
         if post.PostTime.Before(cutoffTime) {
-            return false
+            return types.FilterTime
         }
     }
     
     // Check custom date range
     if !filter.StartDate.IsZero() && post.PostTime.Before(filter.StartDate) {
-        return false
+        return types.FilterTime
     }
     
     if !filter.EndDate.IsZero() && post.PostTime.After(filter.EndDate) {
-        return false
-    }
-    
-    // Check keywords (include)
-    if len(filter.Keywords) > 0 {
-        hasKeyword := false
-        contentLower := strings.ToLower(post.Content)
-        for _, keyword := range filter.Keywords {
-            if strings.Contains(contentLower, strings.ToLower(keyword)) {
-                hasKeyword = true
-                break
-            }
-        }
-        if !hasKeyword {
-            return false
-        }
+        return types.FilterTime
     }
     
-    // Check excluded keywords
+    // Check excluded keywords and patterns first: exclusion always wins
     if len(filter.ExcludeKeywords) > 0 {
         contentLower := strings.ToLower(post.Content)
         for _, keyword := range filter.ExcludeKeywords {
             if strings.Contains(contentLower, strings.ToLower(keyword)) {
-                return false
+                return types.FilterExclude
             }
         }
     }
+    if matchesAnyPattern(post.Content, patterns.exclude) {
+        return types.FilterExclude
+    }
     
-    // Check group IDs
-    if len(filter.GroupIDs) > 0 {
+    // Check keywords and patterns (include)
+    if !matchesKeywords(post.Content, filter) {
+        return types.FilterKeyword
+    }
+    if len(patterns.include) > 0 && !matchesAnyPattern(post.Content, patterns.include) {
+        return types.FilterKeyword
+    }
+    
+    // Check source IDs: Page posts against PageIDs, group posts against
+    // GroupIDs. Search results aren't tied to a source, so they skip this.
+    sourceIDs := filter.GroupIDs
+    if post.SourceType == SourcePage {
+        sourceIDs = filter.PageIDs
+    }
+    if len(sourceIDs) > 0 && post.SourceType != SourceSearch {
         found := false
-        for _, groupID := range filter.GroupIDs {
-            if post.GroupID == groupID {
+        for _, sourceID := range sourceIDs {
+            if post.GroupID == sourceID {
                 found = true
                 break
             }
         }
         if !found {
-            return false
+            return types.FilterGroup
         }
     }
     
@@ -93,46 +173,172 @@ func ApplyFilter(post types.ScrapedPost, filter *types.PostFilter) bool {
             }
         }
         if !found {
-            return false
+            return types.FilterAuthor
         }
     }
     
-    return true
+    // Check hashtags and mentions
+    if len(filter.Hashtags) > 0 && !containsAnyTag(post.Hashtags, filter.Hashtags, "#") {
+        return types.FilterTag
+    }
+    if len(filter.Mentions) > 0 && !containsAnyTag(post.Mentions, filter.Mentions, "@") {
+        return types.FilterTag
+    }
+    
+    // Check media and links
+    mediaCount := mediaCount(post)
+    if filter.MinMediaCount > 0 && mediaCount < filter.MinMediaCount {
+        return types.FilterMedia
+    }
+    if filter.RequireMedia && mediaCount == 0 {
+        return types.FilterMedia
+    }
+    if filter.RequireLink && len(post.Links) == 0 {
+        return types.FilterMedia
+    }
+    
+    // Check post types
+    if len(filter.PostTypes) > 0 {
+        found := false
+        for _, postType := range filter.PostTypes {
+            if strings.EqualFold(post.PostType, postType) {
+                found = true
+                break
+            }
+        }
+        if !found {
+            return types.FilterPostType
+        }
+    }
+    
+    // Check languages; posts whose language couldn't be detected don't match
+    if len(filter.Languages) > 0 {
+        found := false
+        for _, language := range filter.Languages {
+            if post.Language != "" && strings.EqualFold(post.Language, language) {
+                found = true
+                break
+            }
+        }
+        if !found {
+            return types.FilterLanguage
+        }
+    }
+    
+    return types.FilterPassed
 }
 
-// BatchFilter applies filters to multiple posts and returns statistics
-func BatchFilter(posts []types.ScrapedPost, filter *types.PostFilter) ([]types.ScrapedPost, types.FilterStats) {
+// BatchFilter applies filters to multiple posts in one pass, counting each
+// rejected post under the criterion that rejected it. Regex patterns are
+// compiled once for the whole batch. With DedupeByContent only the most
+// engaged of the passing posts that share a ContentHash is kept, in the place
+// of the first copy.
+func BatchFilter(posts []types.ScrapedPost, filter *types.PostFilter) ([]types.ScrapedPost, types.FilterStats, error) {
+    patterns, err := compilePatterns(filter)
+    if err != nil {
+        return nil, types.FilterStats{}, err
+    }
+
     var filtered []types.ScrapedPost
     stats := types.FilterStats{
         TotalPosts: len(posts),
     }
-    
-    cutoffTime := time.Now().AddDate(0, 0, -filter.DaysBack)
+    copies := make(map[string]int) // content hash to index in filtered
     
     for _, post := range posts {
-        // Track individual filter reasons
-        passedLikes := filter.MinLikes == 0 || post.LikesCount >= filter.MinLikes
-        passedTime := filter.DaysBack == 0 || post.PostTime.After(cutoffTime)
-        passedKeywords := len(filter.Keywords) == 0 || containsAnyKeyword(post.Content, filter.Keywords)
-        
-        if !passedLikes {
-            stats.LikesFiltered++
+        reason := applyFilter(post, filter, patterns)
+        if reason != types.FilterPassed {
+            stats.Record(reason)
+            continue
         }
-        if !passedTime {
-            stats.TimeFiltered++
+
+        if filter.DedupeByContent {
+            if hash := ContentHash(post.Content); hash != "" {
+                if i, ok := copies[hash]; ok {
+                    stats.Record(types.FilterDuplicate)
+                    if totalEngagement(post) > totalEngagement(filtered[i]) {
+                        filtered[i] = post
+                    }
+                    continue
+                }
+                copies[hash] = len(filtered)
+            }
+        }
+
+        stats.Record(reason)
+        filtered = append(filtered, post)
+    }
+    
+    return filtered, stats, nil
+}
+
+// ContentHash is the hex SHA-256 of the post text after lowercasing and
+// collapsing whitespace, so reshares of the same text hash alike. Posts
+// without text get "" and are never treated as duplicates.
+func ContentHash(content string) string {
+    normalized := strings.Join(strings.Fields(strings.ToLower(content)), " ")
+    if normalized == "" {
+        return ""
+    }
+    sum := sha256.Sum256([]byte(normalized))
+    return hex.EncodeToString(sum[:])
+}
+
+// totalEngagement sums a post's likes, comments and shares
+func totalEngagement(post types.ScrapedPost) int {
+    return post.LikesCount + post.CommentsCount + post.SharesCount
+}
+
+// isReactionType reports whether kind names one of Facebook's reactions
+func isReactionType(kind string) bool {
+    for _, reaction := range reactionTypes {
+        if strings.EqualFold(kind, reaction) {
+            return true
         }
-        if !passedKeywords {
-            stats.KeywordFiltered++
+    }
+    return false
+}
+
+// containsAnyTag reports whether any of the post's tags matches a wanted
+// one, ignoring case and the leading # or @
+func containsAnyTag(tags, wanted []string, prefix string) bool {
+    for _, tag := range tags {
+        tag = strings.TrimPrefix(strings.TrimSpace(tag), prefix)
+        for _, w := range wanted {
+            if strings.EqualFold(tag, strings.TrimPrefix(strings.TrimSpace(w), prefix)) {
+                return true
+            }
         }
-        
-        // Apply full filter
-        if ApplyFilter(post, filter) {
-            filtered = append(filtered, post)
+    }
+    return false
+}
+
+// mediaCount is the number of images and videos in a post, trusting the
+// parsed MediaCount when it saw more than were extracted
+func mediaCount(post types.ScrapedPost) int {
+    count := len(post.Images) + len(post.Videos)
+    if post.MediaCount > count {
+        return post.MediaCount
+    }
+    return count
+}
+
+// matchesKeywords applies Keywords in the filter's match mode
+func matchesKeywords(content string, filter *types.PostFilter) bool {
+    if filter.KeywordMatchMode == types.KeywordMatchAll {
+        return containsAllKeywords(content, filter.Keywords)
+    }
+    return containsAnyKeyword(content, filter.Keywords)
+}
+
+func containsAllKeywords(content string, keywords []string) bool {
+    contentLower := strings.ToLower(content)
+    for _, keyword := range keywords {
+        if !strings.Contains(contentLower, strings.ToLower(keyword)) {
+            return false
         }
     }
-    
-    stats.FilteredPosts = len(filtered)
-    return filtered, stats
+    return true
 }
 
 func containsAnyKeyword(content string, keywords []string) bool {
@@ -0,0 +1,239 @@
+package scraper
+
+import (
+    "strings"
+    "testing"
+
+    "facebook-scraper/pkg/types"
+)
+
+func TestApplyFilterSourceIDs(t *testing.T) {
+    filter := &types.PostFilter{GroupIDs: []string{"tracked"}, PageIDs: []string{"brand"}}
+
+    tests := []struct {
+        name string
+        post types.ScrapedPost
+        want types.FilterReason
+    }{
+        {"tracked group", types.ScrapedPost{GroupID: "tracked", SourceType: SourceGroup}, types.FilterPassed},
+        {"other group", types.ScrapedPost{GroupID: "other", SourceType: SourceGroup}, types.FilterGroup},
+        {"tracked page", types.ScrapedPost{GroupID: "brand", SourceType: SourcePage}, types.FilterPassed},
+        {"page checked against page IDs", types.ScrapedPost{GroupID: "tracked", SourceType: SourcePage}, types.FilterGroup},
+        {"search result", types.ScrapedPost{GroupID: SearchGroupID, SourceType: SourceSearch}, types.FilterPassed},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := ApplyFilter(tt.post, filter); got != tt.want {
+                t.Errorf("ApplyFilter() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestApplyFilterMediaAndLinks(t *testing.T) {
+    image := types.MediaItem{URL: "https://scontent.xx.fbcdn.net/v/photo.jpg", Type: "image"}
+    text := types.ScrapedPost{Content: "Just text"}
+    withImage := types.ScrapedPost{Images: []types.MediaItem{image}}
+    withAlbum := types.ScrapedPost{Images: []types.MediaItem{image}, MediaCount: 4}
+    withLink := types.ScrapedPost{Links: []string{"https://example.com"}}
+
+    tests := []struct {
+        name   string
+        filter types.PostFilter
+        post   types.ScrapedPost
+        want   types.FilterReason
+    }{
+        {"require media without media", types.PostFilter{RequireMedia: true}, text, types.FilterMedia},
+        {"require media with image", types.PostFilter{RequireMedia: true}, withImage, types.FilterPassed},
+        {"min media count not met", types.PostFilter{MinMediaCount: 2}, withImage, types.FilterMedia},
+        {"min media count from parsed count", types.PostFilter{MinMediaCount: 3}, withAlbum, types.FilterPassed},
+        {"require link without link", types.PostFilter{RequireLink: true}, withImage, types.FilterMedia},
+        {"require link with link", types.PostFilter{RequireLink: true}, withLink, types.FilterPassed},
+        {"defaults keep everything", types.PostFilter{}, text, types.FilterPassed},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := ApplyFilter(tt.post, &tt.filter); got != tt.want {
+                t.Errorf("ApplyFilter() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestBatchFilterStatsPerReason(t *testing.T) {
+    filter := &types.PostFilter{
+        MinLikes:         100,
+        ExcludeSponsored: true,
+        Keywords:         []string{"sale"},
+        ExcludeKeywords:  []string{"spam"},
+        RequireMedia:     true,
+    }
+    image := []types.MediaItem{{URL: "https://scontent.xx.fbcdn.net/v/photo.jpg", Type: "image"}}
+    posts := []types.ScrapedPost{
+        {ID: "1", Content: "Big sale", LikesCount: 500, Images: image},
+        {ID: "2", Content: "Big sale", LikesCount: 500, Images: image, IsSponsored: true},
+        {ID: "3", Content: "Small sale", LikesCount: 10, Images: image},
+        {ID: "4", Content: "Nothing here", LikesCount: 500, Images: image},
+        {ID: "5", Content: "Sale spam", LikesCount: 500, Images: image},
+        {ID: "6", Content: "Sale without photos", LikesCount: 500},
+        {ID: "7", Content: "Another sale", LikesCount: 900, Images: image},
+    }
+
+    filtered, stats, err := BatchFilter(posts, filter)
+    if err != nil {
+        t.Fatalf("BatchFilter: %v", err)
+    }
+
+    want := types.FilterStats{
+        TotalPosts:        7,
+        FilteredPosts:     2,
+        SponsoredFiltered: 1,
+        LikesFiltered:     1,
+        KeywordFiltered:   1,
+        ExcludeFiltered:   1,
+        MediaFiltered:     1,
+    }
+    if stats != want {
+        t.Errorf("stats = %+v, want %+v", stats, want)
+    }
+    if len(filtered) != 2 || filtered[0].ID != "1" || filtered[1].ID != "7" {
+        t.Errorf("filtered = %+v, want posts 1 and 7", filtered)
+    }
+}
+
+func TestApplyFilterKeywordMatchMode(t *testing.T) {
+    both := types.ScrapedPost{Content: "Cheap FLIGHTS to Nairobi this weekend"}
+    one := types.ScrapedPost{Content: "Cheap hotels this weekend"}
+    none := types.ScrapedPost{Content: "Weather update"}
+    keywords := []string{"cheap", "flights"}
+
+    tests := []struct {
+        name string
+        mode string
+        post types.ScrapedPost
+        want types.FilterReason
+    }{
+        {"default any, one keyword", "", one, types.FilterPassed},
+        {"any, one keyword", types.KeywordMatchAny, one, types.FilterPassed},
+        {"any, no keyword", types.KeywordMatchAny, none, types.FilterKeyword},
+        {"all, every keyword", types.KeywordMatchAll, both, types.FilterPassed},
+        {"all, one keyword", types.KeywordMatchAll, one, types.FilterKeyword},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            filter := &types.PostFilter{Keywords: keywords, KeywordMatchMode: tt.mode}
+            if got := ApplyFilter(tt.post, filter); got != tt.want {
+                t.Errorf("ApplyFilter() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestApplyFilterExcludeWins(t *testing.T) {
+    post := types.ScrapedPost{Content: "Cheap flights, click this SCAM link"}
+
+    tests := []struct {
+        name   string
+        filter types.PostFilter
+    }{
+        {"exclude keyword over any", types.PostFilter{Keywords: []string{"cheap"}, ExcludeKeywords: []string{"scam"}}},
+        {"exclude keyword over all", types.PostFilter{Keywords: []string{"cheap", "flights"}, KeywordMatchMode: types.KeywordMatchAll, ExcludeKeywords: []string{"scam"}}},
+        {"exclude regex over include regex", types.PostFilter{KeywordRegex: []string{`(?i)cheap\s+flights`}, ExcludeRegex: []string{`(?i)\bscam\b`}}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := ApplyFilter(post, &tt.filter); got != types.FilterExclude {
+                t.Errorf("ApplyFilter() = %q, want %q", got, types.FilterExclude)
+            }
+        })
+    }
+}
+
+func TestApplyFilterKeywordRegex(t *testing.T) {
+    filter := &types.PostFilter{KeywordRegex: []string{`\bKES\s?\d+`, `(?i)giveaway`}}
+
+    if got := ApplyFilter(types.ScrapedPost{Content: "Now only KES 500"}, filter); got != types.FilterPassed {
+        t.Errorf("matching post: ApplyFilter() = %q, want passed", got)
+    }
+    if got := ApplyFilter(types.ScrapedPost{Content: "Now only 500 shillings"}, filter); got != types.FilterKeyword {
+        t.Errorf("non-matching post: ApplyFilter() = %q, want %q", got, types.FilterKeyword)
+    }
+}
+
+func TestBatchFilterInvalidOptions(t *testing.T) {
+    tests := []struct {
+        name    string
+        filter  types.PostFilter
+        wantErr string
+    }{
+        {"keyword regex", types.PostFilter{KeywordRegex: []string{"ok", "(unclosed"}}, "keyword_regex"},
+        {"exclude regex", types.PostFilter{ExcludeRegex: []string{"[a-"}}, "exclude_regex"},
+        {"match mode", types.PostFilter{KeywordMatchMode: "most"}, "keyword match mode"},
+    }
+
+    posts := []types.ScrapedPost{{ID: "1", Content: "anything"}}
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, _, err := BatchFilter(posts, &tt.filter)
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Fatalf("BatchFilter() error = %v, want one mentioning %q", err, tt.wantErr)
+            }
+            // A single post never passes a filter that can't be compiled
+            if got := ApplyFilter(posts[0], &tt.filter); got == types.FilterPassed {
+                t.Error("ApplyFilter() passed a post through an invalid filter")
+            }
+        })
+    }
+}
+
+func TestApplyFilterMinReactions(t *testing.T) {
+    filter := &types.PostFilter{MinReactions: map[string]int{"angry": 500, "Haha": 100}}
+
+    tests := []struct {
+        name string
+        post types.ScrapedPost
+        want types.FilterReason
+    }{
+        {"both thresholds met", types.ScrapedPost{Reactions: map[string]int{"angry": 800, "haha": 150, "like": 10}}, types.FilterPassed},
+        {"one threshold missed", types.ScrapedPost{Reactions: map[string]int{"angry": 800, "haha": 20}}, types.FilterReaction},
+        {"reaction missing from breakdown", types.ScrapedPost{Reactions: map[string]int{"haha": 150}}, types.FilterReaction},
+        {"no breakdown", types.ScrapedPost{LikesCount: 5000}, types.FilterReaction},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := ApplyFilter(tt.post, filter); got != tt.want {
+                t.Errorf("ApplyFilter() = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestBatchFilterMinReactions(t *testing.T) {
+    filter := &types.PostFilter{MinReactions: map[string]int{"love": 10}}
+    posts := []types.ScrapedPost{
+        {ID: "1", Reactions: map[string]int{"love": 12}},
+        {ID: "2", Reactions: map[string]int{"love": 3}},
+        {ID: "3"},
+    }
+
+    filtered, stats, err := BatchFilter(posts, filter)
+    if err != nil {
+        t.Fatalf("BatchFilter: %v", err)
+    }
+    if len(filtered) != 1 || filtered[0].ID != "1" {
+        t.Errorf("filtered = %+v, want post 1", filtered)
+    }
+    if stats.ReactionFiltered != 2 || stats.FilteredPosts != 1 {
+        t.Errorf("ReactionFiltered = %d, FilteredPosts = %d, want 2 and 1", stats.ReactionFiltered, stats.FilteredPosts)
+    }
+
+    _, _, err = BatchFilter(posts, &types.PostFilter{MinReactions: map[string]int{"furious": 1}})
+    if err == nil || !strings.Contains(err.Error(), "furious") {
+        t.Errorf("BatchFilter() with an unknown reaction: error = %v", err)
+    }
+}
@@ -0,0 +1,111 @@
+package scraper
+
+import (
+    "strings"
+    "unicode"
+)
+
+// scriptLanguages are scripts that identify a language on their own. Kana
+// comes before Han so Japanese text isn't taken for Chinese.
+var scriptLanguages = []struct {
+    script *unicode.RangeTable
+    lang   string
+}{
+    {unicode.Hiragana, "ja"},
+    {unicode.Katakana, "ja"},
+    {unicode.Hangul, "ko"},
+    {unicode.Han, "zh"},
+    {unicode.Thai, "th"},
+    {unicode.Greek, "el"},
+    {unicode.Hebrew, "he"},
+    {unicode.Arabic, "ar"},
+    {unicode.Devanagari, "hi"},
+    {unicode.Cyrillic, "ru"},
+}
+
+// languageStopwords are frequent short words of Latin-script languages that
+// are rare in the others
+var languageStopwords = map[string][]string{
+    "en": {"the", "and", "is", "are", "was", "this", "that", "with", "for", "you", "have", "not", "what", "just"},
+    "es": {"el", "los", "las", "y", "es", "que", "del", "por", "con", "una", "para", "pero", "muy", "esta"},
+    "fr": {"le", "les", "et", "est", "des", "une", "dans", "pour", "pas", "que", "avec", "sur", "mais", "très"},
+    "de": {"der", "die", "und", "ist", "nicht", "das", "ein", "eine", "mit", "auf", "ich", "sie", "auch", "sehr"},
+    "pt": {"o", "os", "e", "é", "não", "uma", "com", "para", "mas", "muito", "isso", "você", "mais", "está"},
+    "it": {"il", "gli", "e", "è", "non", "che", "una", "con", "per", "ma", "molto", "sono", "questo", "della"},
+    "nl": {"de", "het", "en", "is", "niet", "een", "met", "voor", "op", "ik", "zijn", "maar", "ook", "dat"},
+    "id": {"dan", "yang", "ini", "itu", "tidak", "dengan", "untuk", "ada", "saya", "dari", "akan", "sudah"},
+}
+
+// stopwordLanguages inverts languageStopwords
+var stopwordLanguages = func() map[string][]string {
+    index := make(map[string][]string)
+    for lang, words := range languageStopwords {
+        for _, word := range words {
+            index[word] = append(index[word], lang)
+        }
+    }
+    return index
+}()
+
+// detectLanguage guesses the ISO 639-1 code of text from its script, or for
+// Latin script from common words. It returns "" when the text is too short or
+// ambiguous to tell.
+func detectLanguage(text string) string {
+    var latin, other int
+    scriptCounts := make(map[string]int)
+    for _, r := range text {
+        if !unicode.IsLetter(r) {
+            continue
+        }
+        if unicode.Is(unicode.Latin, r) {
+            latin++
+            continue
+        }
+        other++
+        for _, sl := range scriptLanguages {
+            if unicode.Is(sl.script, r) {
+                scriptCounts[sl.lang]++
+                break
+            }
+        }
+    }
+
+    if other > latin {
+        // Any kana means Japanese, which also uses Han characters
+        if scriptCounts["ja"] > 0 {
+            return "ja"
+        }
+        best, bestCount := "", 0
+        for _, sl := range scriptLanguages {
+            if count := scriptCounts[sl.lang]; count > bestCount {
+                best, bestCount = sl.lang, count
+            }
+        }
+        return best
+    }
+
+    scores := make(map[string]int)
+    words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r)
+    })
+    for _, word := range words {
+        for _, lang := range stopwordLanguages[word] {
+            scores[lang]++
+        }
+    }
+
+    // Need two hits and a clear winner to avoid guessing from a single word
+    best, bestScore, runnerUp := "", 0, 0
+    for lang, score := range scores {
+        switch {
+        case score > bestScore:
+            best, bestScore, runnerUp = lang, score, bestScore
+        case score > runnerUp:
+            runnerUp = score
+        }
+    }
+    if bestScore < 2 || bestScore == runnerUp {
+        return ""
+    }
+    return best
+}
@@ -0,0 +1,51 @@
+package scraper
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+    tests := []struct {
+        text string
+        want string
+    }{
+        // Latin script, from common words
+        {"This is the best deal that you will find this week", "en"},
+        {"Esta es la mejor oferta que hay por aquí, pero es para los socios", "es"},
+        {"Le meilleur prix est dans les magasins avec une remise", "fr"},
+        {"Das ist nicht das Angebot, und ich bin auch sehr müde", "de"},
+        {"Isso não é uma promoção, mas está muito barato", "pt"},
+        {"Saya tidak tahu dan ini sudah habis", "id"},
+
+        // Other scripts, from the characters alone
+        {"Это лучшее предложение недели", "ru"},
+        {"今日はとても良い天気ですね", "ja"},
+        {"今天天气很好", "zh"},
+        {"오늘 날씨가 좋네요", "ko"},
+        {"مرحبا بكم في المجموعة", "ar"},
+        {"Καλημέρα σε όλους", "el"},
+        {"สวัสดีครับ", "th"},
+        {"שלום לכולם", "he"},
+        {"सभी को नमस्ते", "hi"},
+
+        // Mostly another script with some Latin
+        {"Новая цена: 500 KES за штуку", "ru"},
+
+        // Too little to go on
+        {"", ""},
+        {"12345 !!!", ""},
+        {"the", ""},
+        {"Nairobi Kampala Kigali", ""},
+    }
+
+    for _, tt := range tests {
+        if got := detectLanguage(tt.text); got != tt.want {
+            t.Errorf("detectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+        }
+    }
+}
+
+func TestDetectLanguageNeedsClearWinner(t *testing.T) {
+    // "e" is a stopword of both Italian and Portuguese, so neither gets ahead
+    if got := detectLanguage("Pizza e pasta e vino"); got != "" {
+        t.Errorf("detectLanguage() = %q, want no guess from shared words", got)
+    }
+}
@@ -0,0 +1,137 @@
+package scraper
+
+import (
+    "strconv"
+    "strings"
+
+    "github.com/PuerkitoBio/goquery"
+    "facebook-scraper/pkg/types"
+)
+
+// Attributes that carry media sizes, most reliable first. Lazy-loaded media
+// often only has the data- variants.
+var (
+    widthAttrs    = []string{"width", "data-width", "data-original-width"}
+    heightAttrs   = []string{"height", "data-height", "data-original-height"}
+    posterAttrs   = []string{"poster", "data-poster", "data-thumbnail", "data-thumbnail-src"}
+    durationAttrs = []string{"data-duration", "data-video-duration", "duration"}
+)
+
+// minImageDimension is the smallest declared width or height of a content
+// image. Emoji, reaction icons and avatars are all smaller.
+const minImageDimension = 64
+
+// spriteImagePaths are parts of the URLs Facebook serves emoji, reaction and
+// UI sprites from
+var spriteImagePaths = []string{
+    "/images/emoji.php/",
+    "/emoji.php/",
+    "/images/emoji/",
+    "/images/reaction/",
+    "/images/reactions/",
+    "/rsrc.php/",
+    "/images/assets_DO_NOT_HARDCODE/",
+}
+
+// imageItem builds the MediaItem of an <img>, or returns false when it isn't
+// a content image
+func imageItem(img *goquery.Selection, src string) (types.MediaItem, bool) {
+    alt, _ := img.Attr("alt")
+    item := types.MediaItem{
+        URL:         src,
+        Type:        "image",
+        Description: alt,
+        Width:       intAttr(img, widthAttrs),
+        Height:      intAttr(img, heightAttrs),
+    }
+    return item, isContentImage(item)
+}
+
+// videoItem builds the MediaItem of a <video> or video container, taking the
+// poster frame as its thumbnail
+func videoItem(video *goquery.Selection, src string) types.MediaItem {
+    item := types.MediaItem{
+        URL:      src,
+        Type:     "video",
+        Width:    intAttr(video, widthAttrs),
+        Height:   intAttr(video, heightAttrs),
+        Duration: videoDuration(video),
+    }
+    for _, attr := range posterAttrs {
+        if poster, ok := video.Attr(attr); ok && poster != "" {
+            item.Thumbnail = poster
+            break
+        }
+    }
+    if item.Thumbnail == "" {
+        // Containers show the poster as an image before the video loads
+        if poster, ok := video.Find("img[src]").First().Attr("src"); ok {
+            item.Thumbnail = poster
+        }
+    }
+    return item
+}
+
+// isContentImage rules out tracking pixels and emoji or sprite images, by
+// their declared size when known and otherwise by URL
+func isContentImage(item types.MediaItem) bool {
+    if (item.Width > 0 && item.Width < minImageDimension) || (item.Height > 0 && item.Height < minImageDimension) {
+        return false
+    }
+    for _, path := range spriteImagePaths {
+        if strings.Contains(item.URL, path) {
+            return false
+        }
+    }
+    return true
+}
+
+// intAttr returns the first of attrs that holds a size such as "640" or
+// "640px", or 0
+func intAttr(s *goquery.Selection, attrs []string) int {
+    for _, attr := range attrs {
+        value, ok := s.Attr(attr)
+        if !ok {
+            continue
+        }
+        value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+        if n, err := strconv.ParseFloat(value, 64); err == nil && n > 0 {
+            return int(n)
+        }
+    }
+    return 0
+}
+
+// videoDuration reads a video's length in seconds from its data attributes,
+// given either in seconds or as a clock like "1:02:03"
+func videoDuration(s *goquery.Selection) int {
+    for _, attr := range durationAttrs {
+        if value, ok := s.Attr(attr); ok {
+            if seconds := parseDuration(value); seconds > 0 {
+                return seconds
+            }
+        }
+    }
+    return 0
+}
+
+func parseDuration(value string) int {
+    value = strings.TrimSpace(value)
+    if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+        return int(seconds)
+    }
+
+    parts := strings.Split(value, ":")
+    if len(parts) < 2 || len(parts) > 3 {
+        return 0
+    }
+    seconds := 0
+    for _, part := range parts {
+        n, err := strconv.Atoi(part)
+        if err != nil || n < 0 {
+            return 0
+        }
+        seconds = seconds*60 + n
+    }
+    return seconds
+}
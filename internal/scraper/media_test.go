@@ -0,0 +1,113 @@
+package scraper
+
+import (
+    "reflect"
+    "testing"
+
+    "facebook-scraper/pkg/types"
+)
+
+func TestExtractImagesSizes(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    post := parseFragment(t, `<div>
+<img src="https://scontent.xx.fbcdn.net/v/photo1.jpg" width="640" height="480" alt="A beach">
+<img src="https://scontent.xx.fbcdn.net/v/photo2.jpg" data-width="1080px" data-height="720">
+<img src="https://scontent.xx.fbcdn.net/v/photo3.jpg">
+</div>`)
+
+    want := []types.MediaItem{
+        {URL: "https://scontent.xx.fbcdn.net/v/photo1.jpg", Type: "image", Description: "A beach", Width: 640, Height: 480},
+        {URL: "https://scontent.xx.fbcdn.net/v/photo2.jpg", Type: "image", Width: 1080, Height: 720},
+        {URL: "https://scontent.xx.fbcdn.net/v/photo3.jpg", Type: "image"},
+    }
+    if got := fs.extractImages(post); !reflect.DeepEqual(got, want) {
+        t.Errorf("extractImages() = %+v, want %+v", got, want)
+    }
+}
+
+func TestExtractVideosSizesAndPoster(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    post := parseFragment(t, `<div>
+<video src="https://video.xx.fbcdn.net/v/clip1.mp4" width="1280" height="720" poster="https://scontent.xx.fbcdn.net/v/poster1.jpg" data-duration="95"></video>
+<div data-testid="video" src="https://video.xx.fbcdn.net/v/clip2.mp4" data-original-width="640" data-original-height="360" data-video-duration="1:02:03">
+<img src="https://scontent.xx.fbcdn.net/v/poster2.jpg">
+</div>
+</div>`)
+
+    want := []types.MediaItem{
+        {URL: "https://video.xx.fbcdn.net/v/clip1.mp4", Type: "video", Width: 1280, Height: 720,
+            Thumbnail: "https://scontent.xx.fbcdn.net/v/poster1.jpg", Duration: 95},
+        {URL: "https://video.xx.fbcdn.net/v/clip2.mp4", Type: "video", Width: 640, Height: 360,
+            Thumbnail: "https://scontent.xx.fbcdn.net/v/poster2.jpg", Duration: 3723},
+    }
+    if got := fs.extractVideos(post); !reflect.DeepEqual(got, want) {
+        t.Errorf("extractVideos() = %+v, want %+v", got, want)
+    }
+}
+
+func TestParseDuration(t *testing.T) {
+    tests := map[string]int{
+        "95":      95,
+        "95.7":    95,
+        "1:35":    95,
+        "1:02:03": 3723,
+        " 0:07 ":  7,
+        "":        0,
+        "1:2:3:4": 0,
+        "1:xx":    0,
+        "-1:30":   0,
+    }
+    for in, want := range tests {
+        if got := parseDuration(in); got != want {
+            t.Errorf("parseDuration(%q) = %d, want %d", in, got, want)
+        }
+    }
+}
+
+func TestExtractImagesSkipsPixelsAndEmoji(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    post := parseFragment(t, `<div>
+<img src="https://www.facebook.com/tr?id=123&ev=PageView&noscript=1.gif" width="1" height="1">
+<img src="https://static.xx.fbcdn.net/images/emoji.php/v9/t4c/1/16/1f602.png" alt="😂">
+<img src="https://static.xx.fbcdn.net/rsrc.php/v3/yO/r/like.png">
+<img src="https://scontent.xx.fbcdn.net/v/avatar.jpg" width="40" height="40">
+<img src="https://scontent.xx.fbcdn.net/v/photo.jpg" width="640" height="480">
+</div>`)
+
+    images := fs.extractImages(post)
+    if len(images) != 1 || images[0].URL != "https://scontent.xx.fbcdn.net/v/photo.jpg" {
+        t.Errorf("extractImages() = %+v, want only the content photo", images)
+    }
+}
+
+func TestExtractPostDataCountsContentImagesOnly(t *testing.T) {
+    fs := newTestScraper(t, "http://localhost")
+    post := parseFragment(t, `<div data-ft='{"top_level_post_id":"42"}'>
+<h3>Jane Doe</h3>
+<p>Great day <img src="https://static.xx.fbcdn.net/images/emoji.php/v9/t4c/1/16/1f600.png" width="16" height="16"></p>
+<img src="https://www.facebook.com/tr?id=1&noscript=1.gif" width="1" height="1">
+</div>`).Find("div[data-ft]")
+
+    got := fs.extractPostData(post, "123")
+    if got.MediaCount != 0 || got.PostType != "text" {
+        t.Errorf("MediaCount = %d, PostType = %q, want 0 and text", got.MediaCount, got.PostType)
+    }
+}
+
+func TestIsContentImage(t *testing.T) {
+    tests := []struct {
+        item types.MediaItem
+        want bool
+    }{
+        {types.MediaItem{URL: "https://scontent.xx.fbcdn.net/v/photo.jpg", Width: 640, Height: 480}, true},
+        {types.MediaItem{URL: "https://scontent.xx.fbcdn.net/v/photo.jpg"}, true},
+        {types.MediaItem{URL: "https://scontent.xx.fbcdn.net/v/pixel.gif", Width: 1, Height: 1}, false},
+        {types.MediaItem{URL: "https://scontent.xx.fbcdn.net/v/banner.jpg", Width: 800, Height: 20}, false},
+        {types.MediaItem{URL: "https://static.xx.fbcdn.net/images/reaction/love.png"}, false},
+    }
+    for _, tt := range tests {
+        if got := isContentImage(tt.item); got != tt.want {
+            t.Errorf("isContentImage(%+v) = %v, want %v", tt.item, got, tt.want)
+        }
+    }
+}
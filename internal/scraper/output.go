@@ -0,0 +1,155 @@
+package scraper
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "facebook-scraper/pkg/types"
+)
+
+// Output formats for the flat file written at the end of a run
+const (
+    OutputJSON  = "json"
+    OutputCSV   = "csv"
+    OutputJSONL = "jsonl"
+    OutputNone  = "none"
+)
+
+// SetOutputFormat makes the scraper keep every post that passes the filter so
+// WriteOutput can write them to a file. An empty format or "none" disables it.
+func (fs *FacebookScraper) SetOutputFormat(format string) error {
+    format = strings.ToLower(strings.TrimSpace(format))
+    switch format {
+    case "", OutputNone:
+        format = ""
+    case OutputJSON, OutputCSV, OutputJSONL:
+    default:
+        return fmt.Errorf("unknown output format %q (use json, csv or jsonl)", format)
+    }
+
+    fs.outputMu.Lock()
+    defer fs.outputMu.Unlock()
+    fs.outputFormat = format
+    fs.output = nil
+    return nil
+}
+
+// collectOutput remembers posts for WriteOutput when an output format is set
+func (fs *FacebookScraper) collectOutput(posts []types.ScrapedPost) {
+    fs.outputMu.Lock()
+    defer fs.outputMu.Unlock()
+    if fs.outputFormat == "" {
+        return
+    }
+    fs.output = append(fs.output, posts...)
+}
+
+// WriteOutput writes the posts collected since the last call to
+// <dir>/posts.<format> and returns the file path. It does nothing and returns
+// "" when no output format is set.
+func (fs *FacebookScraper) WriteOutput(dir string) (string, error) {
+    fs.outputMu.Lock()
+    defer fs.outputMu.Unlock()
+    if fs.outputFormat == "" {
+        return "", nil
+    }
+
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return "", fmt.Errorf("failed to create output directory: %w", err)
+    }
+    path := filepath.Join(dir, "posts."+fs.outputFormat)
+
+    // Write to a temporary file first so a failed run never leaves a
+    // truncated file behind
+    tmp, err := os.CreateTemp(dir, "posts-*.tmp")
+    if err != nil {
+        return "", fmt.Errorf("failed to create output file: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+
+    switch fs.outputFormat {
+    case OutputCSV:
+        err = writeScrapedPostsCSV(tmp, fs.output)
+    case OutputJSONL:
+        err = writeScrapedPostsJSONL(tmp, fs.output)
+    default:
+        err = writeScrapedPostsJSON(tmp, fs.output)
+    }
+    if err == nil {
+        err = tmp.Chmod(0644)
+    }
+    if closeErr := tmp.Close(); err == nil {
+        err = closeErr
+    }
+    if err != nil {
+        return "", fmt.Errorf("failed to write %s output: %w", fs.outputFormat, err)
+    }
+
+    if err := os.Rename(tmp.Name(), path); err != nil {
+        return "", fmt.Errorf("failed to write %s: %w", path, err)
+    }
+
+    fs.logger.Infof("Wrote %d posts to %s", len(fs.output), path)
+    fs.output = nil
+    return path, nil
+}
+
+func writeScrapedPostsJSON(out io.Writer, posts []types.ScrapedPost) error {
+    if posts == nil {
+        posts = []types.ScrapedPost{}
+    }
+    encoder := json.NewEncoder(out)
+    encoder.SetIndent("", "  ")
+    return encoder.Encode(posts)
+}
+
+// writeScrapedPostsJSONL writes one JSON object per line
+func writeScrapedPostsJSONL(out io.Writer, posts []types.ScrapedPost) error {
+    encoder := json.NewEncoder(out)
+    for _, post := range posts {
+        if err := encoder.Encode(post); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func writeScrapedPostsCSV(out io.Writer, posts []types.ScrapedPost) error {
+    writer := csv.NewWriter(out)
+
+    header := []string{"ID", "Group ID", "Source Type", "Author", "Content", "Likes", "Comments", "Shares",
+        "Post Type", "Post Time", "URL", "Hashtags"}
+    if err := writer.Write(header); err != nil {
+        return err
+    }
+
+    for _, post := range posts {
+        record := []string{
+            post.ID,
+            post.GroupID,
+            post.SourceType,
+            post.AuthorName,
+            post.Content,
+            strconv.Itoa(post.LikesCount),
+            strconv.Itoa(post.CommentsCount),
+            strconv.Itoa(post.SharesCount),
+            post.PostType,
+            post.PostTime.Format(time.RFC3339),
+            post.URL,
+            strings.Join(post.Hashtags, " "),
+        }
+        if err := writer.Write(record); err != nil {
+            return err
+        }
+    }
+
+    writer.Flush()
+    return writer.Error()
+}
@@ -0,0 +1,79 @@
+package scraper
+
+import (
+    "context"
+    "math/rand"
+    "time"
+
+    "golang.org/x/time/rate"
+)
+
+// Jitter randomizes d by up to percent in either direction, so delays don't
+// form a regular, easily fingerprinted cadence. percent is clamped to 0-100.
+func Jitter(d time.Duration, percent int) time.Duration {
+    if percent > 100 {
+        percent = 100
+    }
+    spread := int64(d) * int64(percent) / 100
+    if d <= 0 || spread <= 0 {
+        return d
+    }
+    return d - time.Duration(spread) + time.Duration(rand.Int63n(2*spread+1))
+}
+
+// SleepContext waits for d or until ctx is cancelled, whichever comes first,
+// returning ctx's error in the latter case
+func SleepContext(ctx context.Context, d time.Duration) error {
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-timer.C:
+        return nil
+    }
+}
+
+// rateLimiter paces outbound requests with a token bucket that lets one
+// request through every interval, allowing up to burst at once after a quiet
+// period. It is safe for concurrent use, so every worker shares the same budget.
+type rateLimiter struct {
+    limiter *rate.Limiter
+    jitter  int // percent applied to each wait, see Jitter
+}
+
+// newRateLimiter returns a limiter for perMinute requests per minute, or nil
+// when perMinute is not positive
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+    if perMinute <= 0 {
+        return nil
+    }
+    if burst < 1 {
+        burst = 1
+    }
+    return &rateLimiter{
+        limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), burst),
+    }
+}
+
+// Wait blocks until a request may be made or ctx is cancelled. A nil limiter
+// never waits.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+    if l == nil {
+        return nil
+    }
+
+    // Reserve rather than rate.Limiter.Wait so the wait can be jittered
+    reservation := l.limiter.Reserve()
+    wait := Jitter(reservation.Delay(), l.jitter)
+    if wait <= 0 {
+        return nil
+    }
+    if err := SleepContext(ctx, wait); err != nil {
+        // The request won't be made, so hand the token back
+        reservation.Cancel()
+        return err
+    }
+    return nil
+}
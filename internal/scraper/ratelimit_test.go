@@ -0,0 +1,108 @@
+package scraper
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestJitterBounds(t *testing.T) {
+    d := 10 * time.Second
+    for i := 0; i < 1000; i++ {
+        if got := Jitter(d, 20); got < 8*time.Second || got > 12*time.Second {
+            t.Fatalf("Jitter(10s, 20) = %v, want within 8s-12s", got)
+        }
+    }
+
+    if got := Jitter(d, 0); got != d {
+        t.Errorf("Jitter(10s, 0) = %v, want 10s", got)
+    }
+    if got := Jitter(0, 50); got != 0 {
+        t.Errorf("Jitter(0, 50) = %v, want 0", got)
+    }
+    for i := 0; i < 100; i++ {
+        if got := Jitter(d, 500); got < 0 || got > 2*d {
+            t.Fatalf("Jitter(10s, 500) = %v, want the percent clamped to 100", got)
+        }
+    }
+}
+
+func TestSleepContext(t *testing.T) {
+    if err := SleepContext(context.Background(), time.Millisecond); err != nil {
+        t.Errorf("SleepContext() = %v, want nil", err)
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    start := time.Now()
+    if err := SleepContext(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+        t.Errorf("SleepContext() = %v, want context.Canceled", err)
+    }
+    if time.Since(start) > time.Second {
+        t.Error("SleepContext() did not return when the context was cancelled")
+    }
+}
+
+func TestRateLimiterPacesRequests(t *testing.T) {
+    // 600 a minute is one request every 100ms
+    limiter := newRateLimiter(600, 1)
+    ctx := context.Background()
+
+    start := time.Now()
+    for i := 0; i < 3; i++ {
+        if err := limiter.Wait(ctx); err != nil {
+            t.Fatalf("Wait() = %v", err)
+        }
+    }
+    // The first request uses the burst, the next two wait 100ms each
+    if elapsed := time.Since(start); elapsed < 180*time.Millisecond || elapsed > time.Second {
+        t.Errorf("three requests took %v, want about 200ms", elapsed)
+    }
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+    limiter := newRateLimiter(1, 3)
+    start := time.Now()
+    for i := 0; i < 3; i++ {
+        if err := limiter.Wait(context.Background()); err != nil {
+            t.Fatalf("Wait() = %v", err)
+        }
+    }
+    if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+        t.Errorf("burst of 3 took %v, want no wait", elapsed)
+    }
+}
+
+func TestRateLimiterCancelReturnsToken(t *testing.T) {
+    limiter := newRateLimiter(600, 1)
+    if err := limiter.Wait(context.Background()); err != nil {
+        t.Fatalf("Wait() = %v", err)
+    }
+
+    // A cancelled wait doesn't use up the next slot
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+        t.Fatalf("Wait() = %v, want context.Canceled", err)
+    }
+
+    start := time.Now()
+    if err := limiter.Wait(context.Background()); err != nil {
+        t.Fatalf("Wait() = %v", err)
+    }
+    if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+        t.Errorf("Wait() after a cancelled wait took %v, want at most one interval", elapsed)
+    }
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+    if limiter := newRateLimiter(0, 1); limiter != nil {
+        t.Fatalf("newRateLimiter(0) = %+v, want nil", limiter)
+    }
+
+    var limiter *rateLimiter
+    if err := limiter.Wait(context.Background()); err != nil {
+        t.Errorf("nil limiter Wait() = %v, want nil", err)
+    }
+}
@@ -0,0 +1,108 @@
+package scraper
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/url"
+    "strings"
+
+    "github.com/PuerkitoBio/goquery"
+    "facebook-scraper/pkg/types"
+)
+
+// SearchGroupID is the synthetic group ID stored on posts found via search
+const SearchGroupID = "search"
+
+// ScrapeSearch scrapes public posts matching a keyword query and stores them
+// with SearchGroupID and the query, through the same filter and storage
+// pipeline as groups
+func (fs *FacebookScraper) ScrapeSearch(ctx context.Context, query string) (*ScrapingStats, error) {
+    return fs.scrapeSource(ctx, SourceSearch, query, fs.FetchSearchPosts)
+}
+
+// FetchSearchPosts fetches posts from the mobile search results for query,
+// following the "see more results" link for up to the configured max pages
+func (fs *FacebookScraper) FetchSearchPosts(ctx context.Context, query string) ([]types.ScrapedPost, error) {
+    pageURL := fmt.Sprintf("%s/search/posts/?q=%s", fs.mobileURL, url.QueryEscape(query))
+
+    var posts []types.ScrapedPost
+    for page := 1; page <= fs.maxPages && pageURL != ""; page++ {
+        fs.logger.Infof("Fetching search results page %d for %q", page, query)
+
+        body, err := fs.fetchHTML(ctx, pageURL, pageLabel(SearchGroupID+"-"+query, page))
+        if errors.Is(err, ErrAuthExpired) {
+            return nil, fmt.Errorf("search %q: %w", query, err)
+        }
+        if err != nil {
+            if len(posts) > 0 {
+                fs.logger.Warnf("Stopping search for %q at page %d: %v", query, page, err)
+                break
+            }
+            return nil, err
+        }
+
+        pagePosts, err := fs.parseGroupPosts(body, SearchGroupID)
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse search results: %w", err)
+        }
+        if len(pagePosts) == 0 && page == 1 && containsLoginMarkers(body) {
+            return nil, fmt.Errorf("search %q: %w: login page served", query, ErrAuthExpired)
+        }
+        posts = append(posts, pagePosts...)
+
+        pageURL = fs.nextPageURL(body, pageURL)
+
+        // Rate limiting
+        if err := fs.throttle(ctx); err != nil {
+            return nil, err
+        }
+    }
+
+    posts = fs.deduplicatePosts(posts)
+    for i := range posts {
+        posts[i].SourceType = SourceSearch
+        posts[i].SearchQuery = query
+    }
+
+    fs.logger.Infof("Found %d posts for search %q", len(posts), query)
+    return posts, nil
+}
+
+// nextPageURL returns the absolute URL of the "see more" / older posts link
+// on a mobile page fetched from pageURL, or "" when there are no more pages
+func (fs *FacebookScraper) nextPageURL(html, pageURL string) string {
+    doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+    if err != nil {
+        return ""
+    }
+
+    selectors := []string{
+        "#see_more_pager a",
+        "#m_more_item a",
+        "div[id*='see_more'] a",
+        "a[href*='cursor=']",
+        "a[href*='show_older']",
+    }
+
+    var next string
+    for _, selector := range selectors {
+        if href, exists := doc.Find(selector).First().Attr("href"); exists && href != "" {
+            next = href
+            break
+        }
+    }
+    if next == "" {
+        return ""
+    }
+
+    base, err := url.Parse(pageURL)
+    if err != nil {
+        return ""
+    }
+    ref, err := url.Parse(next)
+    if err != nil {
+        return ""
+    }
+    return base.ResolveReference(ref).String()
+}
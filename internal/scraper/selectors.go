@@ -0,0 +1,37 @@
+package scraper
+
+// Selectors holds the CSS selector lists tried in order when parsing posts,
+// so markup changes can be patched from the config without a rebuild
+type Selectors struct {
+    Post    []string
+    Author  []string
+    Content []string
+}
+
+// DefaultSelectors returns the built-in selector lists
+func DefaultSelectors() Selectors {
+    return Selectors{
+        Post: []string{
+            "div[data-ft]",                      // Classic mobile posts
+            "article[data-ft]",                  // Article format posts
+            "div[role='article']",               // Semantic article posts
+            "div[id*='story']",                  // Story format posts
+            ".story_body_container",             // Story body containers
+            "div[data-testid='story-subtitle']", // New format posts
+        },
+        Author: []string{
+            "h3 a",
+            ".actor a",
+            "[data-hovercard] strong",
+            "strong a",
+            ".profileLink",
+        },
+        Content: []string{
+            ".userContent",
+            "[data-testid='post_message']",
+            ".story_body_container p",
+            ".text_exposed_root",
+            "p",
+        },
+    }
+}
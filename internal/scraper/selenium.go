@@ -0,0 +1,257 @@
+//go:build selenium
+
+package scraper
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+    "github.com/tebeka/selenium"
+    "github.com/tebeka/selenium/chrome"
+    "github.com/tebeka/selenium/firefox"
+    "facebook-scraper/pkg/types"
+)
+
+// seleniumPostSelector matches post containers on mobile and desktop layouts
+const seleniumPostSelector = "div[data-ft], [role=article]"
+
+// Browsers supported by the Selenium backend
+const (
+    SeleniumFirefox = "firefox"
+    SeleniumChrome  = "chrome"
+)
+
+// SeleniumBrowserScraper drives Firefox or Chrome through a local WebDriver
+// service (geckodriver / chromedriver)
+type SeleniumBrowserScraper struct {
+    logger  *logrus.Logger
+    service *selenium.Service
+    driver  selenium.WebDriver
+    fs      *FacebookScraper
+
+    maxScrolls  int
+    waitTimeout time.Duration
+}
+
+// NewSeleniumBrowserScraper starts the WebDriver service for the configured
+// browser and opens a session
+func NewSeleniumBrowserScraper(fs *FacebookScraper, opts BrowserOptions) (*SeleniumBrowserScraper, error) {
+    browser := opts.Browser
+    if browser == "" {
+        browser = SeleniumFirefox
+    }
+    port := opts.DriverPort
+    if port == 0 {
+        port = 4444
+    }
+
+    var (
+        service *selenium.Service
+        caps    selenium.Capabilities
+        err     error
+    )
+
+    switch browser {
+    case SeleniumFirefox:
+        driverPath := opts.DriverPath
+        if driverPath == "" {
+            driverPath = "geckodriver"
+        }
+        service, err = selenium.NewGeckoDriverService(driverPath, port)
+        if err != nil {
+            return nil, fmt.Errorf("failed to start geckodriver: %w", err)
+        }
+
+        caps = selenium.Capabilities{"browserName": "firefox"}
+        firefoxCaps := firefox.Capabilities{
+            Prefs: map[string]interface{}{
+                "dom.webdriver.enabled": false,
+                "useAutomationExtension": false,
+                "general.useragent.override": fs.userAgents.Next(),
+            },
+        }
+        if opts.Headless {
+            firefoxCaps.Args = append(firefoxCaps.Args, "-headless")
+        }
+        caps.AddFirefox(firefoxCaps)
+
+    case SeleniumChrome:
+        driverPath := opts.DriverPath
+        if driverPath == "" {
+            driverPath = "chromedriver"
+        }
+        service, err = selenium.NewChromeDriverService(driverPath, port)
+        if err != nil {
+            return nil, fmt.Errorf("failed to start chromedriver: %w", err)
+        }
+
+        caps = selenium.Capabilities{"browserName": "chrome"}
+        chromeCaps := chrome.Capabilities{
+            Args: []string{
+                "--disable-blink-features=AutomationControlled",
+                "--no-sandbox",
+                "--disable-dev-shm-usage",
+                "--user-agent=" + fs.userAgents.Next(),
+            },
+            ExcludeSwitches: []string{"enable-automation"},
+            Prefs: map[string]interface{}{
+                "credentials_enable_service":        false,
+                "profile.password_manager_enabled": false,
+            },
+        }
+        if opts.Headless {
+            chromeCaps.Args = append(chromeCaps.Args, "--headless=new")
+        }
+        caps.AddChrome(chromeCaps)
+
+    default:
+        return nil, fmt.Errorf("unsupported selenium browser %q (expected %s or %s)", browser, SeleniumFirefox, SeleniumChrome)
+    }
+
+    driver, err := selenium.NewRemote(caps, fmt.Sprintf("http://localhost:%d", port))
+    if err != nil {
+        service.Stop()
+        return nil, fmt.Errorf("failed to open %s session: %w", browser, err)
+    }
+
+    fs.logger.Infof("Started Selenium %s session on port %d", browser, port)
+
+    maxScrolls := opts.MaxScrolls
+    if maxScrolls == 0 {
+        maxScrolls = 10
+    }
+    waitTimeout := opts.WaitTimeout
+    if waitTimeout == 0 {
+        waitTimeout = 15 * time.Second
+    }
+
+    return &SeleniumBrowserScraper{
+        logger:      fs.logger,
+        service:     service,
+        driver:      driver,
+        fs:          fs,
+        maxScrolls:  maxScrolls,
+        waitTimeout: waitTimeout,
+    }, nil
+}
+
+func newSeleniumBackend(fs *FacebookScraper, opts BrowserOptions) (GroupScraper, error) {
+    return NewSeleniumBrowserScraper(fs, opts)
+}
+
+// FetchGroupPosts implements GroupScraper
+func (sbs *SeleniumBrowserScraper) FetchGroupPosts(ctx context.Context, groupID string) ([]types.ScrapedPost, error) {
+    html, err := sbs.ScrapeGroupWithBrowser(ctx, groupID, sbs.fs.authManager.ActiveCookies())
+    if err != nil {
+        return nil, err
+    }
+
+    return sbs.fs.parseGroupPosts(html, groupID)
+}
+
+// ScrapeGroupWithBrowser loads the group with the account's cookies, scrolls
+// to load more posts and returns the rendered HTML
+func (sbs *SeleniumBrowserScraper) ScrapeGroupWithBrowser(ctx context.Context, groupID string, cookies []Cookie) (string, error) {
+    sbs.logger.Infof("Starting Selenium scraping for group %s", groupID)
+
+    // Cookies can only be set once the browser is on the Facebook domain
+    if err := sbs.driver.Get("https://m.facebook.com"); err != nil {
+        return "", fmt.Errorf("failed to open Facebook: %w", err)
+    }
+    for _, cookie := range cookies {
+        err := sbs.driver.AddCookie(&selenium.Cookie{
+            Name:   cookie.Name,
+            Value:  cookie.Value,
+            Domain: ".facebook.com",
+            Path:   "/",
+            Secure: true,
+        })
+        if err != nil {
+            sbs.logger.Warnf("Failed to set cookie %s: %v", cookie.Name, err)
+        }
+    }
+
+    groupURL := fmt.Sprintf("https://m.facebook.com/groups/%s", groupID)
+    if err := sbs.fs.limiter.Wait(ctx); err != nil {
+        return "", err
+    }
+    if err := sbs.driver.Get(groupURL); err != nil {
+        return "", fmt.Errorf("failed to open %s: %w", groupURL, err)
+    }
+
+    // Wait for the first post containers instead of a blind sleep
+    err := sbs.driver.WaitWithTimeout(func(wd selenium.WebDriver) (bool, error) {
+        return sbs.countPosts() > 0, nil
+    }, sbs.waitTimeout)
+    if err != nil {
+        sbs.logger.Warnf("No post containers appeared within %v: %v", sbs.waitTimeout, err)
+    }
+
+    count := sbs.countPosts()
+    for i := 0; i < sbs.maxScrolls; i++ {
+        if err := ctx.Err(); err != nil {
+            return "", err
+        }
+
+        if _, err := sbs.driver.ExecuteScript("window.scrollTo(0, document.body.scrollHeight);", nil); err != nil {
+            sbs.logger.Warnf("Scroll %d failed: %v", i+1, err)
+            break
+        }
+
+        newCount, err := sbs.waitForMorePosts(count)
+        if err != nil {
+            sbs.logger.Infof("No more posts loaded after scroll %d (%d posts)", i+1, count)
+            break
+        }
+        sbs.logger.Debugf("Scroll %d/%d: %d posts", i+1, sbs.maxScrolls, newCount)
+        count = newCount
+    }
+
+    html, err := sbs.driver.PageSource()
+    if err != nil {
+        return "", fmt.Errorf("failed to read page source: %w", err)
+    }
+
+    if currentURL, err := sbs.driver.CurrentURL(); err == nil && strings.Contains(currentURL, "login") {
+        return "", fmt.Errorf("%w: redirected to %s", ErrAuthExpired, currentURL)
+    }
+
+    return html, nil
+}
+
+// countPosts returns how many post containers are currently in the DOM
+func (sbs *SeleniumBrowserScraper) countPosts() int {
+    elems, err := sbs.driver.FindElements(selenium.ByCSSSelector, seleniumPostSelector)
+    if err != nil {
+        return 0
+    }
+    return len(elems)
+}
+
+// waitForMorePosts waits until the post count grows past prev and then stops
+// growing, returning the settled count
+func (sbs *SeleniumBrowserScraper) waitForMorePosts(prev int) (int, error) {
+    last := prev
+    err := sbs.driver.WaitWithTimeoutAndInterval(func(wd selenium.WebDriver) (bool, error) {
+        current := sbs.countPosts()
+        settled := current > prev && current == last
+        last = current
+        return settled, nil
+    }, sbs.waitTimeout, 500*time.Millisecond)
+
+    return last, err
+}
+
+// Close ends the browser session and stops the WebDriver service
+func (sbs *SeleniumBrowserScraper) Close() error {
+    if sbs.driver != nil {
+        sbs.driver.Quit()
+    }
+    if sbs.service != nil {
+        return sbs.service.Stop()
+    }
+    return nil
+}
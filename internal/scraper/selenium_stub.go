@@ -0,0 +1,13 @@
+//go:build !selenium
+
+package scraper
+
+import (
+    "fmt"
+)
+
+// newSeleniumBackend is replaced by the real implementation in selenium.go
+// when building with -tags selenium
+func newSeleniumBackend(fs *FacebookScraper, opts BrowserOptions) (GroupScraper, error) {
+    return nil, fmt.Errorf("scraper backend %q is not available in this build (rebuild with -tags selenium)", BackendSelenium)
+}
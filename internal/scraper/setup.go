@@ -0,0 +1,107 @@
+package scraper
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/sirupsen/logrus"
+    "facebook-scraper/internal/config"
+    "facebook-scraper/internal/database"
+    "facebook-scraper/pkg/types"
+)
+
+// NewFromConfig creates a scraper with every option from the config applied.
+// Call Initialize on the result before scraping.
+func NewFromConfig(cfg *config.Config, logger *logrus.Logger, db *database.DB) (*FacebookScraper, error) {
+    fbScraper, err := NewFacebookScraper(
+        cfg.Facebook.Auth.CookiesFile,
+        cfg.Facebook.Auth.UserAgent,
+        time.Duration(cfg.Facebook.RateLimit.DelayBetweenRequests)*time.Second,
+        logger,
+        db,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("failed to create Facebook scraper: %w", err)
+    }
+
+    if err := fbScraper.SetFilter(cfg.Filter.PostFilter()); err != nil {
+        return nil, fmt.Errorf("invalid filter: %w", err)
+    }
+    if err := configureBackends(fbScraper, cfg.Scraper); err != nil {
+        return nil, fmt.Errorf("failed to configure scraper backend: %w", err)
+    }
+    fbScraper.SetJitterPercent(cfg.Facebook.RateLimit.JitterPercent)
+    fbScraper.SetRequestsPerMinute(cfg.Facebook.RateLimit.RequestsPerMinute)
+    if err := fbScraper.SetOutputFormat(cfg.Scraper.OutputFormat); err != nil {
+        return nil, err
+    }
+    fbScraper.SetScrapeComments(cfg.Scraper.ScrapeComments)
+    fbScraper.SetDetectLanguage(cfg.Scraper.DetectLanguage)
+    fbScraper.SetMaxPages(cfg.Scraper.MaxPages)
+    fbScraper.SetSelectors(Selectors{
+        Post:    cfg.Selectors.Post,
+        Author:  cfg.Selectors.Author,
+        Content: cfg.Selectors.Content,
+    })
+    if cfg.Debug.SaveHTML {
+        fbScraper.SetRawHTMLDir(cfg.Debug.RawDir)
+        logger.Warnf("Saving raw HTML responses to %s", cfg.Debug.RawDir)
+    }
+    fbScraper.SetPostWebhook(PostWebhook{
+        URL:      cfg.Notifications.WebhookURL,
+        Headers:  cfg.Notifications.Headers,
+        MinLikes: cfg.Notifications.MinLikes,
+    })
+    fbScraper.SetUserAgents(cfg.Facebook.Auth.UserAgents)
+    cooldown := time.Duration(cfg.Facebook.Auth.AccountCooldown) * time.Minute
+    if err := fbScraper.AddAccounts(cfg.Facebook.Auth.CookiesFiles, cooldown); err != nil {
+        return nil, fmt.Errorf("failed to configure accounts: %w", err)
+    }
+    if err := fbScraper.SetProxies(cfg.Facebook.Auth.Proxies()); err != nil {
+        return nil, fmt.Errorf("failed to configure proxies: %w", err)
+    }
+
+    return fbScraper, nil
+}
+
+// ApplyGroupFilters gives every group that sets its own min_likes, days_back
+// or keywords a filter built from the global one with those overrides
+func (fs *FacebookScraper) ApplyGroupFilters(groups []config.Group, global config.FilterConfig) error {
+    filters := make(map[string]*types.PostFilter)
+    for _, group := range groups {
+        if !group.HasFilterOverrides() {
+            continue
+        }
+        filter := group.PostFilter(global)
+        filters[group.ID] = filter
+        fs.logger.Infof("Group %s uses its own filter (%d+ likes, past %d days, %d keyword(s))",
+            group.ID, filter.MinLikes, filter.DaysBack, len(filter.Keywords))
+    }
+    return fs.SetGroupFilters(filters)
+}
+
+func configureBackends(fbScraper *FacebookScraper, cfg config.ScraperConfig) error {
+    opts := BrowserOptions{
+        Headless:    cfg.Browser.Headless,
+        Browser:     cfg.Browser.Browser,
+        DriverPath:  cfg.Browser.DriverPath,
+        DriverPort:  cfg.Browser.DriverPort,
+        MaxScrolls:  cfg.Browser.MaxScrolls,
+        WaitTimeout: time.Duration(cfg.Browser.WaitTimeout) * time.Second,
+    }
+
+    backend, err := NewBackend(cfg.Backend, fbScraper, opts)
+    if err != nil {
+        return err
+    }
+
+    var fallback GroupScraper
+    if cfg.FallbackBackend != "" {
+        if fallback, err = NewBackend(cfg.FallbackBackend, fbScraper, opts); err != nil {
+            return err
+        }
+    }
+
+    fbScraper.SetBackends(backend, fallback)
+    return nil
+}
@@ -0,0 +1,44 @@
+package scraper
+
+import (
+    "sync/atomic"
+)
+
+// DefaultUserAgents is used when neither user_agents nor user_agent is configured
+var DefaultUserAgents = []string{
+    "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36",
+    "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36",
+    "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+    "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:122.0) Gecko/20100101 Firefox/122.0",
+    "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+    "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Mobile Safari/537.36",
+}
+
+// UserAgentPool hands out user agents in round-robin order
+type UserAgentPool struct {
+    agents []string
+    next   uint64
+}
+
+// NewUserAgentPool builds a pool from agents, falling back to the single
+// fallback agent and then to DefaultUserAgents when agents is empty
+func NewUserAgentPool(agents []string, fallback string) *UserAgentPool {
+    if len(agents) == 0 && fallback != "" {
+        agents = []string{fallback}
+    }
+    if len(agents) == 0 {
+        agents = DefaultUserAgents
+    }
+    return &UserAgentPool{agents: agents}
+}
+
+// Next returns the next user agent in the rotation
+func (p *UserAgentPool) Next() string {
+    i := atomic.AddUint64(&p.next, 1) - 1
+    return p.agents[i%uint64(len(p.agents))]
+}
+
+// Size returns the number of user agents in the pool
+func (p *UserAgentPool) Size() int {
+    return len(p.agents)
+}
@@ -0,0 +1,112 @@
+package scraper
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "time"
+
+    "facebook-scraper/internal/database/models"
+)
+
+// webhookTimeout bounds each delivery attempt
+const webhookTimeout = 10 * time.Second
+
+// PostWebhook is an HTTP endpoint told about newly saved posts with at least
+// MinLikes likes. Posts that were already stored don't trigger it again.
+type PostWebhook struct {
+    URL      string
+    Headers  map[string]string // e.g. an Authorization header
+    MinLikes int               // 0 notifies about every new post that passed the filter
+}
+
+// newPostPayload is the body POSTed for each new high-engagement post
+type newPostPayload struct {
+    Event     string    `json:"event"`
+    PostID    string    `json:"post_id"`
+    GroupID   string    `json:"group_id"`
+    GroupName string    `json:"group_name"`
+    Author    string    `json:"author"`
+    Likes     int       `json:"likes"`
+    Comments  int       `json:"comments"`
+    Shares    int       `json:"shares"`
+    URL       string    `json:"url"`
+    PostedAt  time.Time `json:"posted_at"`
+}
+
+// SetPostWebhook sets the endpoint notified about new high-engagement posts;
+// an empty URL disables notifications
+func (fs *FacebookScraper) SetPostWebhook(webhook PostWebhook) {
+    if webhook.URL == "" {
+        fs.webhook = nil
+        return
+    }
+    fs.webhook = &webhook
+}
+
+// notifyNewPosts POSTs every newly inserted post at the webhook's threshold,
+// retrying each once. Failures are logged and never fail the scrape.
+func (fs *FacebookScraper) notifyNewPosts(ctx context.Context, posts []*models.Post) {
+    if fs.webhook == nil {
+        return
+    }
+
+    client := &http.Client{Timeout: webhookTimeout}
+    for _, post := range posts {
+        if post.Likes < fs.webhook.MinLikes {
+            continue
+        }
+
+        body, err := json.Marshal(newPostPayload{
+            Event:     "new_post",
+            PostID:    post.PostID,
+            GroupID:   post.GroupID,
+            GroupName: post.GroupName,
+            Author:    post.AuthorName,
+            Likes:     post.Likes,
+            Comments:  post.Comments,
+            Shares:    post.Shares,
+            URL:       post.PostURL,
+            PostedAt:  post.Timestamp,
+        })
+        if err != nil {
+            fs.logger.Errorf("Failed to encode webhook payload for post %s: %v", post.PostID, err)
+            continue
+        }
+
+        err = fs.postWebhook(ctx, client, body)
+        if err != nil && ctx.Err() == nil {
+            fs.logger.Warnf("Post webhook failed for post %s, retrying: %v", post.PostID, err)
+            err = fs.postWebhook(ctx, client, body)
+        }
+        if err != nil {
+            fs.logger.Errorf("Failed to notify webhook about post %s: %v", post.PostID, err)
+            continue
+        }
+        fs.logger.Infof("Notified webhook about new post %s (%d likes)", post.PostID, post.Likes)
+    }
+}
+
+func (fs *FacebookScraper) postWebhook(ctx context.Context, client *http.Client, body []byte) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, fs.webhook.URL, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    for name, value := range fs.webhook.Headers {
+        req.Header.Set(name, value)
+    }
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return fmt.Errorf("unexpected status %d", resp.StatusCode)
+    }
+    return nil
+}
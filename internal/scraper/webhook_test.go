@@ -0,0 +1,106 @@
+package scraper
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "sort"
+    "sync"
+    "testing"
+
+    "facebook-scraper/internal/database/models"
+)
+
+// webhookRecorder counts the deliveries per post, failing the first failures[id]
+// attempts for a post with a 503
+type webhookRecorder struct {
+    mu        sync.Mutex
+    attempts  map[string]int
+    delivered []string
+    failures  map[string]int
+    headers   http.Header
+}
+
+func newWebhookServer(t *testing.T, failures map[string]int) (*webhookRecorder, *httptest.Server) {
+    t.Helper()
+
+    rec := &webhookRecorder{attempts: make(map[string]int), failures: failures}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var payload newPostPayload
+        if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+            t.Errorf("webhook body: %v", err)
+        }
+
+        rec.mu.Lock()
+        defer rec.mu.Unlock()
+        rec.headers = r.Header.Clone()
+        rec.attempts[payload.PostID]++
+        if rec.attempts[payload.PostID] <= rec.failures[payload.PostID] {
+            w.WriteHeader(http.StatusServiceUnavailable)
+            return
+        }
+        rec.delivered = append(rec.delivered, payload.PostID)
+    }))
+    t.Cleanup(server.Close)
+    return rec, server
+}
+
+func TestNotifyNewPostsThreshold(t *testing.T) {
+    rec, server := newWebhookServer(t, nil)
+    fs := newTestScraper(t, "http://localhost")
+    fs.SetPostWebhook(PostWebhook{URL: server.URL, MinLikes: 100, Headers: map[string]string{"Authorization": "Bearer token"}})
+
+    // notifyNewPosts is handed only the posts SavePosts inserted, so posts that
+    // were already stored never reach it
+    fs.notifyNewPosts(context.Background(), []*models.Post{
+        {PostID: "hot", Likes: 150},
+        {PostID: "cold", Likes: 99},
+        {PostID: "edge", Likes: 100},
+    })
+
+    sort.Strings(rec.delivered)
+    if want := []string{"edge", "hot"}; !reflect.DeepEqual(rec.delivered, want) {
+        t.Errorf("delivered %v, want %v", rec.delivered, want)
+    }
+    if rec.attempts["cold"] != 0 {
+        t.Errorf("post below the threshold was sent %d times", rec.attempts["cold"])
+    }
+    if got := rec.headers.Get("Authorization"); got != "Bearer token" {
+        t.Errorf("Authorization = %q, want the configured header", got)
+    }
+}
+
+func TestNotifyNewPostsRetriesOnce(t *testing.T) {
+    rec, server := newWebhookServer(t, map[string]int{"flaky": 1, "down": 5})
+    fs := newTestScraper(t, "http://localhost")
+    fs.SetPostWebhook(PostWebhook{URL: server.URL})
+
+    fs.notifyNewPosts(context.Background(), []*models.Post{
+        {PostID: "flaky", Likes: 10},
+        {PostID: "down", Likes: 10},
+        {PostID: "ok", Likes: 10},
+    })
+
+    want := map[string]int{"flaky": 2, "down": 2, "ok": 1}
+    if !reflect.DeepEqual(rec.attempts, want) {
+        t.Errorf("attempts = %v, want %v", rec.attempts, want)
+    }
+    sort.Strings(rec.delivered)
+    if want := []string{"flaky", "ok"}; !reflect.DeepEqual(rec.delivered, want) {
+        t.Errorf("delivered %v, want %v", rec.delivered, want)
+    }
+}
+
+func TestNotifyNewPostsDisabled(t *testing.T) {
+    rec, server := newWebhookServer(t, nil)
+    fs := newTestScraper(t, "http://localhost")
+    fs.SetPostWebhook(PostWebhook{URL: server.URL})
+    fs.SetPostWebhook(PostWebhook{})
+
+    fs.notifyNewPosts(context.Background(), []*models.Post{{PostID: "hot", Likes: 1000}})
+    if len(rec.attempts) != 0 {
+        t.Errorf("disabled webhook was called: %v", rec.attempts)
+    }
+}
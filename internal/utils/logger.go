@@ -7,17 +7,41 @@ import (
     "github.com/sirupsen/logrus"
 )
 
+// Log formats for logging.format
+const (
+    LogFormatText = "text"
+    LogFormatJSON = "json"
+)
+
+// NewLogger creates the logger used by the commands. level "debug" enables
+// debug output and format "json" writes one JSON object per line for log
+// collectors; anything else gives info-level text logs.
+func NewLogger(level, format string) *logrus.Logger {
+    logger := logrus.New()
+    configureLogger(logger, level == "debug", format)
+    return logger
+}
+
 func SetupLogger(debug bool) {
-    logrus.SetFormatter(&logrus.TextFormatter{
-        FullTimestamp: true,
-        TimestampFormat: "2006-01-02 15:04:05",
-    })
+    configureLogger(logrus.StandardLogger(), debug, LogFormatText)
+    logrus.SetOutput(os.Stdout)
+}
 
-    if debug {
-        logrus.SetLevel(logrus.DebugLevel)
+func configureLogger(logger *logrus.Logger, debug bool, format string) {
+    if format == LogFormatJSON {
+        logger.SetFormatter(&logrus.JSONFormatter{
+            TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+        })
     } else {
-        logrus.SetLevel(logrus.InfoLevel)
+        logger.SetFormatter(&logrus.TextFormatter{
+            FullTimestamp: true,
+            TimestampFormat: "2006-01-02 15:04:05",
+        })
     }
 
-    logrus.SetOutput(os.Stdout)
-}
\ No newline at end of file
+    if debug {
+        logger.SetLevel(logrus.DebugLevel)
+    } else {
+        logger.SetLevel(logrus.InfoLevel)
+    }
+}
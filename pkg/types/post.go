@@ -6,60 +6,216 @@ import (
 )
 
 type ScrapedPost struct {
-    ID            string    `json:"id"`
-    GroupID       string    `json:"group_id"`
-    AuthorName    string    `json:"author_name"`
-    AuthorID      string    `json:"author_id"`
-    Content       string    `json:"content"`
-    URL           string    `json:"url"`
-    PostTime      time.Time `json:"post_time"`
-    LikesCount    int       `json:"likes_count"`
-    CommentsCount int       `json:"comments_count"`
-    SharesCount   int       `json:"shares_count"`
+    ID            string         `json:"id"`
+    GroupID       string         `json:"group_id"`    // group or Page ID, see SourceType
+    SourceType    string         `json:"source_type"` // "group", "page" or "search"
+    SearchQuery   string         `json:"search_query,omitempty"`
+    AuthorName    string         `json:"author_name"`
+    AuthorID      string         `json:"author_id"`
+    Content       string         `json:"content"`
+    URL           string         `json:"url"`
+    PostTime      time.Time      `json:"post_time"`
+    LikesCount    int            `json:"likes_count"`
+    CommentsCount int            `json:"comments_count"`
+    SharesCount   int            `json:"shares_count"`
+    Reactions     map[string]int `json:"reactions,omitempty"` // per type; LikesCount is the total
     
     // Add these new fields for media content
-    Images        []MediaItem   `json:"images"`
-    Videos        []MediaItem   `json:"videos"`
-    Mentions      []string      `json:"mentions"`
-    Hashtags      []string      `json:"hashtags"`
-    Links         []string      `json:"links"`
-    MediaCount    int           `json:"media_count"`
-    PostType      string        `json:"post_type"` // "text", "image", "video", "link", "mixed"
+    Images       []MediaItem  `json:"images"`
+    Videos       []MediaItem  `json:"videos"`
+    Mentions     []string     `json:"mentions"` // names and @handles, see MentionLinks for profiles
+    MentionLinks []Mention    `json:"mention_links,omitempty"`
+    Hashtags     []string     `json:"hashtags"`
+    Links        []string     `json:"links"`
+    LinkPreview  *LinkPreview `json:"link_preview,omitempty"` // card of a shared link
+    MediaCount   int          `json:"media_count"`
+    PostType     string       `json:"post_type"`          // "text", "image", "video", "link", "mixed"
+    IsSponsored  bool         `json:"is_sponsored"`       // ad or "Suggested for you" item
+    Language     string       `json:"language,omitempty"` // ISO 639-1 code when detection is enabled
+    Comments     []Comment    `json:"comments,omitempty"`
 }
 
+type Comment struct {
+    AuthorName string    `json:"author_name"`
+    AuthorID   string    `json:"author_id"`
+    Text       string    `json:"text"`
+    Timestamp  time.Time `json:"timestamp"`
+    LikesCount int       `json:"likes_count"`
+}
+
+// Keyword match modes for PostFilter.KeywordMatchMode
+const (
+    KeywordMatchAny = "any"
+    KeywordMatchAll = "all"
+)
+
 type PostFilter struct {
-    MinLikes        int       `json:"min_likes"`
-    MaxLikes        int       `json:"max_likes"`
-    MinComments     int       `json:"min_comments"`
-    MinShares       int       `json:"min_shares"`
-    DaysBack        int       `json:"days_back"`
-    Keywords        []string  `json:"keywords"`
-    ExcludeKeywords []string  `json:"exclude_keywords"`
-    GroupIDs        []string  `json:"group_ids"`
-    PageIDs         []string  `json:"page_ids"`
-    AuthorNames     []string  `json:"author_names"`
-    StartDate       time.Time `json:"start_date"`
-    EndDate         time.Time `json:"end_date"`
+    MinLikes           int            `json:"min_likes"`
+    MaxLikes           int            `json:"max_likes"`
+    MinComments        int            `json:"min_comments"`
+    MinShares          int            `json:"min_shares"`
+    MinTotalEngagement int            `json:"min_total_engagement"` // likes + comments + shares
+    MinReactions       map[string]int `json:"min_reactions"`        // per reaction type, e.g. {"angry": 500}
+    DaysBack           int            `json:"days_back"`
+    Keywords           []string       `json:"keywords"`
+    ExcludeKeywords    []string       `json:"exclude_keywords"`
+    KeywordMatchMode   string         `json:"keyword_match_mode"` // any (default) or all of Keywords; ExcludeKeywords always wins
+    KeywordRegex       []string       `json:"keyword_regex"`      // content must match at least one
+    ExcludeRegex       []string       `json:"exclude_regex"`      // content must match none
+    GroupIDs           []string       `json:"group_ids"`          // applies to group and search posts
+    PageIDs            []string       `json:"page_ids"`           // applies to Page posts
+    AuthorNames        []string       `json:"author_names"`
+    Hashtags           []string       `json:"hashtags"`   // post needs one of these tags, # optional
+    Mentions           []string       `json:"mentions"`   // post needs one of these mentions, @ optional
+    PostTypes          []string       `json:"post_types"` // text, image, video, link or mixed; empty allows all
+    MinMediaCount      int            `json:"min_media_count"`
+    RequireMedia       bool           `json:"require_media"`     // at least one image or video
+    RequireLink        bool           `json:"require_link"`      // at least one external link
+    ExcludeSponsored   bool           `json:"exclude_sponsored"` // drop ads and suggested posts
+    Languages          []string       `json:"languages"`         // ISO 639-1 codes; needs language detection
+    DedupeByContent    bool           `json:"dedupe_by_content"` // keep the most engaged of posts with the same text
+    StartDate          time.Time      `json:"start_date"`
+    EndDate            time.Time      `json:"end_date"`
 }
 
+// FilterReason names the filter criterion that rejected a post
+type FilterReason string
+
+const (
+    FilterPassed     FilterReason = ""
+    FilterSponsored  FilterReason = "sponsored"
+    FilterLikes      FilterReason = "likes"      // min_likes or max_likes
+    FilterComments   FilterReason = "comments"
+    FilterShares     FilterReason = "shares"
+    FilterEngagement FilterReason = "engagement" // min_total_engagement
+    FilterReaction   FilterReason = "reaction"   // min_reactions
+    FilterTime       FilterReason = "time"       // days_back or the date range
+    FilterKeyword    FilterReason = "keyword"    // keywords or keyword_regex
+    FilterExclude    FilterReason = "exclude"    // exclude_keywords or exclude_regex
+    FilterGroup      FilterReason = "group"      // group_ids or page_ids
+    FilterAuthor     FilterReason = "author"
+    FilterTag        FilterReason = "tag"        // hashtags or mentions
+    FilterMedia      FilterReason = "media"      // media and link requirements
+    FilterPostType   FilterReason = "post_type"
+    FilterLanguage   FilterReason = "language"
+    FilterDuplicate  FilterReason = "duplicate"  // dedupe_by_content
+)
+
+// FilterStats counts posts by the first filter criterion that rejected them
 type FilterStats struct {
-    TotalPosts     int `json:"total_posts"`
-    FilteredPosts  int `json:"filtered_posts"`
-    LikesFiltered  int `json:"likes_filtered"`
-    TimeFiltered   int `json:"time_filtered"`
-    KeywordFiltered int `json:"keyword_filtered"`
+    TotalPosts         int `json:"total_posts"`
+    FilteredPosts      int `json:"filtered_posts"` // posts that passed
+    SponsoredFiltered  int `json:"sponsored_filtered"`
+    LikesFiltered      int `json:"likes_filtered"`
+    CommentsFiltered   int `json:"comments_filtered"`
+    SharesFiltered     int `json:"shares_filtered"`
+    EngagementFiltered int `json:"engagement_filtered"`
+    ReactionFiltered   int `json:"reaction_filtered"`
+    TimeFiltered       int `json:"time_filtered"`
+    KeywordFiltered    int `json:"keyword_filtered"`
+    ExcludeFiltered    int `json:"exclude_filtered"`
+    GroupFiltered      int `json:"group_filtered"`
+    AuthorFiltered     int `json:"author_filtered"`
+    TagFiltered        int `json:"tag_filtered"`
+    MediaFiltered      int `json:"media_filtered"`
+    PostTypeFiltered   int `json:"post_type_filtered"`
+    LanguageFiltered   int `json:"language_filtered"`
+    DuplicateFiltered  int `json:"duplicate_filtered"`
+}
+
+// Record counts a post's filter result
+func (fs *FilterStats) Record(reason FilterReason) {
+    switch reason {
+    case FilterPassed:
+        fs.FilteredPosts++
+    case FilterSponsored:
+        fs.SponsoredFiltered++
+    case FilterLikes:
+        fs.LikesFiltered++
+    case FilterComments:
+        fs.CommentsFiltered++
+    case FilterShares:
+        fs.SharesFiltered++
+    case FilterEngagement:
+        fs.EngagementFiltered++
+    case FilterReaction:
+        fs.ReactionFiltered++
+    case FilterTime:
+        fs.TimeFiltered++
+    case FilterKeyword:
+        fs.KeywordFiltered++
+    case FilterExclude:
+        fs.ExcludeFiltered++
+    case FilterGroup:
+        fs.GroupFiltered++
+    case FilterAuthor:
+        fs.AuthorFiltered++
+    case FilterTag:
+        fs.TagFiltered++
+    case FilterMedia:
+        fs.MediaFiltered++
+    case FilterPostType:
+        fs.PostTypeFiltered++
+    case FilterLanguage:
+        fs.LanguageFiltered++
+    case FilterDuplicate:
+        fs.DuplicateFiltered++
+    }
 }
 
 type MediaItem struct {
     URL         string `json:"url"`
-    Type        string `json:"type"`        // "image", "video"
+    Type        string `json:"type"` // "image", "video"
     Description string `json:"description"`
     Width       int    `json:"width"`
     Height      int    `json:"height"`
-    Thumbnail   string `json:"thumbnail"`   // For videos
+    Thumbnail   string `json:"thumbnail"`          // For videos
+    Duration    int    `json:"duration,omitempty"` // video length in seconds
+}
+
+// Mention is a person or Page tagged in a post's content
+type Mention struct {
+    Name   string `json:"name"`
+    UserID string `json:"user_id,omitempty"` // numeric ID, or the username of vanity profile URLs
+    URL    string `json:"url"`
+}
+
+// LinkPreview is the card Facebook renders for a shared external link
+type LinkPreview struct {
+    URL         string `json:"url"`
+    Title       string `json:"title"`
+    Description string `json:"description,omitempty"`
+    Domain      string `json:"domain"`
+    Image       string `json:"image,omitempty"`
+}
+
+// Add adds the counts from other, e.g. to total the stats of several groups
+func (fs *FilterStats) Add(other FilterStats) {
+    fs.TotalPosts += other.TotalPosts
+    fs.FilteredPosts += other.FilteredPosts
+    fs.SponsoredFiltered += other.SponsoredFiltered
+    fs.LikesFiltered += other.LikesFiltered
+    fs.CommentsFiltered += other.CommentsFiltered
+    fs.SharesFiltered += other.SharesFiltered
+    fs.EngagementFiltered += other.EngagementFiltered
+    fs.ReactionFiltered += other.ReactionFiltered
+    fs.TimeFiltered += other.TimeFiltered
+    fs.KeywordFiltered += other.KeywordFiltered
+    fs.ExcludeFiltered += other.ExcludeFiltered
+    fs.GroupFiltered += other.GroupFiltered
+    fs.AuthorFiltered += other.AuthorFiltered
+    fs.TagFiltered += other.TagFiltered
+    fs.MediaFiltered += other.MediaFiltered
+    fs.PostTypeFiltered += other.PostTypeFiltered
+    fs.LanguageFiltered += other.LanguageFiltered
+    fs.DuplicateFiltered += other.DuplicateFiltered
 }
 
 func (fs FilterStats) String() string {
-    return fmt.Sprintf("Total: %d, Filtered: %d, Likes: %d, Time: %d, Keywords: %d", 
-        fs.TotalPosts, fs.FilteredPosts, fs.LikesFiltered, fs.TimeFiltered, fs.KeywordFiltered)
+    return fmt.Sprintf("Total: %d, Filtered: %d, Sponsored: %d, Likes: %d, Comments: %d, Shares: %d, Engagement: %d, "+
+        "Reactions: %d, Time: %d, Keywords: %d, Excluded: %d, Group: %d, Author: %d, Tags: %d, Media: %d, Type: %d, Language: %d, Duplicates: %d",
+        fs.TotalPosts, fs.FilteredPosts, fs.SponsoredFiltered, fs.LikesFiltered, fs.CommentsFiltered, fs.SharesFiltered,
+        fs.EngagementFiltered, fs.ReactionFiltered, fs.TimeFiltered, fs.KeywordFiltered, fs.ExcludeFiltered,
+        fs.GroupFiltered, fs.AuthorFiltered, fs.TagFiltered, fs.MediaFiltered, fs.PostTypeFiltered, fs.LanguageFiltered,
+        fs.DuplicateFiltered)
 }
\ No newline at end of file
@@ -0,0 +1,9 @@
+// Package web holds the assets served by the API server
+package web
+
+import "embed"
+
+// Templates contains the HTML templates under templates/
+//
+//go:embed templates/*.html
+var Templates embed.FS